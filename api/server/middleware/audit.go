@@ -0,0 +1,382 @@
+package middleware // import "github.com/docker/docker/api/server/middleware"
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/pkg/audit"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/sirupsen/logrus"
+)
+
+// maxAuditBodySize is the maximum size of the request bodies recorded in
+// the audit log.
+const maxAuditBodySize = 65536 // 64KB
+
+// redacted replaces the values of the secrets in the audit log.
+const redacted = "*****"
+
+const (
+	// auditStageHijacked is the stage of the records written when the
+	// connection of a request is hijacked, to attach to a container for
+	// instance.
+	auditStageHijacked = "hijacked"
+	// auditStageCompleted is the stage of the records written when a
+	// request which hijacked its connection completes.
+	auditStageCompleted = "completed"
+)
+
+// versionPrefix matches the API version prefix of the request paths.
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+// auditRoutes are the fields of the request bodies recorded at the
+// metadata level, by route. The fields of nested objects are separated by
+// dots.
+var auditRoutes = []struct {
+	path   *regexp.Regexp
+	fields []string
+}{
+	{
+		path:   regexp.MustCompile(`^/containers/create$`),
+		fields: []string{"Image", "Cmd", "Entrypoint", "User", "HostConfig.Privileged", "HostConfig.Binds", "HostConfig.CapAdd", "HostConfig.NetworkMode", "HostConfig.PidMode"},
+	},
+	{
+		path:   regexp.MustCompile(`^/containers/[^/]+/exec$`),
+		fields: []string{"Cmd", "User", "Privileged"},
+	},
+	{
+		path:   regexp.MustCompile(`^/services/(create|[^/]+/update)$`),
+		fields: []string{"Name", "TaskTemplate.ContainerSpec.Image", "TaskTemplate.ContainerSpec.Command", "TaskTemplate.ContainerSpec.User", "TaskTemplate.ContainerSpec.Privileges"},
+	},
+}
+
+// AuditRecord is a record of the audit log, written for each API request.
+// The requests hijacking their connection get two records, one when the
+// connection is hijacked and one when they complete, told apart by Stage.
+type AuditRecord struct {
+	Time       time.Time              `json:"time"`
+	RemoteAddr string                 `json:"remote_addr,omitempty"`
+	User       string                 `json:"user,omitempty"`
+	Peer       *audit.Peer            `json:"peer,omitempty"`
+	Stage      string                 `json:"stage,omitempty"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Query      map[string][]string    `json:"query,omitempty"`
+	Headers    map[string][]string    `json:"headers,omitempty"`
+	Body       map[string]interface{} `json:"body,omitempty"`
+	Status     int                    `json:"status"`
+	Duration   float64                `json:"duration_ms"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// AuditMiddleware writes a record of each API request to an audit log.
+type AuditMiddleware struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level string
+}
+
+// NewAuditMiddleware creates a new AuditMiddleware writing the records to
+// w, one JSON object per write. The level is one of the levels of the audit
+// package.
+func NewAuditMiddleware(w io.Writer, level string) (*AuditMiddleware, error) {
+	if err := audit.ValidateLevel(level); err != nil {
+		return nil, err
+	}
+	if level == "" {
+		level = audit.LevelMetadata
+	}
+	return &AuditMiddleware{w: w, level: level}, nil
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (m *AuditMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		start := time.Now()
+		record := &AuditRecord{
+			Time:       start.UTC(),
+			RemoteAddr: r.RemoteAddr,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      sanitizeValues(r.URL.Query()),
+		}
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			record.User = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		// the process making the request over a unix socket
+		record.Peer = audit.PeerFromRequest(r)
+		if m.level == audit.LevelRequest {
+			record.Headers = sanitizeValues(r.Header)
+			record.Body = peekJSONBody(r)
+		} else if fields := auditFields(r); len(fields) > 0 {
+			record.Body = selectFields(peekJSONBody(r), fields)
+		}
+
+		rw := &statusRecorder{ResponseWriter: w}
+		rw.onHijack = func() {
+			// the request may last as long as the container it attaches
+			// to: record it before streaming
+			hijacked := *record
+			hijacked.Stage = auditStageHijacked
+			hijacked.Status = hijackedStatus(r)
+			hijacked.Duration = float64(time.Since(start)) / float64(time.Millisecond)
+			m.write(&hijacked)
+		}
+		err := handler(ctx, rw, r, vars)
+
+		record.Duration = float64(time.Since(start)) / float64(time.Millisecond)
+		if rw.hijacked {
+			record.Stage = auditStageCompleted
+		}
+		switch {
+		case err != nil:
+			// the error is written to the client by the server
+			record.Status = httputils.GetHTTPErrorStatusCode(err)
+			record.Error = err.Error()
+		case rw.hijacked:
+			record.Status = hijackedStatus(r)
+		case rw.status == 0:
+			record.Status = http.StatusOK
+		default:
+			record.Status = rw.status
+		}
+		m.write(record)
+		return err
+	}
+}
+
+// hijackedStatus returns the status of a request which hijacked its
+// connection, as the status line is written to the hijacked connection.
+func hijackedStatus(r *http.Request) int {
+	if r.Header.Get("Upgrade") != "" {
+		return http.StatusSwitchingProtocols
+	}
+	return http.StatusOK
+}
+
+func (m *AuditMiddleware) write(record *AuditRecord) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		logrus.Errorf("Error marshalling audit record for %s %s: %v", record.Method, record.Path, err)
+		return
+	}
+	b = append(b, '\n')
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.w.Write(b); err != nil {
+		logrus.Errorf("Error writing audit record for %s %s: %v", record.Method, record.Path, err)
+	}
+}
+
+// sanitizeValues returns a copy of the query parameters or headers with the
+// values of those which may hold credentials or secrets redacted.
+func sanitizeValues(values map[string][]string) map[string][]string {
+	if len(values) == 0 {
+		return nil
+	}
+	sanitized := make(map[string][]string, len(values))
+	for k, v := range values {
+		if isSecretKey(k) {
+			v = []string{redacted}
+		}
+		sanitized[k] = v
+	}
+	return sanitized
+}
+
+// isSecretKey returns whether the query parameter or header k may hold
+// credentials or secrets.
+func isSecretKey(k string) bool {
+	k = strings.ToLower(k)
+	switch k {
+	case "x-registry-auth", "x-registry-config", "authorization", "cookie", "buildargs":
+		return true
+	}
+	for _, s := range []string{"password", "secret", "token"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// peekJSONBody returns the JSON body of the request, with the secrets
+// masked, without consuming it.
+func peekJSONBody(r *http.Request) map[string]interface{} {
+	if r.Body == nil || r.ContentLength == 0 || r.ContentLength > maxAuditBodySize {
+		return nil
+	}
+	if err := httputils.CheckForJSON(r); err != nil {
+		return nil
+	}
+
+	body := r.Body
+	bufReader := bufio.NewReaderSize(body, maxAuditBodySize)
+	r.Body = ioutils.NewReadCloserWrapper(bufReader, func() error { return body.Close() })
+
+	b, err := bufReader.Peek(maxAuditBodySize)
+	if err != io.EOF {
+		// either there was an error reading, or the buffer is full (in which case the request is too large)
+		return nil
+	}
+
+	var form map[string]interface{}
+	if err := json.Unmarshal(b, &form); err != nil {
+		return nil
+	}
+	maskSecretKeys(form, r.URL.Path)
+	maskEnv(form)
+	return form
+}
+
+// auditFields returns the fields of the body of the request r recorded at
+// the metadata level.
+func auditFields(r *http.Request) []string {
+	if r.Method != http.MethodPost {
+		return nil
+	}
+	path := versionPrefix.ReplaceAllString(strings.TrimRight(r.URL.Path, "/"), "/")
+	for _, route := range auditRoutes {
+		if route.path.MatchString(path) {
+			return route.fields
+		}
+	}
+	return nil
+}
+
+// selectFields returns the fields of form, keeping the objects they are
+// nested in.
+func selectFields(form map[string]interface{}, fields []string) map[string]interface{} {
+	if form == nil {
+		return nil
+	}
+	selected := make(map[string]interface{})
+	for _, field := range fields {
+		keys := strings.Split(field, ".")
+		src, dst := form, selected
+		for i, k := range keys {
+			v, ok := src[k]
+			if !ok || v == nil {
+				break
+			}
+			if i == len(keys)-1 {
+				dst[k] = v
+				break
+			}
+			if src, ok = v.(map[string]interface{}); !ok {
+				break
+			}
+			next, ok := dst[k].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				dst[k] = next
+			}
+			dst = next
+		}
+	}
+	removeEmptyObjects(selected)
+	return selected
+}
+
+// removeEmptyObjects removes the objects of form left empty by
+// selectFields, when none of their selected fields is set.
+func removeEmptyObjects(form map[string]interface{}) bool {
+	for k, v := range form {
+		if obj, ok := v.(map[string]interface{}); ok && removeEmptyObjects(obj) {
+			delete(form, k)
+		}
+	}
+	return len(form) == 0
+}
+
+// maskEnv redacts the values of the environment variables in the
+// container and exec configurations of the JSON body inp, keeping their
+// names.
+func maskEnv(inp interface{}) {
+	switch v := inp.(type) {
+	case []interface{}:
+		for _, e := range v {
+			maskEnv(e)
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			env, ok := e.([]interface{})
+			if k != "Env" || !ok {
+				maskEnv(e)
+				continue
+			}
+			for i, kv := range env {
+				if s, ok := kv.(string); ok {
+					if idx := strings.Index(s, "="); idx != -1 {
+						env[i] = s[:idx+1] + redacted
+					}
+				}
+			}
+		}
+	}
+}
+
+// statusRecorder records the status code written by the handlers. It
+// implements the optional interfaces of the response writers the handlers
+// depend on.
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	hijacked bool
+	// onHijack is called once the connection is hijacked.
+	onHijack func()
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusRecorder) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Internal response writer doesn't support the Hijacker interface")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return conn, buf, err
+	}
+	w.hijacked = true
+	if w.onHijack != nil {
+		w.onHijack()
+	}
+	return conn, buf, nil
+}
@@ -0,0 +1,209 @@
+package middleware // import "github.com/docker/docker/api/server/middleware"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/audit"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/pkg/errors"
+)
+
+func readAuditRecord(t *testing.T, buf *bytes.Buffer) AuditRecord {
+	var record AuditRecord
+	assert.NilError(t, json.NewDecoder(buf).Decode(&record))
+	return record
+}
+
+func TestAuditMiddlewareMetadata(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, "")
+	assert.NilError(t, err)
+
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	})
+	req := httptest.NewRequest("POST", "/v1.37/images/create?fromImage=busybox&password=foo", strings.NewReader(`{"password":"foo"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Registry-Auth", "c2VjcmV0")
+	assert.NilError(t, h(context.Background(), httptest.NewRecorder(), req, nil))
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.Equal("POST", record.Method))
+	assert.Check(t, is.Equal("/v1.37/images/create", record.Path))
+	assert.Check(t, is.Equal(http.StatusCreated, record.Status))
+	assert.Check(t, is.DeepEqual([]string{"busybox"}, record.Query["fromImage"]))
+	assert.Check(t, is.DeepEqual([]string{redacted}, record.Query["password"]))
+	assert.Check(t, is.Len(record.Headers, 0))
+	assert.Check(t, is.Len(record.Body, 0))
+}
+
+func TestAuditMiddlewareRequest(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, audit.LevelRequest)
+	assert.NilError(t, err)
+
+	body := `{"Name":"foo","Data":"c2VjcmV0"}`
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		// the handler still receives the whole body
+		b, err := ioutil.ReadAll(r.Body)
+		assert.Check(t, err)
+		assert.Check(t, is.Equal(body, string(b)))
+		return errdefs.Forbidden(errors.New("denied"))
+	})
+	req := httptest.NewRequest("POST", "/secrets/create", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Registry-Auth", "c2VjcmV0")
+	err = h(context.Background(), httptest.NewRecorder(), req, nil)
+	assert.Check(t, is.Error(err, "denied"))
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.Equal(http.StatusForbidden, record.Status))
+	assert.Check(t, is.Equal("denied", record.Error))
+	assert.Check(t, is.DeepEqual([]string{redacted}, record.Headers["X-Registry-Auth"]))
+	assert.Check(t, is.DeepEqual([]string{"application/json"}, record.Headers["Content-Type"]))
+	assert.Check(t, is.Equal("foo", record.Body["Name"]))
+	assert.Check(t, is.Equal(redacted, record.Body["Data"]))
+}
+
+func TestAuditMiddlewareMetadataFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, audit.LevelMetadata)
+	assert.NilError(t, err)
+
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	})
+	body := `{"Cmd":["sh"],"User":"root","Privileged":true,"Env":["PASSWORD=foo"],"AttachStdin":true}`
+	req := httptest.NewRequest("POST", "/v1.37/containers/abc/exec", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NilError(t, h(context.Background(), httptest.NewRecorder(), req, nil))
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.DeepEqual(map[string]interface{}{
+		"Cmd":        []interface{}{"sh"},
+		"User":       "root",
+		"Privileged": true,
+	}, record.Body))
+
+	body = `{"Image":"busybox","Env":["FOO=bar"],"HostConfig":{"Privileged":true,"Memory":0},"NetworkingConfig":{}}`
+	req = httptest.NewRequest("POST", "/containers/create?name=foo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NilError(t, h(context.Background(), httptest.NewRecorder(), req, nil))
+
+	record = readAuditRecord(t, buf)
+	assert.Check(t, is.DeepEqual(map[string]interface{}{
+		"Image":      "busybox",
+		"HostConfig": map[string]interface{}{"Privileged": true},
+	}, record.Body))
+}
+
+func TestAuditMiddlewareRequestEnv(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, audit.LevelRequest)
+	assert.NilError(t, err)
+
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	})
+	body := `{"Image":"busybox","Env":["DB_PASSWORD=foo","EMPTY=","HOME"]}`
+	req := httptest.NewRequest("POST", "/containers/create", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NilError(t, h(context.Background(), httptest.NewRecorder(), req, nil))
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.Equal("busybox", record.Body["Image"]))
+	assert.Check(t, is.DeepEqual([]interface{}{"DB_PASSWORD=" + redacted, "EMPTY=" + redacted, "HOME"}, record.Body["Env"]))
+}
+
+// hijackRecorder is a response recorder supporting the hijacking of its
+// connection.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestAuditMiddlewareHijack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, "")
+	assert.NilError(t, err)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		assert.NilError(t, err)
+		defer conn.Close()
+
+		// the request is recorded as soon as the connection is hijacked
+		record := readAuditRecord(t, buf)
+		assert.Check(t, is.Equal(auditStageHijacked, record.Stage))
+		assert.Check(t, is.Equal(http.StatusSwitchingProtocols, record.Status))
+		assert.Check(t, is.Equal("/containers/abc/attach", record.Path))
+		return nil
+	})
+	req := httptest.NewRequest("POST", "/containers/abc/attach?stream=1", nil)
+	req.Header.Set("Upgrade", "tcp")
+	assert.NilError(t, h(context.Background(), &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}, req, nil))
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.Equal(auditStageCompleted, record.Stage))
+	assert.Check(t, is.Equal(http.StatusSwitchingProtocols, record.Status))
+	assert.Check(t, is.Equal(0, buf.Len()))
+}
+
+func TestAuditMiddlewarePeer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m, err := NewAuditMiddleware(buf, "")
+	assert.NilError(t, err)
+
+	dir, err := ioutil.TempDir("", "audit-peer")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+
+	h := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	})
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h(context.Background(), w, r, nil)
+	}))
+	srv.Listener = audit.PeerListener(l)
+	srv.Start()
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://docker/v1.37/info")
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	record := readAuditRecord(t, buf)
+	assert.Check(t, is.Equal("/v1.37/info", record.Path))
+	if runtime.GOOS == "linux" {
+		assert.Check(t, is.DeepEqual(&audit.Peer{UID: os.Getuid(), GID: os.Getgid(), PID: os.Getpid()}, record.Peer))
+	}
+}
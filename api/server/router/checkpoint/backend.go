@@ -1,10 +1,19 @@
 package checkpoint // import "github.com/docker/docker/api/server/router/checkpoint"
 
-import "github.com/docker/docker/api/types"
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/api/types/container"
+)
 
 // Backend for Checkpoint
 type Backend interface {
 	CheckpointCreate(container string, config types.CheckpointCreateOptions) error
 	CheckpointDelete(container string, config types.CheckpointDeleteOptions) error
 	CheckpointList(container string, config types.CheckpointListOptions) ([]types.Checkpoint, error)
+	CheckpointExport(container string, config types.CheckpointExportOptions, out io.Writer) error
+	CheckpointImport(ctx context.Context, config *backend.CheckpointImportConfig, in io.Reader) (container.ContainerCreateCreatedBody, error)
 }
@@ -31,6 +31,8 @@ func (r *checkpointRouter) initRoutes() {
 	r.routes = []router.Route{
 		router.NewGetRoute("/containers/{name:.*}/checkpoints", r.getContainerCheckpoints, router.Experimental),
 		router.NewPostRoute("/containers/{name:.*}/checkpoints", r.postContainerCheckpoint, router.Experimental),
+		router.NewGetRoute("/containers/{name}/checkpoints/{checkpoint}/export", r.getContainerCheckpointExport, router.Experimental),
+		router.NewPostRoute("/containers/checkpoints/import", r.postContainerCheckpointImport, router.Experimental),
 		router.NewDeleteRoute("/containers/{name}/checkpoints/{checkpoint}", r.deleteContainerCheckpoint, router.Experimental),
 	}
 }
@@ -2,11 +2,17 @@ package checkpoint // import "github.com/docker/docker/api/server/router/checkpo
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
 )
 
 func (s *checkpointRouter) postContainerCheckpoint(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -63,3 +69,61 @@ func (s *checkpointRouter) deleteContainerCheckpoint(ctx context.Context, w http
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
+
+func (s *checkpointRouter) getContainerCheckpointExport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	return s.backend.CheckpointExport(vars["name"], types.CheckpointExportOptions{
+		CheckpointDir: r.Form.Get("dir"),
+		CheckpointID:  vars["checkpoint"],
+	}, w)
+}
+
+func (s *checkpointRouter) postContainerCheckpointImport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	metaHeaders := map[string][]string{}
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Meta-") {
+			metaHeaders[k] = v
+		}
+	}
+
+	authEncoded := r.Header.Get("X-Registry-Auth")
+	authConfig := &types.AuthConfig{}
+	if authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(authConfig); err != nil {
+			// the image may not need to be pulled, or may be public
+			authConfig = &types.AuthConfig{}
+		}
+	}
+
+	// The host configuration is passed as a parameter rather than in the
+	// bundle, so that authorization plugins can inspect it.
+	var hostConfig *container.HostConfig
+	if hostConfigJSON := r.Form.Get("hostConfig"); hostConfigJSON != "" {
+		hostConfig = &container.HostConfig{}
+		if err := json.Unmarshal([]byte(hostConfigJSON), hostConfig); err != nil {
+			return errdefs.InvalidParameter(errors.Wrap(err, "invalid hostConfig"))
+		}
+	}
+
+	created, err := s.backend.CheckpointImport(ctx, &backend.CheckpointImportConfig{
+		Name:        r.Form.Get("name"),
+		Start:       httputils.BoolValue(r, "start"),
+		AuthConfig:  authConfig,
+		MetaHeaders: metaHeaders,
+		HostConfig:  hostConfig,
+	}, r.Body)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, created)
+}
@@ -52,7 +52,9 @@ type monitorBackend interface {
 	ContainerInspect(name string, size bool, version string) (interface{}, error)
 	ContainerLogs(ctx context.Context, name string, config *types.ContainerLogsOptions) (msgs <-chan *backend.LogMessage, tty bool, err error)
 	ContainerStats(ctx context.Context, name string, config *backend.ContainerStatsConfig) error
-	ContainerTop(name string, psArgs string) (*container.ContainerTopOKBody, error)
+	ContainersStats(ctx context.Context, config *backend.ContainersStatsConfig) error
+	ContainerStatsHistory(name string, options types.ContainerStatsHistoryOptions) (*types.StatsHistory, error)
+	ContainerTop(name string, psArgs string, structured bool) (*container.ContainerTopOKBody, error)
 
 	Containers(config *types.ContainerListOptions) ([]*types.Container, error)
 }
@@ -34,12 +34,14 @@ func (r *containerRouter) initRoutes() {
 		router.NewHeadRoute("/containers/{name:.*}/archive", r.headContainersArchive),
 		// GET
 		router.NewGetRoute("/containers/json", r.getContainersJSON),
+		router.NewGetRoute("/containers/stats", r.getAllContainersStats, router.WithCancel),
 		router.NewGetRoute("/containers/{name:.*}/export", r.getContainersExport),
 		router.NewGetRoute("/containers/{name:.*}/changes", r.getContainersChanges),
 		router.NewGetRoute("/containers/{name:.*}/json", r.getContainersByName),
 		router.NewGetRoute("/containers/{name:.*}/top", r.getContainersTop),
 		router.NewGetRoute("/containers/{name:.*}/logs", r.getContainersLogs, router.WithCancel),
 		router.NewGetRoute("/containers/{name:.*}/stats", r.getContainersStats, router.WithCancel),
+		router.NewGetRoute("/containers/{name:.*}/stats/history", r.getContainersStatsHistory),
 		router.NewGetRoute("/containers/{name:.*}/attach/ws", r.wsContainersAttach),
 		router.NewGetRoute("/exec/{id:.*}/json", r.getExecByID),
 		router.NewGetRoute("/containers/{name:.*}/archive", r.getContainersArchive),
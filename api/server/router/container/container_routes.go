@@ -115,6 +115,49 @@ func (s *containerRouter) getContainersStats(ctx context.Context, w http.Respons
 	return s.backend.ContainerStats(ctx, vars["name"], config)
 }
 
+func (s *containerRouter) getAllContainersStats(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	filter, err := filters.FromJSON(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	stream := httputils.BoolValueOrDefault(r, "stream", true)
+	if !stream {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	config := &backend.ContainersStatsConfig{
+		All:       httputils.BoolValue(r, "all"),
+		Filters:   filter,
+		Stream:    stream,
+		OutStream: w,
+	}
+
+	return s.backend.ContainersStats(ctx, config)
+}
+
+func (s *containerRouter) getContainersStatsHistory(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	options := types.ContainerStatsHistoryOptions{
+		Since:      r.Form.Get("since"),
+		Until:      r.Form.Get("until"),
+		Resolution: r.Form.Get("resolution"),
+	}
+
+	history, err := s.backend.ContainerStatsHistory(vars["name"], options)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, history)
+}
+
 func (s *containerRouter) getContainersLogs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -392,7 +435,12 @@ func (s *containerRouter) getContainersTop(ctx context.Context, w http.ResponseW
 		return err
 	}
 
-	procList, err := s.backend.ContainerTop(vars["name"], r.Form.Get("ps_args"))
+	structured := httputils.BoolValue(r, "structured")
+	if versions.LessThan(httputils.VersionFromContext(ctx), "1.37") {
+		structured = false
+	}
+
+	procList, err := s.backend.ContainerTop(vars["name"], r.Form.Get("ps_args"), structured)
 	if err != nil {
 		return err
 	}
@@ -432,6 +480,7 @@ func (s *containerRouter) postContainerUpdate(ctx context.Context, w http.Respon
 	hostConfig := &container.HostConfig{
 		Resources:     updateConfig.Resources,
 		RestartPolicy: updateConfig.RestartPolicy,
+		PortBindings:  updateConfig.PortBindings,
 	}
 
 	name := vars["name"]
@@ -465,6 +514,12 @@ func (s *containerRouter) postContainersCreate(ctx context.Context, w http.Respo
 		hostConfig.AutoRemove = false
 	}
 
+	// When using API 1.36 and under, containers share the cgroup namespace of
+	// the host, as the private mode is the default on cgroup v2 hosts.
+	if hostConfig != nil && versions.LessThan(version, "1.37") {
+		hostConfig.CgroupnsMode = "host"
+	}
+
 	ccr, err := s.backend.ContainerCreate(types.ContainerCreateConfig{
 		Name:             name,
 		Config:           config,
@@ -27,5 +27,6 @@ func (r *distributionRouter) initRoutes() {
 	r.routes = []router.Route{
 		// GET
 		router.NewGetRoute("/distribution/{name:.*}/json", r.getDistributionInfo),
+		router.NewGetRoute("/distribution/{name:.*}/manifest", r.getDistributionManifest),
 	}
 }
@@ -26,34 +26,16 @@ func (s *distributionRouter) getDistributionInfo(ctx context.Context, w http.Res
 	w.Header().Set("Content-Type", "application/json")
 
 	var (
-		config              = &types.AuthConfig{}
-		authEncoded         = r.Header.Get("X-Registry-Auth")
+		config              = authConfigFromRequest(r)
 		distributionInspect registrytypes.DistributionInspect
 	)
 
-	if authEncoded != "" {
-		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
-		if err := json.NewDecoder(authJSON).Decode(&config); err != nil {
-			// for a search it is not an error if no auth was given
-			// to increase compatibility with the existing api it is defaulting to be empty
-			config = &types.AuthConfig{}
-		}
-	}
-
 	image := vars["name"]
 
-	ref, err := reference.ParseAnyReference(image)
+	namedRef, err := parseNamedReference(image)
 	if err != nil {
 		return err
 	}
-	namedRef, ok := ref.(reference.Named)
-	if !ok {
-		if _, ok := ref.(reference.Digested); ok {
-			// full image ID
-			return errors.Errorf("no manifest found for full image ID")
-		}
-		return errors.Errorf("unknown image reference format: %s", image)
-	}
 
 	distrepo, _, err := s.backend.GetRepository(ctx, namedRef, config)
 	if err != nil {
@@ -136,3 +118,53 @@ func (s *distributionRouter) getDistributionInfo(ctx context.Context, w http.Res
 
 	return httputils.WriteJSON(w, http.StatusOK, distributionInspect)
 }
+
+func (s *distributionRouter) getDistributionManifest(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	namedRef, err := parseNamedReference(vars["name"])
+	if err != nil {
+		return err
+	}
+
+	manifest, err := s.backend.InspectManifest(ctx, namedRef, authConfigFromRequest(r))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, manifest)
+}
+
+// authConfigFromRequest decodes the credentials passed in the
+// X-Registry-Auth header.
+func authConfigFromRequest(r *http.Request) *types.AuthConfig {
+	config := &types.AuthConfig{}
+	if authEncoded := r.Header.Get("X-Registry-Auth"); authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(&config); err != nil {
+			// for a search it is not an error if no auth was given
+			// to increase compatibility with the existing api it is defaulting to be empty
+			config = &types.AuthConfig{}
+		}
+	}
+	return config
+}
+
+// parseNamedReference parses an image reference which must contain a
+// repository name.
+func parseNamedReference(image string) (reference.Named, error) {
+	ref, err := reference.ParseAnyReference(image)
+	if err != nil {
+		return nil, err
+	}
+	namedRef, ok := ref.(reference.Named)
+	if !ok {
+		if _, ok := ref.(reference.Digested); ok {
+			// full image ID
+			return nil, errors.Errorf("no manifest found for full image ID")
+		}
+		return nil, errors.Errorf("unknown image reference format: %s", image)
+	}
+	return namedRef, nil
+}
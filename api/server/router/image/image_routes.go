@@ -14,6 +14,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/system"
@@ -138,6 +139,18 @@ func (s *imageRouter) getImagesGet(ctx context.Context, w http.ResponseWriter, r
 		return err
 	}
 
+	var compression archive.Compression
+	switch c := r.Form.Get("compression"); c {
+	case "", "none":
+		compression = archive.Uncompressed
+	case "gzip":
+		compression = archive.Gzip
+	case "zstd":
+		compression = archive.Zstd
+	default:
+		return errdefs.InvalidParameter(errors.Errorf("invalid compression %q: must be none, gzip or zstd", c))
+	}
+
 	w.Header().Set("Content-Type", "application/x-tar")
 
 	output := ioutils.NewWriteFlusher(w)
@@ -149,7 +162,7 @@ func (s *imageRouter) getImagesGet(ctx context.Context, w http.ResponseWriter, r
 		names = r.Form["names"]
 	}
 
-	if err := s.backend.ExportImage(names, output); err != nil {
+	if err := s.backend.ExportImage(names, compression, output); err != nil {
 		if !output.Flushed() {
 			return err
 		}
@@ -258,6 +271,21 @@ func (s *imageRouter) postImagesTag(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+func (s *imageRouter) postImagesRebase(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	oldBase, newBase := r.Form.Get("oldBase"), r.Form.Get("newBase")
+	if oldBase == "" || newBase == "" {
+		return errdefs.InvalidParameter(errors.New("the old and new base images are required"))
+	}
+	id, err := s.backend.RebaseImage(vars["name"], oldBase, newBase, r.Form.Get("repo"), r.Form.Get("tag"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusCreated, &types.IDResponse{ID: id})
+}
+
 func (s *imageRouter) getImagesSearch(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -2,6 +2,7 @@ package volume // import "github.com/docker/docker/api/server/router/volume"
 
 import (
 	"context"
+	"io"
 
 	// TODO return types need to be refactored into pkg
 	"github.com/docker/docker/api/types"
@@ -14,6 +15,11 @@ type Backend interface {
 	Volumes(filter string) ([]*types.Volume, []string, error)
 	VolumeInspect(name string) (*types.Volume, error)
 	VolumeCreate(name, driverName string, opts, labels map[string]string) (*types.Volume, error)
+	VolumeClone(source, name string, opts, labels map[string]string) (*types.Volume, error)
+	VolumeSnapshot(source, name string, labels map[string]string) (*types.Volume, error)
+	VolumeUpdate(name string, labels, opts map[string]string) (*types.Volume, error)
 	VolumeRm(name string, force bool) error
+	VolumeExport(name, compression string, out io.Writer) error
+	VolumeImport(name string, content io.Reader, overwrite bool) error
 	VolumesPrune(ctx context.Context, pruneFilters filters.Args) (*types.VolumesPruneReport, error)
 }
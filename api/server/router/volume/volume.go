@@ -26,10 +26,16 @@ func (r *volumeRouter) initRoutes() {
 	r.routes = []router.Route{
 		// GET
 		router.NewGetRoute("/volumes", r.getVolumesList),
+		router.NewGetRoute("/volumes/{name:.*}/archive", r.getVolumeArchive),
 		router.NewGetRoute("/volumes/{name:.*}", r.getVolumeByName),
 		// POST
 		router.NewPostRoute("/volumes/create", r.postVolumesCreate),
 		router.NewPostRoute("/volumes/prune", r.postVolumesPrune, router.WithCancel),
+		router.NewPostRoute("/volumes/{name:.*}/clone", r.postVolumeClone),
+		router.NewPostRoute("/volumes/{name:.*}/snapshot", r.postVolumeSnapshot),
+		router.NewPostRoute("/volumes/{name:.*}/update", r.postVolumeUpdate),
+		// PUT
+		router.NewPutRoute("/volumes/{name:.*}/archive", r.putVolumeArchive),
 		// DELETE
 		router.NewDeleteRoute("/volumes/{name:.*}", r.deleteVolumes),
 	}
@@ -12,7 +12,11 @@ import (
 	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/api/server/router/debug"
 	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/audit"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -54,12 +58,19 @@ func (s *Server) UseMiddleware(m middleware.Middleware) {
 
 // Accept sets a listener the server accepts connections into.
 func (s *Server) Accept(addr string, listeners ...net.Listener) {
+	s.AcceptScoped(addr, nil, listeners...)
+}
+
+// AcceptScoped sets a listener the server accepts connections into, only
+// serving the routes the scope allows. A nil scope allows all the routes.
+func (s *Server) AcceptScoped(addr string, scope *authorization.Scope, listeners ...net.Listener) {
 	for _, listener := range listeners {
 		httpServer := &HTTPServer{
 			srv: &http.Server{
 				Addr: addr,
 			},
-			l: listener,
+			l:     listener,
+			scope: scope,
 		}
 		s.servers = append(s.servers, httpServer)
 	}
@@ -80,6 +91,9 @@ func (s *Server) serveAPI() error {
 	var chErrors = make(chan error, len(s.servers))
 	for _, srv := range s.servers {
 		srv.srv.Handler = s.routerSwapper
+		if srv.scope != nil {
+			srv.srv.Handler = &scopedHandler{scope: srv.scope, handler: s.routerSwapper}
+		}
 		go func(srv *HTTPServer) {
 			var err error
 			logrus.Infof("API listen on %s", srv.l.Addr())
@@ -102,14 +116,16 @@ func (s *Server) serveAPI() error {
 // HTTPServer contains an instance of http server and the listener.
 // srv *http.Server, contains configuration to create an http server and a mux router with all api end points.
 // l   net.Listener, is a TCP or Socket listener that dispatches incoming request to the router.
+// scope *authorization.Scope, restricts the routes served on the listener, if set.
 type HTTPServer struct {
-	srv *http.Server
-	l   net.Listener
+	srv   *http.Server
+	l     net.Listener
+	scope *authorization.Scope
 }
 
 // Serve starts listening for inbound requests.
 func (s *HTTPServer) Serve() error {
-	return s.srv.Serve(s.l)
+	return s.srv.Serve(audit.PeerListener(s.l))
 }
 
 // Close closes the HTTPServer from listening for the inbound requests.
@@ -117,6 +133,32 @@ func (s *HTTPServer) Close() error {
 	return s.l.Close()
 }
 
+// scopeKey is the key of the scope of the listener a request was received on
+// in the request context.
+type scopeKey struct{}
+
+// scopedHandler records the scope of the listener in the context of the
+// requests, for makeHTTPHandler to enforce it.
+type scopedHandler struct {
+	scope   *authorization.Scope
+	handler http.Handler
+}
+
+func (h *scopedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), scopeKey{}, h.scope)))
+}
+
+// scopeHandler wraps the handler to reject the requests which the scope of
+// the listener they were received on does not allow.
+func scopeHandler(handler httputils.APIFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		if scope, ok := r.Context().Value(scopeKey{}).(*authorization.Scope); ok && !scope.Allows(r.Method, r.URL.Path) {
+			return errdefs.Forbidden(errors.Errorf("%s %s is not allowed on this API socket (%s)", r.Method, r.URL.Path, scope))
+		}
+		return handler(ctx, w, r, vars)
+	}
+}
+
 func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Define the context that we'll pass around to share info
@@ -127,7 +169,9 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 		// immediate function being called should still be passed
 		// as 'args' on the function call.
 		ctx := context.WithValue(context.Background(), dockerversion.UAStringKey, r.Header.Get("User-Agent"))
-		handlerFunc := s.handlerWithGlobalMiddlewares(handler)
+		// The scope is enforced within the middlewares, so that the
+		// rejected requests are audited.
+		handlerFunc := s.handlerWithGlobalMiddlewares(scopeHandler(handler))
 
 		vars := mux.Vars(r)
 		if vars == nil {
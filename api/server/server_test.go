@@ -11,6 +11,8 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
+	"github.com/docker/docker/api/server/router"
+	"github.com/docker/docker/pkg/authorization"
 )
 
 func TestMiddlewares(t *testing.T) {
@@ -44,3 +46,46 @@ func TestMiddlewares(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestScopedHandler(t *testing.T) {
+	srv := &Server{
+		cfg: &Config{},
+	}
+	srv.routers = append(srv.routers, &testRouter{})
+	srv.routerSwapper = &routerSwapper{router: srv.createMux()}
+
+	handler := &scopedHandler{scope: &authorization.Scope{ReadOnly: true}, handler: srv.routerSwapper}
+
+	req := httptest.NewRequest("GET", "/v1.37/containers/json", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/v1.37/containers/create", nil)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, resp.Code)
+	}
+
+	// the listeners without a scope serve all the routes
+	resp = httptest.NewRecorder()
+	srv.routerSwapper.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+}
+
+type testRouter struct{}
+
+func (testRouter) Routes() []router.Route {
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	}
+	return []router.Route{
+		router.NewGetRoute("/containers/json", ok),
+		router.NewPostRoute("/containers/create", ok),
+	}
+}
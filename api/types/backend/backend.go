@@ -5,7 +5,9 @@ import (
 	"io"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 )
 
 // ContainerAttachConfig holds the streams to use when connecting to a container to view logs.
@@ -77,6 +79,15 @@ type ContainerStatsConfig struct {
 	Version   string
 }
 
+// ContainersStatsConfig holds information for configuring the runtime
+// behavior of a backend.ContainersStats() call.
+type ContainersStatsConfig struct {
+	All       bool
+	Filters   filters.Args
+	Stream    bool
+	OutStream io.Writer
+}
+
 // ExecInspect holds information about a running process started
 // with docker exec.
 type ExecInspect struct {
@@ -126,3 +137,15 @@ type CommitConfig struct {
 	ContainerOS         string
 	ParentImageID       string
 }
+
+// CheckpointImportConfig holds the parameters to create a container from an
+// exported checkpoint.
+type CheckpointImportConfig struct {
+	Name        string
+	Start       bool
+	AuthConfig  *types.AuthConfig
+	MetaHeaders map[string][]string
+	// HostConfig replaces the host configuration of the checkpoint bundle,
+	// if set.
+	HostConfig *container.HostConfig
+}
@@ -28,6 +28,24 @@ type CheckpointDeleteOptions struct {
 	CheckpointDir string
 }
 
+// CheckpointExportOptions holds parameters to export a checkpoint of a container
+type CheckpointExportOptions struct {
+	CheckpointID  string
+	CheckpointDir string
+}
+
+// CheckpointImportOptions holds parameters to create a container from an
+// exported checkpoint
+type CheckpointImportOptions struct {
+	Name         string
+	Start        bool
+	RegistryAuth string // RegistryAuth is the base64 encoded credentials for the registry
+	// HostConfig replaces the host configuration of the exported container.
+	// It must be set to import containers with settings giving them access
+	// to the host, such as privileged containers or bind mounts.
+	HostConfig *container.HostConfig
+}
+
 // ContainerAttachOptions holds parameters to attach to a container.
 type ContainerAttachOptions struct {
 	Stream     bool
@@ -81,6 +99,22 @@ type ContainerLogsOptions struct {
 	Details    bool
 }
 
+// ContainersStatsOptions holds parameters to stream the stats of multiple
+// containers.
+type ContainersStatsOptions struct {
+	All     bool
+	Filters filters.Args
+	Stream  bool
+}
+
+// ContainerStatsHistoryOptions holds parameters to query the stats history
+// of a container.
+type ContainerStatsHistoryOptions struct {
+	Since      string
+	Until      string
+	Resolution string
+}
+
 // ContainerRemoveOptions holds parameters to remove containers.
 type ContainerRemoveOptions struct {
 	RemoveVolumes bool
@@ -211,6 +245,14 @@ type ImageImportOptions struct {
 	Platform string   // Platform is the target platform of the image
 }
 
+// ImageRebaseOptions holds parameters to rebase an image on another base
+// image.
+type ImageRebaseOptions struct {
+	OldBase   string // OldBase is the base image the image was built on
+	NewBase   string // NewBase is the base image replacing OldBase
+	Reference string // Reference is the repository:tag to tag the rebased image with, if set
+}
+
 // ImageListOptions holds parameters to filter the list of images with.
 type ImageListOptions struct {
 	All     bool
@@ -388,3 +430,17 @@ type SwarmUnlockKeyResponse struct {
 type PluginCreateOptions struct {
 	RepoName string
 }
+
+// VolumeExportOptions holds parameters to export the content of a volume.
+type VolumeExportOptions struct {
+	// Compression is the compression of the archive: "none" (the
+	// default), "gzip" or "zstd".
+	Compression string
+}
+
+// VolumeImportOptions holds parameters to import content into a volume.
+type VolumeImportOptions struct {
+	// Overwrite allows the import into a volume which is not empty. The
+	// existing files of the volume are overwritten by files of the archive.
+	Overwrite bool
+}
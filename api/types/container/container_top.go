@@ -7,10 +7,61 @@ package container
 // See hack/generate-swagger-api.sh
 // ----------------------------------------------------------------------------
 
+// TopProcess the details of a process running in a container
+// swagger:model TopProcess
+type TopProcess struct {
+
+	// Command line of the process
+	Cmdline []string `json:"Cmdline,omitempty"`
+
+	// CPU usage of the process over its lifetime
+	// Required: true
+	CPUPercent float64 `json:"CpuPercent"`
+
+	// CPU time consumed by the process, in nanoseconds
+	// Required: true
+	CPUTime int64 `json:"CpuTime"`
+
+	// Process ID
+	// Required: true
+	PID int64 `json:"PID"`
+
+	// Process ID of the parent process
+	// Required: true
+	PPID int64 `json:"PPID"`
+
+	// Resident set size of the process, in bytes
+	// Required: true
+	RSS uint64 `json:"Rss"`
+
+	// Date and time at which the process was started, in RFC 3339 format with nano-seconds
+	StartedAt string `json:"StartedAt,omitempty"`
+
+	// State of the process, as reported by the kernel (for example `R` for running, or `S` for sleeping)
+	State string `json:"State,omitempty"`
+
+	// Controlling terminal of the process, or `?` if it has none
+	TTY string `json:"TTY,omitempty"`
+
+	// Number of threads of the process
+	// Required: true
+	Threads int64 `json:"Threads"`
+
+	// Effective user ID of the process in the container
+	// Required: true
+	UID int64 `json:"UID"`
+
+	// Name of the user in the passwd file of the container, if any
+	User string `json:"User,omitempty"`
+}
+
 // ContainerTopOKBody OK response to ContainerTop operation
 // swagger:model ContainerTopOKBody
 type ContainerTopOKBody struct {
 
+	// The details of each process running in the container, in structured mode
+	Details []TopProcess `json:"Details,omitempty"`
+
 	// Each process running in the container, where each is process is an array of values corresponding to the titles
 	// Required: true
 	Processes [][]string `json:"Processes"`
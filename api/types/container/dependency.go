@@ -0,0 +1,36 @@
+package container // import "github.com/docker/docker/api/types/container"
+
+import "time"
+
+// DependencyCondition is the condition a dependency of a container must meet
+// before the container is started.
+type DependencyCondition string
+
+// Possible DependencyCondition values.
+//
+// DependencyStarted (default) waits for the dependency to be running.
+//
+// DependencyHealthy waits for the dependency to be running and healthy. The
+// dependency must have a healthcheck.
+//
+// DependencyCompletedSuccessfully waits for the dependency to have exited
+// with a zero exit code.
+const (
+	DependencyStarted               DependencyCondition = "started"
+	DependencyHealthy               DependencyCondition = "healthy"
+	DependencyCompletedSuccessfully DependencyCondition = "completed-successfully"
+)
+
+// Dependency is a container which must meet a condition before the container
+// depending on it is started.
+type Dependency struct {
+	// Container is the name or ID of the container depended on.
+	Container string
+
+	// Condition is the condition the container must meet.
+	Condition DependencyCondition `json:",omitempty"`
+
+	// Timeout is the time to wait for the condition to be met. Zero means
+	// the default timeout.
+	Timeout time.Duration `json:",omitempty"`
+}
@@ -89,6 +89,29 @@ func (n IpcMode) Container() string {
 	return ""
 }
 
+// CgroupnsMode represents the cgroup namespace mode of the container.
+type CgroupnsMode string
+
+// IsPrivate indicates whether the container uses its own private cgroup namespace.
+func (c CgroupnsMode) IsPrivate() bool {
+	return c == "private"
+}
+
+// IsHost indicates whether the container shares the host's cgroup namespace.
+func (c CgroupnsMode) IsHost() bool {
+	return c == "host"
+}
+
+// IsEmpty indicates whether the container cgroup namespace mode is unset.
+func (c CgroupnsMode) IsEmpty() bool {
+	return c == ""
+}
+
+// Valid indicates whether the cgroup namespace mode is valid.
+func (c CgroupnsMode) Valid() bool {
+	return c.IsEmpty() || c.IsPrivate() || c.IsHost()
+}
+
 // NetworkMode represents the container network stack.
 type NetworkMode string
 
@@ -349,6 +372,10 @@ type UpdateConfig struct {
 	// Contains container's resources (cgroups, ulimits)
 	Resources
 	RestartPolicy RestartPolicy
+
+	// PortBindings replaces the port bindings of the container if not nil.
+	// An empty map removes all the port bindings.
+	PortBindings nat.PortMap `json:",omitempty"`
 }
 
 // HostConfig the non-portable Config structure of a container.
@@ -376,6 +403,7 @@ type HostConfig struct {
 	GroupAdd        []string          // List of additional groups that the container process will run as
 	IpcMode         IpcMode           // IPC namespace to use for the container
 	Cgroup          CgroupSpec        // Cgroup to use for the container
+	CgroupnsMode    CgroupnsMode      `json:",omitempty"` // Cgroup namespace mode to use for the container
 	Links           []string          // List of links (in the name:alias form)
 	OomScoreAdj     int               // Container preference for OOM-killing
 	PidMode         PidMode           // PID namespace to use for the container
@@ -403,4 +431,10 @@ type HostConfig struct {
 
 	// Run a custom init inside the container, if null, use the daemon's configured settings
 	Init *bool `json:",omitempty"`
+
+	// Commands exec'd inside the container after it starts and before it stops
+	Lifecycle *LifecycleHooks `json:",omitempty"`
+
+	// Containers which must meet a condition before the container is started
+	DependsOn []Dependency `json:",omitempty"`
 }
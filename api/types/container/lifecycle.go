@@ -0,0 +1,48 @@
+package container // import "github.com/docker/docker/api/types/container"
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types/strslice"
+)
+
+// HookFailurePolicy is the action taken when a lifecycle hook fails.
+type HookFailurePolicy string
+
+// Possible HookFailurePolicy values.
+//
+// HookFailureIgnore (default) logs the failure of the hook and leaves the
+// container running.
+//
+// HookFailureKill kills the container if the hook fails or times out.
+const (
+	HookFailureIgnore HookFailurePolicy = "ignore"
+	HookFailureKill   HookFailurePolicy = "kill"
+)
+
+// LifecycleHook is a command exec'd inside the container at a point of its
+// lifecycle.
+type LifecycleHook struct {
+	// Cmd is the command to run, in the exec form.
+	Cmd strslice.StrSlice
+
+	// Timeout is the time the command is allowed to run. Zero means the
+	// default timeout.
+	Timeout time.Duration `json:",omitempty"`
+
+	// OnFailure is the action taken when the command fails or times out.
+	// Only supported for post-start hooks.
+	OnFailure HookFailurePolicy `json:",omitempty"`
+}
+
+// LifecycleHooks are the commands run inside the container after it starts
+// and before it is stopped.
+type LifecycleHooks struct {
+	// PostStart is run once the container is started.
+	PostStart *LifecycleHook `json:",omitempty"`
+
+	// PreStop is run before the stop signal is sent to the container. Its
+	// run time counts toward the stop timeout of the container, of which it
+	// may use at most half.
+	PreStop *LifecycleHook `json:",omitempty"`
+}
@@ -87,6 +87,12 @@ type VolumeOptions struct {
 	NoCopy       bool              `json:",omitempty"`
 	Labels       map[string]string `json:",omitempty"`
 	DriverConfig *Driver           `json:",omitempty"`
+	// Subpath is the path of a directory of the volume, relative to the
+	// root of the volume, to mount instead of the whole volume.
+	Subpath string `json:",omitempty"`
+	// Chown sets the ownership of the volume to the user of the container
+	// when the volume is mounted for the first time, while it is empty.
+	Chown bool `json:",omitempty"`
 }
 
 // Driver represents a volume driver.
@@ -117,3 +117,33 @@ type DistributionInspect struct {
 	// obtained by parsing the manifest
 	Platforms []v1.Platform
 }
+
+// DistributionManifest describes the manifest resolved in the registry for
+// an image reference, including the image manifests it refers to.
+type DistributionManifest struct {
+	// Descriptor contains information about the manifest, including
+	// the content addressable digest
+	Descriptor v1.Descriptor
+	// Raw is the manifest as it was returned by the registry
+	Raw json.RawMessage
+	// Manifests contains one entry for every manifest referenced by a
+	// manifest list, or a single entry describing the manifest itself
+	Manifests []DistributionImageManifest
+}
+
+// DistributionImageManifest describes an image manifest in the registry,
+// together with its configuration and layers.
+type DistributionImageManifest struct {
+	// Descriptor contains information about the image manifest. For an
+	// entry of a manifest list it also contains the platform.
+	Descriptor v1.Descriptor
+	// Raw is the image manifest as it was returned by the registry
+	Raw json.RawMessage
+	// Config is the image configuration. It is empty for schema1 manifests,
+	// which have no configuration blob.
+	Config json.RawMessage `json:",omitempty"`
+	// Layers describes the layers of the image, base layer first
+	Layers []v1.Descriptor
+	// Size is the total size of the manifest, configuration and layers
+	Size int64
+}
@@ -179,3 +179,45 @@ type StatsJSON struct {
 	// Networks request version >=1.21
 	Networks map[string]NetworkStats `json:"networks,omitempty"`
 }
+
+// ContainersStatsJSON is a document of the stats stream of multiple
+// containers.
+type ContainersStatsJSON struct {
+	Read  time.Time   `json:"read"`
+	Stats []StatsJSON `json:"stats"`
+}
+
+// StatsHistorySample is the resource usage of a container over an interval
+// of its stats history.
+type StatsHistorySample struct {
+	// Time is the start of the interval.
+	Time time.Time `json:"time"`
+
+	// CPUPercent is the average CPU usage over the interval, where 100% is
+	// the usage of one CPU.
+	CPUPercent float64 `json:"cpu_percent"`
+
+	// MemoryUsage and MemoryLimit are the memory usage and limit at the
+	// end of the interval, and MemoryMaxUsage the highest memory usage
+	// sampled during the interval.
+	MemoryUsage    uint64 `json:"memory_usage"`
+	MemoryMaxUsage uint64 `json:"memory_max_usage"`
+	MemoryLimit    uint64 `json:"memory_limit"`
+
+	// Network and block IO counters, cumulated since the container
+	// started, at the end of the interval.
+	NetworkRxBytes  uint64 `json:"network_rx_bytes"`
+	NetworkTxBytes  uint64 `json:"network_tx_bytes"`
+	BlockReadBytes  uint64 `json:"block_read_bytes"`
+	BlockWriteBytes uint64 `json:"block_write_bytes"`
+}
+
+// StatsHistory is the stats history of a container.
+type StatsHistory struct {
+	Name string `json:"name,omitempty"`
+	ID   string `json:"id,omitempty"`
+
+	// Resolution is the interval of the samples, in nanoseconds.
+	Resolution time.Duration        `json:"resolution"`
+	Samples    []StatsHistorySample `json:"samples"`
+}
@@ -34,6 +34,9 @@ type Volume struct {
 	// Required: true
 	Scope string `json:"Scope"`
 
+	// snapshot
+	Snapshot *VolumeSnapshot `json:"Snapshot,omitempty"`
+
 	// Low-level details about the volume, provided by the volume driver.
 	// Details are returned as a map with key/value pairs:
 	// `{"key":"value","key2":"value2"}`.
@@ -47,6 +50,21 @@ type Volume struct {
 	UsageData *VolumeUsageData `json:"UsageData,omitempty"`
 }
 
+// VolumeSnapshot The origin of a volume created as a snapshot of another volume. This
+// field is omitted for other volumes.
+//
+// swagger:model VolumeSnapshot
+type VolumeSnapshot struct {
+
+	// Date/Time the snapshot was taken.
+	// Required: true
+	CreatedAt string `json:"CreatedAt"`
+
+	// Name of the volume the snapshot was taken of.
+	// Required: true
+	Source string `json:"Source"`
+}
+
 // VolumeUsageData Usage details about the volume. This information is used by the
 // `GET /system/df` endpoint, and omitted in other endpoints.
 //
@@ -0,0 +1,21 @@
+package volume // import "github.com/docker/docker/api/types/volume"
+
+// ----------------------------------------------------------------------------
+// DO NOT EDIT THIS FILE
+// This file was generated by `swagger generate operation`
+//
+// See hack/generate-swagger-api.sh
+// ----------------------------------------------------------------------------
+
+// VolumesSnapshotBody volumes snapshot body
+// swagger:model VolumesSnapshotBody
+type VolumesSnapshotBody struct {
+
+	// User-defined key/value metadata.
+	// Required: true
+	Labels map[string]string `json:"Labels"`
+
+	// The snapshot's name. If not specified, Docker generates a name.
+	// Required: true
+	Name string `json:"Name"`
+}
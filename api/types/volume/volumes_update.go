@@ -0,0 +1,21 @@
+package volume // import "github.com/docker/docker/api/types/volume"
+
+// ----------------------------------------------------------------------------
+// DO NOT EDIT THIS FILE
+// This file was generated by `swagger generate operation`
+//
+// See hack/generate-swagger-api.sh
+// ----------------------------------------------------------------------------
+
+// VolumesUpdateBody volumes update body
+// swagger:model VolumesUpdateBody
+type VolumesUpdateBody struct {
+
+	// Driver options to change. The options which are not specified are kept.
+	// Required: true
+	DriverOpts map[string]string `json:"DriverOpts"`
+
+	// User-defined key/value metadata replacing the labels of the volume. If not specified, the labels are not changed.
+	// Required: true
+	Labels map[string]string `json:"Labels"`
+}
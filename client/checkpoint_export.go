@@ -0,0 +1,28 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+)
+
+// CheckpointExport retrieves a checkpoint of the given container, along with
+// the changes to its filesystem and its configuration, as a tar archive and
+// returns it as an io.ReadCloser. It's up to the caller to close the stream.
+func (cli *Client) CheckpointExport(ctx context.Context, container string, options types.CheckpointExportOptions) (io.ReadCloser, error) {
+	if err := cli.NewVersionError("1.37", "checkpoint export"); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if options.CheckpointDir != "" {
+		query.Set("dir", options.CheckpointDir)
+	}
+
+	resp, err := cli.get(ctx, "/containers/"+container+"/checkpoints/"+options.CheckpointID+"/export", query, nil)
+	if err != nil {
+		return nil, wrapResponseError(err, resp, "container", container)
+	}
+	return resp.body, nil
+}
@@ -0,0 +1,49 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// CheckpointImport creates a container from a checkpoint exported by
+// CheckpointExport, and starts it from the checkpoint if requested.
+func (cli *Client) CheckpointImport(ctx context.Context, content io.Reader, options types.CheckpointImportOptions) (container.ContainerCreateCreatedBody, error) {
+	var response container.ContainerCreateCreatedBody
+
+	if err := cli.NewVersionError("1.37", "checkpoint import"); err != nil {
+		return response, err
+	}
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+	if options.Start {
+		query.Set("start", "1")
+	}
+	if options.HostConfig != nil {
+		hostConfigJSON, err := json.Marshal(options.HostConfig)
+		if err != nil {
+			return response, err
+		}
+		query.Set("hostConfig", string(hostConfigJSON))
+	}
+	headers := map[string][]string{
+		"Content-Type": {"application/x-tar"},
+	}
+	if options.RegistryAuth != "" {
+		headers["X-Registry-Auth"] = []string{options.RegistryAuth}
+	}
+
+	resp, err := cli.postRaw(ctx, "/containers/checkpoints/import", query, content, headers)
+	if err != nil {
+		return response, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&response)
+	ensureReaderClosed(resp)
+	return response, err
+}
@@ -0,0 +1,86 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCheckpointImportError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	_, err := client.CheckpointImport(context.Background(), strings.NewReader("bundle"), types.CheckpointImportOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestCheckpointImport(t *testing.T) {
+	expectedURL := "/containers/checkpoints/import"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			query := req.URL.Query()
+			if name := query.Get("name"); name != "migrated" {
+				return nil, fmt.Errorf("expected name 'migrated', got '%s'", name)
+			}
+			if start := query.Get("start"); start != "1" {
+				return nil, fmt.Errorf("expected start '1', got '%s'", start)
+			}
+			var hostConfig container.HostConfig
+			if err := json.Unmarshal([]byte(query.Get("hostConfig")), &hostConfig); err != nil {
+				return nil, err
+			}
+			if hostConfig.NetworkMode != "host" {
+				return nil, fmt.Errorf("expected network mode 'host', got '%s'", hostConfig.NetworkMode)
+			}
+			if auth := req.Header.Get("X-Registry-Auth"); auth != "auth" {
+				return nil, fmt.Errorf("expected X-Registry-Auth 'auth', got '%s'", auth)
+			}
+			content, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if string(content) != "bundle" {
+				return nil, fmt.Errorf("expected bundle content, got %q", content)
+			}
+			b, err := json.Marshal(container.ContainerCreateCreatedBody{ID: "container_id"})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	created, err := client.CheckpointImport(context.Background(), strings.NewReader("bundle"), types.CheckpointImportOptions{
+		Name:         "migrated",
+		Start:        true,
+		RegistryAuth: "auth",
+		HostConfig:   &container.HostConfig{NetworkMode: "host"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "container_id" {
+		t.Fatalf("expected container_id, got %s", created.ID)
+	}
+}
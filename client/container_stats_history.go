@@ -0,0 +1,47 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	timetypes "github.com/docker/docker/api/types/time"
+)
+
+// ContainerStatsHistory returns the stats history of a container, kept by
+// the docker host if its stats history is enabled.
+func (cli *Client) ContainerStatsHistory(ctx context.Context, containerID string, options types.ContainerStatsHistoryOptions) (types.StatsHistory, error) {
+	var history types.StatsHistory
+	if err := cli.NewVersionError("1.37", "container stats history"); err != nil {
+		return history, err
+	}
+
+	query := url.Values{}
+	if options.Since != "" {
+		ts, err := timetypes.GetTimestamp(options.Since, time.Now())
+		if err != nil {
+			return history, err
+		}
+		query.Set("since", ts)
+	}
+	if options.Until != "" {
+		ts, err := timetypes.GetTimestamp(options.Until, time.Now())
+		if err != nil {
+			return history, err
+		}
+		query.Set("until", ts)
+	}
+	if options.Resolution != "" {
+		query.Set("resolution", options.Resolution)
+	}
+
+	resp, err := cli.get(ctx, "/containers/"+containerID+"/stats/history", query, nil)
+	if err != nil {
+		return history, wrapResponseError(err, resp, "container", containerID)
+	}
+	err = json.NewDecoder(resp.body).Decode(&history)
+	ensureReaderClosed(resp)
+	return history, err
+}
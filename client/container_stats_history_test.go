@@ -0,0 +1,83 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestContainerStatsHistoryError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainerStatsHistory(context.Background(), "nothing", types.ContainerStatsHistoryOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestContainerStatsHistoryNotFound(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusNotFound, "Server error")),
+	}
+	_, err := client.ContainerStatsHistory(context.Background(), "unknown", types.ContainerStatsHistoryOptions{})
+	if !IsErrNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestContainerStatsHistory(t *testing.T) {
+	expectedURL := "/containers/container_id/stats/history"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			query := req.URL.Query()
+			if query.Get("since") != "1500000000" {
+				return nil, fmt.Errorf("since not set in URL query properly, got '%s'", query.Get("since"))
+			}
+			if query.Get("until") != "" {
+				return nil, fmt.Errorf("until should not be set in URL query, got '%s'", query.Get("until"))
+			}
+			if query.Get("resolution") != "1m" {
+				return nil, fmt.Errorf("resolution not set in URL query properly, got '%s'", query.Get("resolution"))
+			}
+
+			content, err := json.Marshal(types.StatsHistory{
+				ID:         "container_id",
+				Resolution: time.Minute,
+				Samples:    []types.StatsHistorySample{{MemoryUsage: 1024}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	history, err := client.ContainerStatsHistory(context.Background(), "container_id", types.ContainerStatsHistoryOptions{
+		Since:      "1500000000",
+		Resolution: "1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Resolution != time.Minute {
+		t.Fatalf("expected a resolution of 1m, got %s", history.Resolution)
+	}
+	if len(history.Samples) != 1 || history.Samples[0].MemoryUsage != 1024 {
+		t.Fatalf("unexpected samples %v", history.Samples)
+	}
+}
@@ -72,3 +72,42 @@ func TestContainerTop(t *testing.T) {
 		t.Fatalf("Titles: expected %v, got %v", expectedTitles, processList.Titles)
 	}
 }
+
+func TestContainerTopDetails(t *testing.T) {
+	// The numeric fields of a process running as root, which didn't use
+	// any CPU yet, are zero: they must still be sent.
+	expectedDetails := []container.TopProcess{
+		{PID: 1, UID: 0, User: "root", State: "S", Threads: 1, Cmdline: []string{"/bin/sh"}},
+	}
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			b, err := json.Marshal(container.ContainerTopOKBody{
+				Processes: [][]string{{"0", "1"}},
+				Titles:    []string{"UID", "PID"},
+				Details:   expectedDetails,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, field := range []string{`"UID":0`, `"PPID":0`, `"CpuPercent":0`, `"CpuTime":0`, `"Rss":0`} {
+				if !bytes.Contains(b, []byte(field)) {
+					return nil, fmt.Errorf("expected %s in %s", field, b)
+				}
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	processList, err := client.ContainerTop(context.Background(), "container_id", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedDetails, processList.Details) {
+		t.Fatalf("Details: expected %v, got %v", expectedDetails, processList.Details)
+	}
+}
@@ -0,0 +1,42 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ContainersStats returns near realtime stats for the containers matching
+// the given options, in a single stream of documents.
+// It's up to the caller to close the io.ReadCloser returned.
+func (cli *Client) ContainersStats(ctx context.Context, options types.ContainersStatsOptions) (types.ContainerStats, error) {
+	if err := cli.NewVersionError("1.37", "multiple containers stats"); err != nil {
+		return types.ContainerStats{}, err
+	}
+
+	query := url.Values{}
+	query.Set("stream", "0")
+	if options.Stream {
+		query.Set("stream", "1")
+	}
+	if options.All {
+		query.Set("all", "1")
+	}
+	if options.Filters.Len() > 0 {
+		filterJSON, err := filters.ToJSON(options.Filters)
+		if err != nil {
+			return types.ContainerStats{}, err
+		}
+		query.Set("filters", filterJSON)
+	}
+
+	resp, err := cli.get(ctx, "/containers/stats", query, nil)
+	if err != nil {
+		return types.ContainerStats{}, err
+	}
+
+	osType := getDockerOS(resp.header.Get("Server"))
+	return types.ContainerStats{Body: resp.body, OSType: osType}, err
+}
@@ -0,0 +1,67 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+func TestContainersStatsError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainersStats(context.Background(), types.ContainersStatsOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestContainersStats(t *testing.T) {
+	expectedURL := "/containers/stats"
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, r.URL)
+			}
+
+			query := r.URL.Query()
+			if stream := query.Get("stream"); stream != "1" {
+				return nil, fmt.Errorf("stream not set in URL query properly. Expected '1', got %s", stream)
+			}
+			if all := query.Get("all"); all != "" {
+				return nil, fmt.Errorf("all should not be set in URL query, got %s", all)
+			}
+			expectedFilters := `{"label":{"app=web":true}}`
+			if f := query.Get("filters"); f != expectedFilters {
+				return nil, fmt.Errorf("filters not set in URL query properly. Expected '%s', got %s", expectedFilters, f)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("response"))),
+			}, nil
+		}),
+	}
+
+	resp, err := client.ContainersStats(context.Background(), types.ContainersStatsOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "app=web")),
+		Stream:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "response" {
+		t.Fatalf("expected response to contain 'response', got %s", string(content))
+	}
+}
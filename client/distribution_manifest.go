@@ -0,0 +1,38 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	registrytypes "github.com/docker/docker/api/types/registry"
+)
+
+// DistributionManifest returns the manifest of an image in the registry,
+// including the image manifests, configurations and layers it refers to
+func (cli *Client) DistributionManifest(ctx context.Context, image, encodedRegistryAuth string) (registrytypes.DistributionManifest, error) {
+	var manifest registrytypes.DistributionManifest
+	if image == "" {
+		return manifest, objectNotFoundError{object: "distribution", id: image}
+	}
+
+	if err := cli.NewVersionError("1.37", "distribution manifest"); err != nil {
+		return manifest, err
+	}
+	var headers map[string][]string
+
+	if encodedRegistryAuth != "" {
+		headers = map[string][]string{
+			"X-Registry-Auth": {encodedRegistryAuth},
+		}
+	}
+
+	resp, err := cli.get(ctx, "/distribution/"+image+"/manifest", url.Values{}, headers)
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.NewDecoder(resp.body).Decode(&manifest)
+	ensureReaderClosed(resp)
+	return manifest, err
+}
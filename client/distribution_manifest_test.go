@@ -0,0 +1,73 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+func TestDistributionManifestUnsupported(t *testing.T) {
+	client := &Client{
+		version: "1.36",
+		client:  &http.Client{},
+	}
+	_, err := client.DistributionManifest(context.Background(), "foobar:1.0", "")
+	assert.Check(t, is.Error(err, `"distribution manifest" requires API version 1.37, but the Docker daemon API version is 1.36`))
+}
+
+func TestDistributionManifestWithEmptyID(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("should not make request")
+		}),
+	}
+	_, err := client.DistributionManifest(context.Background(), "", "")
+	if !IsErrNotFound(err) {
+		t.Fatalf("Expected NotFoundError, got %v", err)
+	}
+}
+
+func TestDistributionManifest(t *testing.T) {
+	expectedURL := "/distribution/foobar:1.0/manifest"
+	dgst := digest.FromString("manifest")
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if auth := req.Header.Get("X-Registry-Auth"); auth != "auth" {
+				return nil, fmt.Errorf("Expected X-Registry-Auth header 'auth', got '%s'", auth)
+			}
+			b, err := json.Marshal(registrytypes.DistributionManifest{
+				Descriptor: v1.Descriptor{Digest: dgst},
+				Manifests: []registrytypes.DistributionImageManifest{
+					{Descriptor: v1.Descriptor{Digest: dgst}, Size: 42},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+	manifest, err := client.DistributionManifest(context.Background(), "foobar:1.0", "auth")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(dgst, manifest.Descriptor.Digest))
+	assert.Check(t, is.Len(manifest.Manifests, 1))
+	assert.Check(t, is.Equal(int64(42), manifest.Manifests[0].Size))
+}
@@ -0,0 +1,47 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+)
+
+// ImageRebase creates a new image from an image built on top of
+// options.OldBase, with the layers of the old base replaced by the layers of
+// options.NewBase.
+func (cli *Client) ImageRebase(ctx context.Context, image string, options types.ImageRebaseOptions) (types.IDResponse, error) {
+	var response types.IDResponse
+
+	if err := cli.NewVersionError("1.37", "image rebase"); err != nil {
+		return response, err
+	}
+	query := url.Values{}
+	query.Set("oldBase", options.OldBase)
+	query.Set("newBase", options.NewBase)
+	if options.Reference != "" {
+		ref, err := reference.ParseNormalizedNamed(options.Reference)
+		if err != nil {
+			return response, err
+		}
+		if _, isCanonical := ref.(reference.Canonical); isCanonical {
+			return response, errors.New("refusing to create a tag with a digest reference")
+		}
+		ref = reference.TagNameOnly(ref)
+		query.Set("repo", reference.FamiliarName(ref))
+		if tagged, ok := ref.(reference.Tagged); ok {
+			query.Set("tag", tagged.Tag())
+		}
+	}
+
+	resp, err := cli.post(ctx, "/images/"+image+"/rebase", query, nil, nil)
+	if err != nil {
+		return response, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&response)
+	ensureReaderClosed(resp)
+	return response, err
+}
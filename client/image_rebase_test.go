@@ -0,0 +1,54 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestImageRebaseError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ImageRebase(context.Background(), "app", types.ImageRebaseOptions{OldBase: "base:1", NewBase: "base:2"})
+	assert.Check(t, is.Error(err, "Error response from daemon: Server error"))
+
+	_, err = client.ImageRebase(context.Background(), "app", types.ImageRebaseOptions{Reference: "app@sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"})
+	assert.Check(t, is.Error(err, "refusing to create a tag with a digest reference"))
+}
+
+func TestImageRebase(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/images/app/rebase" {
+				return nil, fmt.Errorf("Expected URL '/images/app/rebase', got '%s'", req.URL)
+			}
+			query := req.URL.Query()
+			for k, v := range map[string]string{"oldBase": "base:1", "newBase": "base:2", "repo": "app", "tag": "patched"} {
+				if query.Get(k) != v {
+					return nil, fmt.Errorf("%s not set in URL query properly. Expected '%s', got '%s'", k, v, query.Get(k))
+				}
+			}
+			b, err := json.Marshal(types.IDResponse{ID: "sha256:rebased"})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	resp, err := client.ImageRebase(context.Background(), "app", types.ImageRebaseOptions{OldBase: "base:1", NewBase: "base:2", Reference: "app:patched"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("sha256:rebased", resp.ID))
+}
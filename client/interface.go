@@ -64,6 +64,8 @@ type ContainerAPIClient interface {
 	ContainerRestart(ctx context.Context, container string, timeout *time.Duration) error
 	ContainerStatPath(ctx context.Context, container, path string) (types.ContainerPathStat, error)
 	ContainerStats(ctx context.Context, container string, stream bool) (types.ContainerStats, error)
+	ContainersStats(ctx context.Context, options types.ContainersStatsOptions) (types.ContainerStats, error)
+	ContainerStatsHistory(ctx context.Context, container string, options types.ContainerStatsHistoryOptions) (types.StatsHistory, error)
 	ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error
 	ContainerStop(ctx context.Context, container string, timeout *time.Duration) error
 	ContainerTop(ctx context.Context, container string, arguments []string) (containertypes.ContainerTopOKBody, error)
@@ -78,6 +80,7 @@ type ContainerAPIClient interface {
 // DistributionAPIClient defines API client methods for the registry
 type DistributionAPIClient interface {
 	DistributionInspect(ctx context.Context, image, encodedRegistryAuth string) (registry.DistributionInspect, error)
+	DistributionManifest(ctx context.Context, image, encodedRegistryAuth string) (registry.DistributionManifest, error)
 }
 
 // ImageAPIClient defines API client methods for the images
@@ -92,6 +95,7 @@ type ImageAPIClient interface {
 	ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error)
 	ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error)
 	ImagePush(ctx context.Context, ref string, options types.ImagePushOptions) (io.ReadCloser, error)
+	ImageRebase(ctx context.Context, image string, options types.ImageRebaseOptions) (types.IDResponse, error)
 	ImageRemove(ctx context.Context, image string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
 	ImageSearch(ctx context.Context, term string, options types.ImageSearchOptions) ([]registry.SearchResult, error)
 	ImageSave(ctx context.Context, images []string) (io.ReadCloser, error)
@@ -168,11 +172,16 @@ type SystemAPIClient interface {
 
 // VolumeAPIClient defines API client methods for the volumes
 type VolumeAPIClient interface {
+	VolumeClone(ctx context.Context, volumeID string, options volumetypes.VolumesCloneBody) (types.Volume, error)
 	VolumeCreate(ctx context.Context, options volumetypes.VolumesCreateBody) (types.Volume, error)
+	VolumeExport(ctx context.Context, volumeID string, options types.VolumeExportOptions) (io.ReadCloser, error)
+	VolumeImport(ctx context.Context, volumeID string, content io.Reader, options types.VolumeImportOptions) error
 	VolumeInspect(ctx context.Context, volumeID string) (types.Volume, error)
 	VolumeInspectWithRaw(ctx context.Context, volumeID string) (types.Volume, []byte, error)
 	VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumesListOKBody, error)
 	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+	VolumeSnapshot(ctx context.Context, volumeID string, options volumetypes.VolumesSnapshotBody) (types.Volume, error)
+	VolumeUpdate(ctx context.Context, volumeID string, options volumetypes.VolumesUpdateBody) (types.Volume, error)
 	VolumesPrune(ctx context.Context, pruneFilter filters.Args) (types.VolumesPruneReport, error)
 }
 
@@ -2,8 +2,10 @@ package client // import "github.com/docker/docker/client"
 
 import (
 	"context"
+	"io"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 )
 
 type apiClientExperimental interface {
@@ -15,4 +17,6 @@ type CheckpointAPIClient interface {
 	CheckpointCreate(ctx context.Context, container string, options types.CheckpointCreateOptions) error
 	CheckpointDelete(ctx context.Context, container string, options types.CheckpointDeleteOptions) error
 	CheckpointList(ctx context.Context, container string, options types.CheckpointListOptions) ([]types.Checkpoint, error)
+	CheckpointExport(ctx context.Context, container string, options types.CheckpointExportOptions) (io.ReadCloser, error)
+	CheckpointImport(ctx context.Context, content io.Reader, options types.CheckpointImportOptions) (container.ContainerCreateCreatedBody, error)
 }
@@ -0,0 +1,50 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+)
+
+// VolumeExport retrieves the content of a volume as a tar archive and
+// returns it as an io.ReadCloser. It's up to the caller to close the stream.
+func (cli *Client) VolumeExport(ctx context.Context, volumeID string, options types.VolumeExportOptions) (io.ReadCloser, error) {
+	if err := cli.NewVersionError("1.37", "volume export"); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if options.Compression != "" {
+		query.Set("compression", options.Compression)
+	}
+	resp, err := cli.get(ctx, "/volumes/"+volumeID+"/archive", query, nil)
+	if err != nil {
+		return nil, wrapResponseError(err, resp, "volume", volumeID)
+	}
+	return resp.body, nil
+}
+
+// VolumeImport extracts a tar archive, which may be compressed, into a
+// volume. The volume must be empty unless options.Overwrite is set.
+func (cli *Client) VolumeImport(ctx context.Context, volumeID string, content io.Reader, options types.VolumeImportOptions) error {
+	if err := cli.NewVersionError("1.37", "volume import"); err != nil {
+		return err
+	}
+	query := url.Values{}
+	if options.Overwrite {
+		query.Set("overwrite", "1")
+	}
+	resp, err := cli.putRaw(ctx, "/volumes/"+volumeID+"/archive", query, content, nil)
+	if err != nil {
+		return wrapResponseError(err, resp, "volume", volumeID)
+	}
+	defer ensureReaderClosed(resp)
+
+	if resp.statusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from daemon: %d", resp.statusCode)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+// VolumeSnapshot creates a volume holding a point-in-time snapshot of
+// another volume in the docker host.
+func (cli *Client) VolumeSnapshot(ctx context.Context, volumeID string, options volumetypes.VolumesSnapshotBody) (types.Volume, error) {
+	var volume types.Volume
+	if err := cli.NewVersionError("1.37", "volume snapshot"); err != nil {
+		return volume, err
+	}
+	resp, err := cli.post(ctx, "/volumes/"+volumeID+"/snapshot", nil, options, nil)
+	if err != nil {
+		return volume, wrapResponseError(err, resp, "volume", volumeID)
+	}
+	err = json.NewDecoder(resp.body).Decode(&volume)
+	ensureReaderClosed(resp)
+	return volume, err
+}
@@ -0,0 +1,87 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+func TestVolumeSnapshotError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	_, err := client.VolumeSnapshot(context.Background(), "source", volumetypes.VolumesSnapshotBody{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestVolumeSnapshotNotFound(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusNotFound, "Server error")),
+	}
+
+	_, err := client.VolumeSnapshot(context.Background(), "unknown", volumetypes.VolumesSnapshotBody{})
+	if !IsErrNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestVolumeSnapshot(t *testing.T) {
+	expectedURL := "/volumes/source/snapshot"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+
+			var body volumetypes.VolumesSnapshotBody
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			if body.Name != "copy" {
+				return nil, fmt.Errorf("expected name 'copy', got '%s'", body.Name)
+			}
+
+			content, err := json.Marshal(types.Volume{
+				Name:       "copy",
+				Driver:     "local",
+				Mountpoint: "mountpoint",
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	volume, err := client.VolumeSnapshot(context.Background(), "source", volumetypes.VolumesSnapshotBody{
+		Name:   "copy",
+		Labels: map[string]string{"label-key": "label-value"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Name != "copy" {
+		t.Fatalf("expected volume.Name to be 'copy', got %s", volume.Name)
+	}
+	if volume.Mountpoint != "mountpoint" {
+		t.Fatalf("expected volume.Mountpoint to be 'mountpoint', got %s", volume.Mountpoint)
+	}
+}
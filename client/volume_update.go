@@ -0,0 +1,24 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+// VolumeUpdate changes the labels and options of a volume in the docker host.
+func (cli *Client) VolumeUpdate(ctx context.Context, volumeID string, options volumetypes.VolumesUpdateBody) (types.Volume, error) {
+	var volume types.Volume
+	if err := cli.NewVersionError("1.37", "volume update"); err != nil {
+		return volume, err
+	}
+	resp, err := cli.post(ctx, "/volumes/"+volumeID+"/update", nil, options, nil)
+	if err != nil {
+		return volume, wrapResponseError(err, resp, "volume", volumeID)
+	}
+	err = json.NewDecoder(resp.body).Decode(&volume)
+	ensureReaderClosed(resp)
+	return volume, err
+}
@@ -0,0 +1,87 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+func TestVolumeUpdateError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+
+	_, err := client.VolumeUpdate(context.Background(), "volume", volumetypes.VolumesUpdateBody{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestVolumeUpdateNotFound(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusNotFound, "Server error")),
+	}
+
+	_, err := client.VolumeUpdate(context.Background(), "unknown", volumetypes.VolumesUpdateBody{})
+	if !IsErrNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestVolumeUpdate(t *testing.T) {
+	expectedURL := "/volumes/volume/update"
+
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != expectedURL {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+
+			var body volumetypes.VolumesUpdateBody
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			if body.Labels["label-key"] != "label-value" {
+				return nil, fmt.Errorf("expected label 'label-value', got '%s'", body.Labels["label-key"])
+			}
+
+			content, err := json.Marshal(types.Volume{
+				Name:       "volume",
+				Driver:     "local",
+				Mountpoint: "mountpoint",
+				Labels:     map[string]string{"label-key": "label-value"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+
+	volume, err := client.VolumeUpdate(context.Background(), "volume", volumetypes.VolumesUpdateBody{
+		Labels: map[string]string{"label-key": "label-value"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Name != "volume" {
+		t.Fatalf("expected volume.Name to be 'volume', got %s", volume.Name)
+	}
+	if volume.Labels["label-key"] != "label-value" {
+		t.Fatalf("expected volume label to be 'label-value', got %s", volume.Labels["label-key"])
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	syslog "github.com/RackSec/srslog"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/docker/go-units"
+)
+
+// defaultAuditLogTag is the default tag of the audit records sent to syslog.
+const defaultAuditLogTag = "dockerd-audit"
+
+var auditLogFacilities = map[string]syslog.Priority{
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"user":     syslog.LOG_USER,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// newAuditLogWriter opens the destination of the audit log configured in
+// conf. It returns nil if the audit log is disabled.
+func newAuditLogWriter(conf *config.Config) (io.WriteCloser, error) {
+	switch conf.AuditLog {
+	case "":
+		return nil, nil
+	case "file":
+		return newAuditLogFile(conf.Root, conf.AuditLogOpts)
+	case "syslog":
+		return newAuditLogSyslog(conf.AuditLogOpts)
+	default:
+		return nil, fmt.Errorf("invalid audit log: %s", conf.AuditLog)
+	}
+}
+
+// auditLogFile writes the audit records to a file rotated as the log files
+// of the json-file logging driver.
+type auditLogFile struct {
+	f *loggerutils.LogFile
+}
+
+// newAuditLogFile opens the audit log file. It supports the "path",
+// "max-size", "max-file" and "compress" options; the file defaults to
+// audit.log in the data root.
+func newAuditLogFile(root string, opts map[string]string) (*auditLogFile, error) {
+	logPath := filepath.Join(root, "audit.log")
+	var capval int64 = -1
+	maxFiles := 1
+	var compress bool
+	for k, v := range opts {
+		var err error
+		switch k {
+		case "path":
+			if !filepath.IsAbs(v) {
+				return nil, fmt.Errorf("audit log path must be absolute: %s", v)
+			}
+			logPath = v
+		case "max-size":
+			capval, err = units.FromHumanSize(v)
+			if err == nil && capval <= 0 {
+				err = fmt.Errorf("max-size should be a positive number")
+			}
+		case "max-file":
+			maxFiles, err = strconv.Atoi(v)
+			if err == nil && maxFiles < 1 {
+				err = fmt.Errorf("max-file cannot be less than 1")
+			}
+		case "compress":
+			compress, err = strconv.ParseBool(v)
+		default:
+			err = fmt.Errorf("unknown option %s", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit log option %s: %v", k, err)
+		}
+	}
+	if compress && (maxFiles == 1 || capval == -1) {
+		return nil, fmt.Errorf("audit log compress cannot be true when max-file is less than 2 or max-size is not set")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return nil, err
+	}
+	f, err := loggerutils.NewLogFile(logPath, capval, maxFiles, compress, marshalAuditRecord, nil, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLogFile{f: f}, nil
+}
+
+// marshalAuditRecord returns the audit record held by the message. It is
+// copied as the message is recycled before it is written.
+func marshalAuditRecord(msg *logger.Message) ([]byte, error) {
+	return append([]byte(nil), msg.Line...), nil
+}
+
+func (l *auditLogFile) Write(b []byte) (int, error) {
+	msg := logger.NewMessage()
+	msg.Line = append(msg.Line, b...)
+	msg.Timestamp = time.Now()
+	if err := l.f.WriteLogEntry(msg); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (l *auditLogFile) Close() error {
+	return l.f.Close()
+}
+
+// newAuditLogSyslog connects to the syslog server receiving the audit log.
+// It supports the "address" (proto://address, the local syslog server by
+// default), "facility" and "tag" options.
+func newAuditLogSyslog(opts map[string]string) (*syslog.Writer, error) {
+	var proto, address string
+	facility := syslog.LOG_AUTH
+	tag := defaultAuditLogTag
+	for k, v := range opts {
+		switch k {
+		case "address":
+			u, err := url.Parse(v)
+			if err != nil || u.Scheme == "" {
+				return nil, fmt.Errorf("invalid audit log option address: should be in form proto://address, got %v", v)
+			}
+			proto = u.Scheme
+			switch proto {
+			case "unix", "unixgram":
+				address = u.Path
+			case "tcp", "udp":
+				address = u.Host
+				if u.Port() == "" {
+					address += ":514"
+				}
+			default:
+				return nil, fmt.Errorf("invalid audit log option address: unsupported protocol %s", proto)
+			}
+		case "facility":
+			f, ok := auditLogFacilities[v]
+			if !ok {
+				return nil, fmt.Errorf("invalid audit log option facility: %s", v)
+			}
+			facility = f
+		case "tag":
+			tag = v
+		default:
+			return nil, fmt.Errorf("invalid audit log option %s: unknown option", k)
+		}
+	}
+	return syslog.Dial(proto, address, facility|syslog.LOG_INFO, tag)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/fs"
+)
+
+func TestAuditLogFile(t *testing.T) {
+	dir := fs.NewDir(t, "audit-log")
+	defer dir.Remove()
+
+	logPath := filepath.Join(dir.Path(), "audit", "api.log")
+	l, err := newAuditLogFile(dir.Path(), map[string]string{"path": logPath, "max-size": "10", "max-file": "2"})
+	assert.NilError(t, err)
+	for _, line := range []string{"{\"a\":1}\n", "{\"b\":2}\n", "{\"c\":3}\n"} {
+		_, err := l.Write([]byte(line))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, l.Close())
+
+	b, err := ioutil.ReadFile(logPath)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("{\"c\":3}\n", string(b)))
+	b, err = ioutil.ReadFile(logPath + ".1")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("{\"a\":1}\n{\"b\":2}\n", string(b)))
+}
+
+func TestAuditLogFileInvalidOptions(t *testing.T) {
+	cases := []struct {
+		opts map[string]string
+		err  string
+	}{
+		{map[string]string{"path": "audit.log"}, "must be absolute"},
+		{map[string]string{"max-size": "-1"}, "invalid audit log option max-size"},
+		{map[string]string{"max-file": "0"}, "max-file cannot be less than 1"},
+		{map[string]string{"compress": "true"}, "compress cannot be true"},
+		{map[string]string{"address": "udp://localhost"}, "unknown option"},
+	}
+	for _, c := range cases {
+		_, err := newAuditLogFile("/nonexistent", c.opts)
+		assert.Check(t, is.ErrorContains(err, c.err), "%v", c.opts)
+	}
+}
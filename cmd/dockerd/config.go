@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/audit"
 	"github.com/docker/docker/registry"
 	"github.com/spf13/pflag"
 )
@@ -24,6 +25,11 @@ func installCommonConfigFlags(conf *config.Config, flags *pflag.FlagSet) {
 
 	flags.Var(opts.NewNamedListOptsRef("storage-opts", &conf.GraphOptions, nil), "storage-opt", "Storage driver options")
 	flags.Var(opts.NewNamedListOptsRef("authorization-plugins", &conf.AuthorizationPlugins, nil), "authorization-plugin", "Authorization plugins to load")
+	flags.Var(config.NewAuthorizationPolicyOpt(&conf.AuthorizationPolicy), "authorization-policy", "Path to the authorization policy file")
+	flags.Var(config.NewListenerScopeOpt(&conf.ListenerScopes), "listener-scope", "Restrict the routes served on an API listener (host=read-only or host=route[,route...])")
+	flags.StringVar(&conf.AuditLog, "audit-log", "", "Audit log of the API requests (file or syslog)")
+	flags.Var(opts.NewNamedMapOpts("audit-log-opts", conf.AuditLogOpts, nil), "audit-log-opt", "Audit log options")
+	flags.StringVar(&conf.AuditLogLevel, "audit-log-level", audit.LevelMetadata, "Audit log level (metadata or request)")
 	flags.Var(opts.NewNamedListOptsRef("exec-opts", &conf.ExecOptions, nil), "exec-opt", "Runtime execution options")
 	flags.StringVarP(&conf.Pidfile, "pidfile", "p", defaultPidFile, "Path to use for daemon PID file")
 	flags.StringVarP(&conf.Root, "graph", "g", defaultDataRoot, "Root of the Docker runtime")
@@ -69,6 +75,18 @@ func installCommonConfigFlags(conf *config.Config, flags *pflag.FlagSet) {
 
 	flags.Var(opts.NewNamedListOptsRef("node-generic-resources", &conf.NodeGenericResources, opts.ValidateSingleGenericResource), "node-generic-resource", "Advertise user-defined resource")
 
+	flags.Var(config.NewImagePolicyOpt(&conf.ImagePolicy), "image-policy", "Path to the image signature policy file")
+	flags.StringVar(&conf.PushCompression, "push-compression", "gzip", "Compression used for pushed image layers (gzip or zstd)")
+	flags.IntVar(&conf.PushCompressionLevel, "push-compression-level", 0, "Compression level used for pushed image layers (0 for the default level)")
+	flags.IntVar(&conf.ImageGCHighThreshold, "image-gc-high-threshold", 0, "Disk usage percentage above which unused images are removed (0 to disable)")
+	flags.IntVar(&conf.ImageGCLowThreshold, "image-gc-low-threshold", 80, "Disk usage percentage down to which unused images are removed")
+	flags.StringVar(&conf.ImageGCMinAge, "image-gc-min-age", "1h", "Minimum time an image must be unused before it can be removed")
+	flags.Var(opts.NewNamedMapOpts("csi-plugins", conf.CSIPlugins, nil), "csi-plugin", "Register a CSI plugin as a volume driver (name=socket path)")
+
+	conf.StatsHistoryMaxMemory = opts.MemBytes(config.DefaultStatsHistoryMaxMemory)
+	flags.BoolVar(&conf.StatsHistory, "stats-history", false, "Keep a history of the stats of containers in memory")
+	flags.Var(&conf.StatsHistoryMaxMemory, "stats-history-max-memory", "Maximum memory used by the stats history of containers")
+
 	flags.IntVar(&conf.NetworkControlPlaneMTU, "network-control-plane-mtu", config.DefaultNetworkMtu, "Network Control plane MTU")
 
 	// "--deprecated-key-path" is to allow configuration of the key used
@@ -13,6 +13,12 @@ var (
 	defaultPidFile  = "/var/run/docker.pid"
 	defaultDataRoot = "/var/lib/docker"
 	defaultExecRoot = "/var/run/docker"
+	// defaultOOMScoreAdjust is reset to 0 in rootless mode, as an
+	// unprivileged daemon cannot lower its oom_score_adj.
+	defaultOOMScoreAdjust = -500
+	// defaultHost is the address listened on when no host is specified.
+	// The empty string selects the platform default (opts.DefaultHost).
+	defaultHost string
 )
 
 // installUnixConfigFlags adds command-line options to the top-level flag parser for
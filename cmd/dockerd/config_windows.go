@@ -12,6 +12,7 @@ var (
 	defaultPidFile  string
 	defaultDataRoot = filepath.Join(os.Getenv("programdata"), "docker")
 	defaultExecRoot = filepath.Join(os.Getenv("programdata"), "docker", "exec-root")
+	defaultHost     string
 )
 
 // installConfigFlags adds flags to the pflag.FlagSet to configure the daemon
@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -63,6 +65,7 @@ type DaemonCli struct {
 	api             *apiserver.Server
 	d               *daemon.Daemon
 	authzMiddleware *authorization.Middleware // authzMiddleware enables to dynamically reload the authorization plugins
+	auditLog        io.Closer                 // auditLog is the destination of the audit log, closed on shutdown
 }
 
 // NewDaemonCli returns a daemon CLI
@@ -206,6 +209,8 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 
 	initRouter(routerOptions)
 
+	d.StartImageGC(routerOptions.buildCache)
+
 	// process cluster change notifications
 	watchCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -228,6 +233,9 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 	c.Cleanup()
 	shutdownDaemon(d)
 	containerdRemote.Cleanup()
+	if cli.auditLog != nil {
+		cli.auditLog.Close()
+	}
 	if errAPI != nil {
 		return fmt.Errorf("Shutting down due to ServeAPI error: %v", errAPI)
 	}
@@ -470,8 +478,31 @@ func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config
 	}
 
 	cli.authzMiddleware = authorization.NewMiddleware(cli.Config.AuthorizationPlugins, pluginStore)
+	if cli.Config.AuthorizationPolicy != nil {
+		p, err := authorization.NewPolicy(*cli.Config.AuthorizationPolicy)
+		if err != nil {
+			return err
+		}
+		cli.authzMiddleware.SetPolicy(p)
+	}
 	cli.Config.AuthzMiddleware = cli.authzMiddleware
 	s.UseMiddleware(cli.authzMiddleware)
+
+	// The audit middleware is the outermost one, so that the requests
+	// rejected by the other middlewares are recorded as well.
+	w, err := newAuditLogWriter(cli.Config)
+	if err != nil {
+		return err
+	}
+	if w != nil {
+		am, err := middleware.NewAuditMiddleware(w, cli.Config.AuditLogLevel)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		cli.auditLog = w
+		s.UseMiddleware(am)
+	}
 	return nil
 }
 
@@ -514,13 +545,24 @@ func newAPIServerConfig(cli *DaemonCli) (*apiserver.Config, error) {
 	}
 
 	if len(cli.Config.Hosts) == 0 {
-		cli.Config.Hosts = make([]string, 1)
+		cli.Config.Hosts = []string{defaultHost}
 	}
 
 	return serverConfig, nil
 }
 
 func loadListeners(cli *DaemonCli, serverConfig *apiserver.Config) ([]string, error) {
+	// The scopes are keyed by the addresses of the listeners as parsed by
+	// ParseHost, so that they match however the addresses are written.
+	scopes := make(map[string]authorization.Scope)
+	for host, scope := range cli.Config.ListenerScopes {
+		h, err := dopts.ParseHost(cli.Config.TLS, host)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing listener scope %s : %v", host, err)
+		}
+		scopes[h] = scope
+	}
+
 	var hosts []string
 	for i := 0; i < len(cli.Config.Hosts); i++ {
 		var err error
@@ -554,9 +596,25 @@ func loadListeners(cli *DaemonCli, serverConfig *apiserver.Config) ([]string, er
 		}
 		logrus.Debugf("Listener created for HTTP on %s (%s)", proto, addr)
 		hosts = append(hosts, protoAddrParts[1])
-		cli.api.Accept(addr, ls...)
+		if scope, ok := scopes[protoAddr]; ok {
+			logrus.Infof("API listener %s restricted to %s", protoAddr, scope.String())
+			cli.api.AcceptScoped(addr, &scope, ls...)
+			delete(scopes, protoAddr)
+		} else {
+			cli.api.Accept(addr, ls...)
+		}
 	}
 
+	// A scope matching no listener is most likely a typo, which would leave
+	// the intended listener unrestricted.
+	if len(scopes) > 0 {
+		var unmatched []string
+		for host := range scopes {
+			unmatched = append(unmatched, host)
+		}
+		sort.Strings(unmatched)
+		return nil, fmt.Errorf("listener scopes do not match any host: %s", strings.Join(unmatched, ", "))
+	}
 	return hosts, nil
 }
 
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/docker/docker/pkg/rootless"
+	"github.com/sirupsen/logrus"
+)
+
+// When running inside the namespaces set up by RootlessKit, the daemon
+// cannot write to the system-wide locations, so the default paths follow
+// the XDG base directory specification instead.
+func init() {
+	if !rootless.RunningWithRootlessKit() {
+		return
+	}
+	defaultOOMScoreAdjust = 0
+	runtimeDir, err := homedir.GetRuntimeDir()
+	if err != nil {
+		logrus.Warnf("Running in rootless mode without XDG_RUNTIME_DIR: %v", err)
+	} else {
+		defaultPidFile = filepath.Join(runtimeDir, "docker.pid")
+		defaultExecRoot = filepath.Join(runtimeDir, "docker")
+		defaultHost = "unix://" + filepath.Join(runtimeDir, "docker.sock")
+	}
+	if dataHome, err := homedir.GetDataHome(); err == nil {
+		defaultDataRoot = filepath.Join(dataHome, "docker")
+	}
+	if configHome, err := homedir.GetConfigHome(); err == nil {
+		defaultDaemonConfigDir = filepath.Join(configHome, "docker")
+		defaultDaemonConfigFile = filepath.Join(defaultDaemonConfigDir, "daemon.json")
+	}
+}
@@ -3,6 +3,7 @@
 package container // import "github.com/docker/docker/container"
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/volume"
 	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/docker/go-connections/nat"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -351,6 +353,25 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 		container.HostConfig.RestartPolicy = hostConfig.RestartPolicy
 	}
 
+	if hostConfig.PortBindings != nil {
+		networkMode := container.HostConfig.NetworkMode
+		if networkMode.IsHost() || networkMode.IsContainer() || networkMode.IsNone() {
+			return conflictingUpdateOptions(fmt.Sprintf("Port bindings cannot be updated because the container uses the %s network mode", networkMode.NetworkName()))
+		}
+		container.HostConfig.PortBindings = hostConfig.PortBindings
+
+		// Ports must be exposed to be published. The set is copied so that
+		// the original one can be restored if the update fails.
+		exposedPorts := make(nat.PortSet, len(container.Config.ExposedPorts)+len(hostConfig.PortBindings))
+		for port := range container.Config.ExposedPorts {
+			exposedPorts[port] = struct{}{}
+		}
+		for port := range hostConfig.PortBindings {
+			exposedPorts[port] = struct{}{}
+		}
+		container.Config.ExposedPorts = exposedPorts
+	}
+
 	return nil
 }
 
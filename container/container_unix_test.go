@@ -0,0 +1,45 @@
+// +build linux freebsd
+
+package container // import "github.com/docker/docker/container"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestUpdateContainerPortBindings(t *testing.T) {
+	exposed := nat.PortSet{"80/tcp": {}}
+	c := &Container{
+		Config:     &container.Config{ExposedPorts: exposed},
+		HostConfig: &container.HostConfig{NetworkMode: "bridge"},
+	}
+
+	bindings := nat.PortMap{"8080/tcp": {{HostPort: "8080"}}}
+	assert.NilError(t, c.UpdateContainer(&container.HostConfig{PortBindings: bindings}))
+	assert.Check(t, is.DeepEqual(bindings, c.HostConfig.PortBindings))
+	assert.Check(t, is.DeepEqual(nat.PortSet{"80/tcp": {}, "8080/tcp": {}}, c.Config.ExposedPorts))
+	// The original set is left untouched so that it can be restored.
+	assert.Check(t, is.DeepEqual(nat.PortSet{"80/tcp": {}}, exposed))
+
+	assert.NilError(t, c.UpdateContainer(&container.HostConfig{PortBindings: nat.PortMap{}}))
+	assert.Check(t, is.Len(c.HostConfig.PortBindings, 0))
+
+	// Port bindings are left unchanged if not set.
+	assert.NilError(t, c.UpdateContainer(&container.HostConfig{}))
+	assert.Check(t, c.HostConfig.PortBindings != nil)
+}
+
+func TestUpdateContainerPortBindingsNetworkMode(t *testing.T) {
+	for _, mode := range []container.NetworkMode{"host", "none", "container:foo"} {
+		c := &Container{
+			Config:     &container.Config{},
+			HostConfig: &container.HostConfig{NetworkMode: mode},
+		}
+		err := c.UpdateContainer(&container.HostConfig{PortBindings: nat.PortMap{"80/tcp": {{HostPort: "80"}}}})
+		assert.Check(t, is.ErrorContains(err, "Port bindings cannot be updated"), string(mode))
+	}
+}
@@ -167,6 +167,9 @@ func (container *Container) UpdateContainer(hostConfig *containertypes.HostConfi
 		resources.IOMaximumBandwidth != 0 {
 		return fmt.Errorf("resource updating isn't supported on Windows")
 	}
+	if hostConfig.PortBindings != nil {
+		return fmt.Errorf("port bindings updating isn't supported on Windows")
+	}
 	// update HostConfig of container
 	if hostConfig.RestartPolicy.Name != "" {
 		if container.HostConfig.AutoRemove && !hostConfig.RestartPolicy.IsNone() {
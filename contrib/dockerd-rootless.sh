@@ -0,0 +1,89 @@
+#!/bin/sh
+# dockerd-rootless.sh executes dockerd in rootless mode.
+#
+# Usage: dockerd-rootless.sh [DOCKERD_OPTIONS]
+#
+# External dependencies:
+# * newuidmap and newgidmap need to be installed.
+# * /etc/subuid and /etc/subgid need to be configured for the current user.
+# * RootlessKit (rootlesskit) needs to be installed.
+# * The network driver of RootlessKit needs to be installed, see below.
+# * Either fuse-overlayfs needs to be installed, or the vfs storage driver
+#   is used.
+#
+# The following environment variables configure RootlessKit:
+# * DOCKERD_ROOTLESS_ROOTLESSKIT_NET: the network driver, used to connect the
+#   namespaces of the daemon to the network of the host. One of "slirp4netns"
+#   (default), "vpnkit" or "lxc-user-nic". The driver needs to be installed.
+# * DOCKERD_ROOTLESS_ROOTLESSKIT_MTU: the MTU of the network driver (default
+#   65520 for slirp4netns and vpnkit, 1500 for lxc-user-nic).
+# * DOCKERD_ROOTLESS_ROOTLESSKIT_PORT_DRIVER: the driver exposing the
+#   published ports on the host, "builtin" (default) or "slirp4netns".
+#
+# The data root, the exec root and the socket of the daemon default to
+# $XDG_DATA_HOME/docker, $XDG_RUNTIME_DIR/docker and
+# $XDG_RUNTIME_DIR/docker.sock. The configuration file defaults to
+# $XDG_CONFIG_HOME/docker/daemon.json.
+
+set -e -x
+if ! [ -w "$XDG_RUNTIME_DIR" ]; then
+	echo "XDG_RUNTIME_DIR needs to be set and writable"
+	exit 1
+fi
+if ! [ -w "$HOME" ]; then
+	echo "HOME needs to be set and writable"
+	exit 1
+fi
+
+rootlesskit=""
+for f in docker-rootlesskit rootlesskit; do
+	if which "$f" > /dev/null 2>&1; then
+		rootlesskit="$f"
+		break
+	fi
+done
+if [ -z "$rootlesskit" ]; then
+	echo "rootlesskit needs to be installed"
+	exit 1
+fi
+
+net="${DOCKERD_ROOTLESS_ROOTLESSKIT_NET:-slirp4netns}"
+mtu="$DOCKERD_ROOTLESS_ROOTLESSKIT_MTU"
+port_driver="${DOCKERD_ROOTLESS_ROOTLESSKIT_PORT_DRIVER:-builtin}"
+case "$net" in
+	slirp4netns | vpnkit)
+		mtu="${mtu:-65520}"
+		;;
+	lxc-user-nic)
+		mtu="${mtu:-1500}"
+		;;
+	*)
+		echo "unsupported network driver: $net"
+		exit 1
+		;;
+esac
+if ! which "$net" > /dev/null 2>&1 && [ "$net" != "lxc-user-nic" ]; then
+	echo "$net needs to be installed"
+	exit 1
+fi
+
+if [ -z "$_DOCKERD_ROOTLESS_CHILD" ]; then
+	_DOCKERD_ROOTLESS_CHILD=1
+	export _DOCKERD_ROOTLESS_CHILD
+	# Re-exec the script via RootlessKit, so as to create the user, mount
+	# and network namespaces.
+	#
+	# --copy-up allows removing and creating files in the directories by
+	# creating a tmpfs and symlinks for the existing files.
+	exec "$rootlesskit" \
+		--net="$net" --mtu="$mtu" \
+		--disable-host-loopback --port-driver="$port_driver" \
+		--copy-up=/etc --copy-up=/run \
+		$DOCKERD_ROOTLESS_ROOTLESSKIT_FLAGS \
+		"$0" "$@"
+else
+	[ "$_DOCKERD_ROOTLESS_CHILD" = 1 ]
+	# Remove the symlinks to the files of the host created by --copy-up.
+	rm -f /run/docker /run/xtables.lock
+	exec dockerd --rootless "$@"
+fi
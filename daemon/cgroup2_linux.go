@@ -0,0 +1,380 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// cgroup2Mountpoint is the mount point of the cgroup v2 unified hierarchy.
+const cgroup2Mountpoint = "/sys/fs/cgroup"
+
+// cgroup2Setting is a value to write to a file of a cgroup v2 directory.
+type cgroup2Setting struct {
+	file  string
+	value string
+}
+
+// cpuSharesToWeight maps the shares range [2, 262144] to the weight range
+// [1, 10000]. Shares outside of that range are clamped to it first, as the
+// kernel does for cgroup v1.
+func cpuSharesToWeight(shares uint64) uint64 {
+	if shares < linuxMinCPUShares {
+		shares = linuxMinCPUShares
+	} else if shares > linuxMaxCPUShares {
+		shares = linuxMaxCPUShares
+	}
+	return 1 + ((shares-linuxMinCPUShares)*9999)/(linuxMaxCPUShares-linuxMinCPUShares)
+}
+
+// cgroup2Settings converts the resources of a runtime spec, which are
+// expressed in terms of cgroup v1 controllers, to the files of the cgroup v2
+// controllers. Zero values are left unchanged, -1 means unlimited.
+func cgroup2Settings(r *specs.LinuxResources) []cgroup2Setting {
+	var settings []cgroup2Setting
+	set := func(file, value string) {
+		settings = append(settings, cgroup2Setting{file: file, value: value})
+	}
+	limit := func(v int64) string {
+		if v < 0 {
+			return "max"
+		}
+		return strconv.FormatInt(v, 10)
+	}
+
+	if m := r.Memory; m != nil {
+		if m.Limit != nil && *m.Limit != 0 {
+			set("memory.max", limit(*m.Limit))
+		}
+		if m.Reservation != nil && *m.Reservation != 0 {
+			set("memory.low", limit(*m.Reservation))
+		}
+		// The v1 swap limit includes memory, the v2 one doesn't.
+		if m.Swap != nil && *m.Swap != 0 {
+			switch {
+			case *m.Swap < 0:
+				set("memory.swap.max", "max")
+			case m.Limit != nil && *m.Limit > 0 && *m.Swap >= *m.Limit:
+				set("memory.swap.max", strconv.FormatInt(*m.Swap-*m.Limit, 10))
+			}
+		}
+	}
+
+	if c := r.CPU; c != nil {
+		if c.Shares != nil && *c.Shares != 0 {
+			set("cpu.weight", strconv.FormatUint(cpuSharesToWeight(*c.Shares), 10))
+		}
+		if c.Quota != nil && *c.Quota != 0 {
+			period := uint64(100 * time.Millisecond / time.Microsecond)
+			if c.Period != nil && *c.Period != 0 {
+				period = *c.Period
+			}
+			set("cpu.max", fmt.Sprintf("%s %d", limit(*c.Quota), period))
+		} else if c.Period != nil && *c.Period != 0 {
+			set("cpu.max", fmt.Sprintf("max %d", *c.Period))
+		}
+		if c.Cpus != "" {
+			set("cpuset.cpus", c.Cpus)
+		}
+		if c.Mems != "" {
+			set("cpuset.mems", c.Mems)
+		}
+	}
+
+	if b := r.BlockIO; b != nil {
+		// Map the blkio weight range [10, 1000] to the io weight range [1, 10000].
+		ioWeight := func(w uint16) uint64 {
+			return 1 + (uint64(w)-10)*9999/990
+		}
+		if b.Weight != nil && *b.Weight != 0 {
+			set("io.weight", fmt.Sprintf("default %d", ioWeight(*b.Weight)))
+		}
+		for _, wd := range b.WeightDevice {
+			if wd.Weight != nil && *wd.Weight != 0 {
+				set("io.weight", fmt.Sprintf("%d:%d %d", wd.Major, wd.Minor, ioWeight(*wd.Weight)))
+			}
+		}
+		for _, t := range []struct {
+			key     string
+			devices []specs.LinuxThrottleDevice
+		}{
+			{"rbps", b.ThrottleReadBpsDevice},
+			{"wbps", b.ThrottleWriteBpsDevice},
+			{"riops", b.ThrottleReadIOPSDevice},
+			{"wiops", b.ThrottleWriteIOPSDevice},
+		} {
+			for _, d := range t.devices {
+				set("io.max", fmt.Sprintf("%d:%d %s=%d", d.Major, d.Minor, t.key, d.Rate))
+			}
+		}
+	}
+
+	if p := r.Pids; p != nil && p.Limit != 0 {
+		set("pids.max", limit(p.Limit))
+	}
+
+	return settings
+}
+
+// cgroup2Path returns the directory of the cgroup v2 of the process pid.
+func cgroup2Path(pid int) (string, error) {
+	cg, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	p, ok := cg[""]
+	if !ok {
+		return "", errors.Errorf("process %d is not in a cgroup v2", pid)
+	}
+	return filepath.Join(cgroup2Mountpoint, p), nil
+}
+
+// applyCgroup2Resources writes the resources r to the cgroup v2 of the
+// process pid. Settings of controllers which aren't enabled for the cgroup
+// are skipped: the container settings are verified against the available
+// controllers beforehand.
+func applyCgroup2Resources(pid int, r *specs.LinuxResources) error {
+	if r == nil {
+		return nil
+	}
+	dir, err := cgroup2Path(pid)
+	if err != nil {
+		return err
+	}
+	for _, s := range cgroup2Settings(r) {
+		if err := ioutil.WriteFile(filepath.Join(dir, s.file), []byte(s.value), 0); err != nil {
+			if os.IsNotExist(err) {
+				logrus.WithField("file", s.file).Debug("Skipping resource of disabled cgroup v2 controller")
+				continue
+			}
+			return errors.Wrapf(err, "failed to write %q to %s", s.value, s.file)
+		}
+	}
+	return nil
+}
+
+// cgroup2ResourcesHook is the name of the prestart hook writing the
+// resources of the spec to the cgroup v2 of the container, for runtimes
+// which only apply the cgroup v1 resources of the spec. As a prestart hook,
+// it runs before the process of the container is started, and the container
+// fails to start if the resources can't be applied.
+const cgroup2ResourcesHook = "docker-cgroup2-resources"
+
+func init() {
+	reexec.Register(cgroup2ResourcesHook, cgroup2ResourcesHookMain)
+}
+
+func cgroup2ResourcesHookMain() {
+	if err := runCgroup2ResourcesHook(os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set cgroup v2 resources of container: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// runCgroup2ResourcesHook applies the resources of the spec of the bundle to
+// the cgroup of the container, both given by the state of the container the
+// runtime passes to the hook on in.
+func runCgroup2ResourcesHook(in io.Reader) error {
+	var state specs.State
+	if err := json.NewDecoder(in).Decode(&state); err != nil {
+		return errors.Wrap(err, "failed to decode the state of the container")
+	}
+	b, err := ioutil.ReadFile(filepath.Join(state.Bundle, "config.json"))
+	if err != nil {
+		return err
+	}
+	var spec specs.Spec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return errors.Wrap(err, "failed to decode the spec of the container")
+	}
+	if spec.Linux == nil {
+		return nil
+	}
+	return applyCgroup2Resources(state.Pid, spec.Linux.Resources)
+}
+
+// cgroup2Stats returns the stats of the container read from its cgroup v2.
+func (daemon *Daemon) cgroup2Stats(c *container.Container) (*types.StatsJSON, error) {
+	dir, err := cgroup2Path(c.GetPID())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errNotRunning(c.ID)
+		}
+		return nil, err
+	}
+	s, err := readCgroup2Stats(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.Read = time.Now()
+
+	// if the container does not set memory limit, use the machineMemory
+	if (s.MemoryStats.Limit == 0 || s.MemoryStats.Limit > daemon.machineMemory) && daemon.machineMemory > 0 {
+		s.MemoryStats.Limit = daemon.machineMemory
+	}
+	return s, nil
+}
+
+// readCgroup2Stats reads the stats of the controllers enabled in the cgroup
+// v2 directory dir.
+func readCgroup2Stats(dir string) (*types.StatsJSON, error) {
+	s := &types.StatsJSON{}
+
+	cpu, err := readCgroup2KeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if cpu != nil {
+		s.CPUStats = types.CPUStats{
+			CPUUsage: types.CPUUsage{
+				TotalUsage:        cpu["usage_usec"] * 1000,
+				UsageInKernelmode: cpu["system_usec"] * 1000,
+				UsageInUsermode:   cpu["user_usec"] * 1000,
+			},
+			ThrottlingData: types.ThrottlingData{
+				Periods:          cpu["nr_periods"],
+				ThrottledPeriods: cpu["nr_throttled"],
+				ThrottledTime:    cpu["throttled_usec"] * 1000,
+			},
+		}
+	}
+
+	memory, err := readCgroup2KeyValues(filepath.Join(dir, "memory.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if memory != nil {
+		s.MemoryStats.Stats = memory
+		if s.MemoryStats.Usage, err = readCgroup2Uint(filepath.Join(dir, "memory.current")); err != nil {
+			return nil, err
+		}
+		if s.MemoryStats.Limit, err = readCgroup2Uint(filepath.Join(dir, "memory.max")); err != nil {
+			return nil, err
+		}
+		events, err := readCgroup2KeyValues(filepath.Join(dir, "memory.events"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		s.MemoryStats.Failcnt = events["max"]
+	}
+
+	io, err := readCgroup2IOStat(filepath.Join(dir, "io.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	s.BlkioStats = io
+
+	if _, err := os.Stat(filepath.Join(dir, "pids.current")); err == nil {
+		if s.PidsStats.Current, err = readCgroup2Uint(filepath.Join(dir, "pids.current")); err != nil {
+			return nil, err
+		}
+		if s.PidsStats.Limit, err = readCgroup2Uint(filepath.Join(dir, "pids.max")); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// readCgroup2Uint reads a file holding a single value, where "max" is
+// returned as 0.
+func readCgroup2Uint(path string) (uint64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v := strings.TrimSpace(string(content))
+	if v == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+// readCgroup2KeyValues reads a file made of "key value" lines.
+func readCgroup2KeyValues(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value in %s", path)
+		}
+		values[fields[0]] = v
+	}
+	return values, scanner.Err()
+}
+
+// readCgroup2IOStat reads the io.stat file, made of lines such as
+// "8:0 rbytes=90430464 wbytes=299008000 rios=8950 wios=1252 dbytes=0 dios=0".
+func readCgroup2IOStat(path string) (types.BlkioStats, error) {
+	var stats types.BlkioStats
+	f, err := os.Open(path)
+	if err != nil {
+		return stats, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var major, minor uint64
+		if _, err := fmt.Sscanf(fields[0], "%d:%d", &major, &minor); err != nil {
+			return stats, errors.Wrapf(err, "invalid device in %s", path)
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return stats, errors.Wrapf(err, "invalid value in %s", path)
+			}
+			entry := types.BlkioStatEntry{Major: major, Minor: minor, Value: v}
+			switch kv[0] {
+			case "rbytes":
+				entry.Op = "Read"
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, entry)
+			case "wbytes":
+				entry.Op = "Write"
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, entry)
+			case "rios":
+				entry.Op = "Read"
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, entry)
+			case "wios":
+				entry.Op = "Write"
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, entry)
+			}
+		}
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,151 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/google/go-cmp/cmp"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestCgroup2Settings(t *testing.T) {
+	memory := int64(512 * 1024 * 1024)
+	swap := int64(768 * 1024 * 1024)
+	reservation := int64(256 * 1024 * 1024)
+	shares := uint64(1024)
+	quota := int64(50000)
+	weight := uint16(500)
+	r := &specs.LinuxResources{
+		Memory: &specs.LinuxMemory{
+			Limit:       &memory,
+			Reservation: &reservation,
+			Swap:        &swap,
+		},
+		CPU: &specs.LinuxCPU{
+			Shares: &shares,
+			Quota:  &quota,
+			Cpus:   "0-1",
+		},
+		BlockIO: &specs.LinuxBlockIO{
+			Weight: &weight,
+			ThrottleReadBpsDevice: []specs.LinuxThrottleDevice{
+				{Rate: 1048576},
+			},
+		},
+		Pids: &specs.LinuxPids{Limit: -1},
+	}
+	r.BlockIO.ThrottleReadBpsDevice[0].Major = 8
+	r.BlockIO.ThrottleReadBpsDevice[0].Minor = 0
+
+	expected := []cgroup2Setting{
+		{"memory.max", "536870912"},
+		{"memory.low", "268435456"},
+		{"memory.swap.max", "268435456"},
+		{"cpu.weight", "39"},
+		{"cpu.max", "50000 100000"},
+		{"cpuset.cpus", "0-1"},
+		{"io.weight", "default 4950"},
+		{"io.max", "8:0 rbps=1048576"},
+		{"pids.max", "max"},
+	}
+	assert.Check(t, is.DeepEqual(expected, cgroup2Settings(r), cmp.AllowUnexported(cgroup2Setting{})))
+}
+
+func TestCgroup2SettingsCPUShares(t *testing.T) {
+	for _, tc := range []struct {
+		shares   uint64
+		expected []cgroup2Setting
+	}{
+		{shares: 0},
+		{shares: 1, expected: []cgroup2Setting{{"cpu.weight", "1"}}},
+		{shares: 2, expected: []cgroup2Setting{{"cpu.weight", "1"}}},
+		{shares: 1024, expected: []cgroup2Setting{{"cpu.weight", "39"}}},
+		{shares: 262144, expected: []cgroup2Setting{{"cpu.weight", "10000"}}},
+		{shares: 1 << 20, expected: []cgroup2Setting{{"cpu.weight", "10000"}}},
+	} {
+		shares := tc.shares
+		r := &specs.LinuxResources{CPU: &specs.LinuxCPU{Shares: &shares}}
+		assert.Check(t, is.DeepEqual(tc.expected, cgroup2Settings(r), cmp.AllowUnexported(cgroup2Setting{})), "shares %d", tc.shares)
+	}
+}
+
+func TestCgroup2SettingsUnchanged(t *testing.T) {
+	r := toContainerdResources(containertypes.Resources{})
+	assert.Check(t, is.Len(cgroup2Settings((*specs.LinuxResources)(r)), 0))
+}
+
+func TestReadCgroup2Stats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup2-stats")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"cpu.stat":       "usage_usec 2000\nuser_usec 1500\nsystem_usec 500\nnr_periods 10\nnr_throttled 2\nthrottled_usec 300\n",
+		"memory.stat":    "anon 4096\nfile 8192\n",
+		"memory.current": "12288\n",
+		"memory.max":     "max\n",
+		"memory.events":  "low 0\nhigh 0\nmax 3\noom 0\noom_kill 0\n",
+		"io.stat":        "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0\n",
+		"pids.current":   "4\n",
+		"pids.max":       "100\n",
+	}
+	for name, content := range files {
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	s, err := readCgroup2Stats(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(uint64(2000000), s.CPUStats.CPUUsage.TotalUsage))
+	assert.Check(t, is.Equal(uint64(1500000), s.CPUStats.CPUUsage.UsageInUsermode))
+	assert.Check(t, is.Equal(uint64(500000), s.CPUStats.CPUUsage.UsageInKernelmode))
+	assert.Check(t, is.Equal(uint64(2), s.CPUStats.ThrottlingData.ThrottledPeriods))
+	assert.Check(t, is.Equal(uint64(300000), s.CPUStats.ThrottlingData.ThrottledTime))
+	assert.Check(t, is.Equal(uint64(12288), s.MemoryStats.Usage))
+	assert.Check(t, is.Equal(uint64(0), s.MemoryStats.Limit))
+	assert.Check(t, is.Equal(uint64(3), s.MemoryStats.Failcnt))
+	assert.Check(t, is.DeepEqual(map[string]uint64{"anon": 4096, "file": 8192}, s.MemoryStats.Stats))
+	assert.Check(t, is.DeepEqual([]types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 1024},
+		{Major: 8, Minor: 0, Op: "Write", Value: 2048},
+	}, s.BlkioStats.IoServiceBytesRecursive))
+	assert.Check(t, is.Equal(uint64(4), s.PidsStats.Current))
+	assert.Check(t, is.Equal(uint64(100), s.PidsStats.Limit))
+}
+
+func TestReadCgroup2StatsDisabledControllers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup2-stats")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 1\n"), 0644))
+
+	s, err := readCgroup2Stats(dir)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(uint64(1000), s.CPUStats.CPUUsage.TotalUsage))
+	assert.Check(t, is.Len(s.MemoryStats.Stats, 0))
+	assert.Check(t, is.Equal(uint64(0), s.PidsStats.Current))
+}
+
+func TestRunCgroup2ResourcesHook(t *testing.T) {
+	bundle, err := ioutil.TempDir("", "cgroup2-hook")
+	assert.NilError(t, err)
+	defer os.RemoveAll(bundle)
+
+	state := `{"ociVersion":"1.0.1","id":"abc","status":"created","pid":1,"bundle":"` + bundle + `"}`
+	err = runCgroup2ResourcesHook(strings.NewReader(state))
+	assert.Check(t, os.IsNotExist(err), "%v", err)
+
+	// the hook has nothing to apply without linux settings
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(bundle, "config.json"), []byte(`{"ociVersion":"1.0.1"}`), 0644))
+	assert.Check(t, runCgroup2ResourcesHook(strings.NewReader(state)))
+
+	err = runCgroup2ResourcesHook(strings.NewReader("{"))
+	assert.Check(t, is.ErrorContains(err, "failed to decode the state of the container"))
+}
@@ -0,0 +1,502 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/backend"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/idtools"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// checkpointBundleVersion is the version of the format of checkpoint
+	// bundles.
+	checkpointBundleVersion = 1
+
+	// checkpointBundleManifest is the name of the manifest in a checkpoint
+	// bundle.
+	checkpointBundleManifest = "manifest.json"
+
+	// checkpointBundleCheckpoint is the prefix of the files of the
+	// checkpoint in a checkpoint bundle.
+	checkpointBundleCheckpoint = "checkpoint/"
+
+	// checkpointBundleRW is the prefix of the entries of the diff of the
+	// read-write layer in a checkpoint bundle.
+	checkpointBundleRW = "rw/"
+)
+
+// checkpointManifest describes the container a checkpoint bundle was
+// exported from.
+type checkpointManifest struct {
+	Version      int
+	CheckpointID string
+	Name         string
+	ImageID      string
+	Config       *containertypes.Config
+	HostConfig   *containertypes.HostConfig
+	Mounts       []types.MountPoint
+}
+
+// CheckpointExport writes a checkpoint of a container to out as a tar
+// archive, along with the changes to the filesystem of the container, its
+// configuration and its mounts, to create the container from the checkpoint
+// on another host. The content of volumes isn't exported.
+func (daemon *Daemon) CheckpointExport(name string, config types.CheckpointExportOptions, out io.Writer) error {
+	ctr, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+
+	if !validCheckpointNamePattern.MatchString(config.CheckpointID) {
+		return errdefs.InvalidParameter(fmt.Errorf("Invalid checkpoint ID (%s), only %s are allowed", config.CheckpointID, validCheckpointNameChars))
+	}
+	checkpointDir, err := getCheckpointDir(config.CheckpointDir, config.CheckpointID, name, ctr.ID, ctr.CheckpointDir(), false)
+	if err != nil {
+		return errdefs.NotFound(err)
+	}
+	if ctr.RWLayer == nil {
+		return errors.Errorf("container %s has no read-write layer", name)
+	}
+
+	ctr.Lock()
+	manifest, err := json.Marshal(checkpointManifest{
+		Version:      checkpointBundleVersion,
+		CheckpointID: config.CheckpointID,
+		Name:         strings.TrimPrefix(ctr.Name, "/"),
+		ImageID:      ctr.ImageID.String(),
+		Config:       ctr.Config,
+		HostConfig:   ctr.HostConfig,
+		Mounts:       ctr.GetMountPoints(),
+	})
+	ctr.Unlock()
+	if err != nil {
+		return err
+	}
+
+	diff, err := ctr.RWLayer.TarStream()
+	if err != nil {
+		return err
+	}
+	defer diff.Close()
+
+	return writeCheckpointBundle(out, manifest, checkpointDir, diff, daemon.idMappings)
+}
+
+// writeCheckpointBundle writes a checkpoint bundle to out: the manifest, the
+// directories and regular files of the checkpoint directory dir, and the
+// entries of the diff of the read-write layer, owned by the IDs of the
+// container.
+func writeCheckpointBundle(out io.Writer, manifest []byte, dir string, diff io.Reader, idMappings *idtools.IDMappings) error {
+	tw := tar.NewWriter(out)
+
+	hdr := &tar.Header{
+		Name:     checkpointBundleManifest,
+		Typeflag: tar.TypeReg,
+		Mode:     0600,
+		Size:     int64(len(manifest)),
+		ModTime:  time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !fi.IsDir() && !fi.Mode().IsRegular() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = checkpointBundleCheckpoint + filepath.ToSlash(rel)
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to archive checkpoint")
+	}
+
+	tr := tar.NewReader(diff)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to archive changes of the container")
+		}
+		hdr.Name = checkpointBundleRW + hdr.Name
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = checkpointBundleRW + hdr.Linkname
+		}
+		if idMappings != nil && !idMappings.Empty() {
+			uid, gid, err := idMappings.ToContainer(idtools.IDPair{UID: hdr.Uid, GID: hdr.Gid})
+			if err != nil {
+				return err
+			}
+			hdr.Uid, hdr.Gid = uid, gid
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// CheckpointImport creates a container from a checkpoint bundle written by
+// CheckpointExport, pulling its image if it's missing, and starts it from
+// the checkpoint if requested.
+func (daemon *Daemon) CheckpointImport(ctx context.Context, config *backend.CheckpointImportConfig, in io.Reader) (containertypes.ContainerCreateCreatedBody, error) {
+	var created containertypes.ContainerCreateCreatedBody
+
+	tmpDir, err := ioutil.TempDir("", "docker-checkpoint-import-")
+	if err != nil {
+		return created, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := readCheckpointBundle(in, tmpDir)
+	if err != nil {
+		return created, err
+	}
+	if err := daemon.pullCheckpointImage(ctx, manifest, config); err != nil {
+		return created, err
+	}
+
+	parser := volumemounts.NewParser(runtime.GOOS)
+	hostConfig := config.HostConfig
+	if hostConfig == nil {
+		// The host configuration of the bundle can't be inspected by the
+		// authorization plugins: the settings giving access to the host
+		// must be passed explicitly.
+		if privileges := hostConfigPrivileges(manifest.HostConfig, parser); len(privileges) > 0 {
+			return created, errdefs.InvalidParameter(errors.Errorf("the checkpoint bundle requests access to the host (%s): pass the host configuration explicitly to import it", strings.Join(privileges, ", ")))
+		}
+		hostConfig = manifest.HostConfig
+	}
+	restoreCheckpointMounts(hostConfig, manifest.Mounts, parser)
+
+	name := config.Name
+	if name == "" {
+		name = manifest.Name
+	}
+	created, err = daemon.ContainerCreate(types.ContainerCreateConfig{
+		Name:       name,
+		Config:     manifest.Config,
+		HostConfig: hostConfig,
+	})
+	if err != nil {
+		return created, err
+	}
+
+	if err := daemon.importCheckpoint(created.ID, manifest, tmpDir); err != nil {
+		if rmErr := daemon.ContainerRm(created.ID, &types.ContainerRmConfig{ForceRemove: true}); rmErr != nil {
+			logrus.WithError(rmErr).WithField("container", created.ID).Warn("Failed to remove container after checkpoint import failure")
+		}
+		return containertypes.ContainerCreateCreatedBody{}, err
+	}
+
+	if config.Start {
+		if err := daemon.ContainerStart(created.ID, nil, manifest.CheckpointID, ""); err != nil {
+			return created, errors.Wrapf(err, "container %s was created, but failed to start from checkpoint %s", created.ID, manifest.CheckpointID)
+		}
+	}
+	return created, nil
+}
+
+// hostConfigPrivileges returns the settings of the host configuration giving
+// the container access to the host.
+func hostConfigPrivileges(hc *containertypes.HostConfig, parser volumemounts.Parser) []string {
+	var privileges []string
+	if hc.Privileged {
+		privileges = append(privileges, "privileged")
+	}
+	for _, b := range hc.Binds {
+		if mp, err := parser.ParseMountRaw(b, hc.VolumeDriver); err != nil || mp.Type != mounttypes.TypeVolume {
+			privileges = append(privileges, "bind "+b)
+		}
+	}
+	for _, m := range hc.Mounts {
+		switch {
+		case m.Type == mounttypes.TypeBind || m.Type == mounttypes.TypeNamedPipe:
+			privileges = append(privileges, string(m.Type)+" mount "+m.Source)
+		case m.VolumeOptions != nil && m.VolumeOptions.DriverConfig != nil && len(m.VolumeOptions.DriverConfig.Options) > 0:
+			// the options of the local driver can bind mount host paths
+			privileges = append(privileges, "volume options for "+m.Target)
+		}
+	}
+	if len(hc.CapAdd) > 0 {
+		privileges = append(privileges, "cap-add "+strings.Join(hc.CapAdd, ","))
+	}
+	if len(hc.Devices) > 0 || len(hc.DeviceCgroupRules) > 0 {
+		privileges = append(privileges, "devices")
+	}
+	if len(hc.SecurityOpt) > 0 {
+		privileges = append(privileges, "security-opt "+strings.Join(hc.SecurityOpt, ","))
+	}
+	if hc.NetworkMode.IsHost() {
+		privileges = append(privileges, "host network")
+	}
+	if hc.PidMode.IsHost() {
+		privileges = append(privileges, "host pid namespace")
+	}
+	if hc.IpcMode.IsHost() {
+		privileges = append(privileges, "host ipc namespace")
+	}
+	if hc.UTSMode.IsHost() {
+		privileges = append(privileges, "host uts namespace")
+	}
+	if hc.UsernsMode.IsHost() {
+		privileges = append(privileges, "host user namespace")
+	}
+	return privileges
+}
+
+// restoreCheckpointMounts adds the named volumes the exported container had
+// mounted, including the anonymous volumes, to the mounts of the host
+// configuration, so that the imported container mounts volumes with the
+// same names. The content of the volumes isn't part of the bundle. The bind
+// mounts are only taken from the host configuration.
+func restoreCheckpointMounts(hc *containertypes.HostConfig, mounts []types.MountPoint, parser volumemounts.Parser) {
+	mounted := make(map[string]bool)
+	for _, b := range hc.Binds {
+		if mp, err := parser.ParseMountRaw(b, hc.VolumeDriver); err == nil {
+			mounted[mp.Destination] = true
+		}
+	}
+	for _, m := range hc.Mounts {
+		mounted[m.Target] = true
+	}
+
+	for _, m := range mounts {
+		if m.Type != mounttypes.TypeVolume || m.Name == "" || mounted[m.Destination] {
+			continue
+		}
+		hc.Mounts = append(hc.Mounts, mounttypes.Mount{
+			Type:     mounttypes.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+			VolumeOptions: &mounttypes.VolumeOptions{
+				DriverConfig: &mounttypes.Driver{Name: m.Driver},
+			},
+		})
+		mounted[m.Destination] = true
+	}
+}
+
+// pullCheckpointImage pulls the image of the container of a checkpoint bundle
+// if it's missing. The image must be the one the container was created from.
+func (daemon *Daemon) pullCheckpointImage(ctx context.Context, manifest *checkpointManifest, config *backend.CheckpointImportConfig) error {
+	img, err := daemon.imageService.GetImage(manifest.Config.Image)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+		ref, parseErr := reference.ParseNormalizedNamed(manifest.Config.Image)
+		if parseErr != nil {
+			// The container was created from an image ID.
+			return err
+		}
+		ref = reference.TagNameOnly(ref)
+		if err := daemon.imageService.PullImage(ctx, ref.String(), "", "", config.MetaHeaders, config.AuthConfig, ioutil.Discard); err != nil {
+			return errors.Wrapf(err, "failed to pull image %s", ref)
+		}
+		if img, err = daemon.imageService.GetImage(manifest.Config.Image); err != nil {
+			return err
+		}
+	}
+	if img.ID().String() != manifest.ImageID {
+		return errdefs.Conflict(errors.Errorf("image %s is %s, but the checkpoint was created with image %s", manifest.Config.Image, img.ID(), manifest.ImageID))
+	}
+	return nil
+}
+
+// importCheckpoint applies the diff of the read-write layer of a checkpoint
+// bundle extracted in dir to the container id, and moves the checkpoint to
+// the checkpoints of the container.
+func (daemon *Daemon) importCheckpoint(id string, manifest *checkpointManifest, dir string) error {
+	ctr, err := daemon.GetContainer(id)
+	if err != nil {
+		return err
+	}
+
+	if err := daemon.Mount(ctr); err != nil {
+		return err
+	}
+	defer daemon.Unmount(ctr)
+
+	rw, err := os.Open(filepath.Join(dir, "rw.tar"))
+	if err != nil {
+		return err
+	}
+	defer rw.Close()
+	options := &archive.TarOptions{
+		UIDMaps: daemon.idMappings.UIDs(),
+		GIDMaps: daemon.idMappings.GIDs(),
+	}
+	if _, err := chrootarchive.ApplyUncompressedLayer(ctr.BaseFS.Path(), rw, options); err != nil {
+		return errors.Wrap(err, "failed to apply changes to the filesystem of the container")
+	}
+
+	checkpointDir := filepath.Join(ctr.CheckpointDir(), manifest.CheckpointID)
+	if err := os.MkdirAll(ctr.CheckpointDir(), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(dir, "checkpoint"), checkpointDir); err != nil {
+		// The temporary directory may be on another filesystem.
+		if err := archive.NewDefaultArchiver().CopyWithTar(filepath.Join(dir, "checkpoint"), checkpointDir); err != nil {
+			return errors.Wrap(err, "failed to copy checkpoint")
+		}
+	}
+	return nil
+}
+
+// readCheckpointBundle reads a checkpoint bundle from in. The checkpoint is
+// extracted in dir/checkpoint, and the diff of the read-write layer is
+// written to dir/rw.tar.
+func readCheckpointBundle(in io.Reader, dir string) (*checkpointManifest, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return errdefs.InvalidParameter(errors.Errorf("invalid checkpoint bundle: "+format, args...))
+	}
+
+	checkpointDir := filepath.Join(dir, "checkpoint")
+	if err := os.Mkdir(checkpointDir, 0700); err != nil {
+		return nil, err
+	}
+	rwFile, err := os.Create(filepath.Join(dir, "rw.tar"))
+	if err != nil {
+		return nil, err
+	}
+	defer rwFile.Close()
+	rw := tar.NewWriter(rwFile)
+
+	var manifest *checkpointManifest
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+
+		switch {
+		case hdr.Name == checkpointBundleManifest:
+			manifest = &checkpointManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, invalid("%v", err)
+			}
+		case strings.HasPrefix(hdr.Name, checkpointBundleRW):
+			hdr.Name = strings.TrimPrefix(hdr.Name, checkpointBundleRW)
+			if hdr.Typeflag == tar.TypeLink {
+				if !strings.HasPrefix(hdr.Linkname, checkpointBundleRW) {
+					return nil, invalid("hard link %s points outside of the container", hdr.Name)
+				}
+				hdr.Linkname = strings.TrimPrefix(hdr.Linkname, checkpointBundleRW)
+			}
+			if err := rw.WriteHeader(hdr); err != nil {
+				return nil, err
+			}
+			if _, err := io.Copy(rw, tr); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(hdr.Name, checkpointBundleCheckpoint):
+			path := filepath.Join(checkpointDir, filepath.FromSlash(strings.TrimPrefix(hdr.Name, checkpointBundleCheckpoint)))
+			if path == checkpointDir {
+				continue
+			}
+			if !strings.HasPrefix(path, checkpointDir+string(os.PathSeparator)) {
+				return nil, invalid("%s points outside of the checkpoint", hdr.Name)
+			}
+			if err := extractCheckpointFile(path, hdr, tr); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, invalid("unexpected entry %s", hdr.Name)
+		}
+	}
+
+	switch {
+	case manifest == nil:
+		return nil, invalid("missing manifest")
+	case manifest.Version != checkpointBundleVersion:
+		return nil, invalid("unsupported version %d", manifest.Version)
+	case manifest.Config == nil || manifest.HostConfig == nil:
+		return nil, invalid("missing container configuration")
+	case !validCheckpointNamePattern.MatchString(manifest.CheckpointID):
+		return nil, invalid("invalid checkpoint ID (%s)", manifest.CheckpointID)
+	}
+	return manifest, rw.Close()
+}
+
+// extractCheckpointFile extracts a directory or regular file of a checkpoint
+// to path.
+func extractCheckpointFile(path string, hdr *tar.Header, r io.Reader) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(path, 0700)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(f, r)
+		return err
+	default:
+		return errdefs.InvalidParameter(errors.Errorf("invalid checkpoint bundle: unsupported type of checkpoint file %s", hdr.Name))
+	}
+}
@@ -0,0 +1,167 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/errdefs"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/skip"
+)
+
+func tarEntries(t *testing.T, entries map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, content := range entries {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	return buf
+}
+
+func TestCheckpointBundle(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "checkpoint-bundle")
+	assert.NilError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	checkpointDir := filepath.Join(tmpDir, "src")
+	for name, content := range map[string]string{
+		"inventory.img":   "inventory",
+		"pages/pages.img": "pages",
+	} {
+		p := filepath.Join(checkpointDir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NilError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+	manifest, err := json.Marshal(checkpointManifest{
+		Version:      checkpointBundleVersion,
+		CheckpointID: "cp1",
+		Name:         "web",
+		ImageID:      "sha256:abcd",
+		Config:       &containertypes.Config{Image: "busybox"},
+		HostConfig:   &containertypes.HostConfig{},
+	})
+	assert.NilError(t, err)
+	diff := tarEntries(t, map[string]string{"etc/hosts": "hosts", "tmp/.wh.removed": ""})
+
+	bundle := &bytes.Buffer{}
+	assert.NilError(t, writeCheckpointBundle(bundle, manifest, checkpointDir, diff, nil))
+
+	dst := filepath.Join(tmpDir, "dst")
+	assert.NilError(t, os.Mkdir(dst, 0700))
+	m, err := readCheckpointBundle(bundle, dst)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("cp1", m.CheckpointID))
+	assert.Check(t, is.Equal("busybox", m.Config.Image))
+
+	content, err := ioutil.ReadFile(filepath.Join(dst, "checkpoint", "pages", "pages.img"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("pages", string(content)))
+
+	rw, err := os.Open(filepath.Join(dst, "rw.tar"))
+	assert.NilError(t, err)
+	defer rw.Close()
+	var names []string
+	tr := tar.NewReader(rw)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.Check(t, is.Len(names, 2))
+	assert.Check(t, is.Contains(names, "etc/hosts"))
+	assert.Check(t, is.Contains(names, "tmp/.wh.removed"))
+}
+
+func TestCheckpointBundleInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		entries  map[string]string
+		expected string
+	}{
+		{
+			entries:  map[string]string{"checkpoint/../../escape": ""},
+			expected: "points outside of the checkpoint",
+		},
+		{
+			entries:  map[string]string{"other": ""},
+			expected: "unexpected entry other",
+		},
+		{
+			entries:  map[string]string{"checkpoint/inventory.img": ""},
+			expected: "missing manifest",
+		},
+		{
+			entries:  map[string]string{checkpointBundleManifest: `{"Version": 2}`},
+			expected: "unsupported version 2",
+		},
+		{
+			entries:  map[string]string{checkpointBundleManifest: `{"Version": 1, "CheckpointID": "../cp", "Config": {}, "HostConfig": {}}`},
+			expected: "invalid checkpoint ID",
+		},
+	} {
+		dir, err := ioutil.TempDir("", "checkpoint-bundle")
+		assert.NilError(t, err)
+		_, err = readCheckpointBundle(tarEntries(t, tc.entries), dir)
+		os.RemoveAll(dir)
+		assert.Check(t, is.ErrorContains(err, tc.expected))
+		assert.Check(t, errdefs.IsInvalidParameter(err))
+	}
+}
+
+func TestHostConfigPrivileges(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "paths are linux paths")
+	parser := volumemounts.NewParser(runtime.GOOS)
+
+	assert.Check(t, is.Len(hostConfigPrivileges(&containertypes.HostConfig{
+		Binds:  []string{"data:/data"},
+		Mounts: []mounttypes.Mount{{Type: mounttypes.TypeVolume, Source: "logs", Target: "/logs"}, {Type: mounttypes.TypeTmpfs, Target: "/tmp"}},
+	}, parser), 0))
+
+	privileges := hostConfigPrivileges(&containertypes.HostConfig{
+		Privileged:  true,
+		Binds:       []string{"/:/host"},
+		Mounts:      []mounttypes.Mount{{Type: mounttypes.TypeBind, Source: "/etc", Target: "/etc"}, {Type: mounttypes.TypeVolume, Target: "/root", VolumeOptions: &mounttypes.VolumeOptions{DriverConfig: &mounttypes.Driver{Options: map[string]string{"o": "bind", "device": "/"}}}}},
+		CapAdd:      []string{"SYS_ADMIN"},
+		NetworkMode: "host",
+		PidMode:     "host",
+	}, parser)
+	assert.Check(t, is.DeepEqual([]string{"privileged", "bind /:/host", "bind mount /etc", "volume options for /root", "cap-add SYS_ADMIN", "host network", "host pid namespace"}, privileges))
+}
+
+func TestRestoreCheckpointMounts(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "paths are linux paths")
+	hc := &containertypes.HostConfig{
+		Binds:  []string{"/srv/config:/config:ro", "data:/data"},
+		Mounts: []mounttypes.Mount{{Type: mounttypes.TypeTmpfs, Target: "/cache"}},
+	}
+	restoreCheckpointMounts(hc, []types.MountPoint{
+		{Type: mounttypes.TypeBind, Source: "/srv/config", Destination: "/config"},
+		{Type: mounttypes.TypeVolume, Name: "data", Driver: "local", Destination: "/data", RW: true},
+		{Type: mounttypes.TypeVolume, Name: "0123abcd", Driver: "local", Destination: "/var/lib/app", RW: true},
+		{Type: mounttypes.TypeVolume, Name: "logs", Driver: "other", Destination: "/logs"},
+		{Type: mounttypes.TypeBind, Source: "/etc", Destination: "/host-etc"},
+	}, volumemounts.NewParser(runtime.GOOS))
+
+	assert.Check(t, is.DeepEqual([]mounttypes.Mount{
+		{Type: mounttypes.TypeTmpfs, Target: "/cache"},
+		{Type: mounttypes.TypeVolume, Source: "0123abcd", Target: "/var/lib/app", VolumeOptions: &mounttypes.VolumeOptions{DriverConfig: &mounttypes.Driver{Name: "local"}}},
+		{Type: mounttypes.TypeVolume, Source: "logs", Target: "/logs", ReadOnly: true, VolumeOptions: &mounttypes.VolumeOptions{DriverConfig: &mounttypes.Driver{Name: "other"}}},
+	}, hc.Mounts))
+}
@@ -8,13 +8,18 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	daemondiscovery "github.com/docker/docker/daemon/discovery"
+	"github.com/docker/docker/distribution/policy"
 	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/audit"
 	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/discovery"
 	"github.com/docker/docker/registry"
@@ -37,6 +42,9 @@ const (
 	StockRuntimeName = "runc"
 	// DefaultShmSize is the default value for container's shm size
 	DefaultShmSize = int64(67108864)
+	// DefaultStatsHistoryMaxMemory is the default maximum memory used by the
+	// stats history of containers.
+	DefaultStatsHistoryMaxMemory = int64(64 * 1024 * 1024)
 	// DefaultNetworkMtu is the default value for network MTU
 	DefaultNetworkMtu = 1500
 	// DisableNetworkBridge is the default value of the option to disable network bridge
@@ -50,10 +58,15 @@ const (
 // Use this to differentiate these options
 // with others like the ones in CommonTLSOptions.
 var flatOptions = map[string]bool{
-	"cluster-store-opts": true,
-	"log-opts":           true,
-	"runtimes":           true,
-	"default-ulimits":    true,
+	"cluster-store-opts":   true,
+	"log-opts":             true,
+	"runtimes":             true,
+	"default-ulimits":      true,
+	"image-policy":         true,
+	"authorization-policy": true,
+	"csi-plugins":          true,
+	"audit-log-opts":       true,
+	"listener-scopes":      true,
 }
 
 // LogConfig represents the default log configuration.
@@ -182,6 +195,10 @@ type CommonConfig struct {
 
 	Experimental bool `json:"experimental"` // Experimental indicates whether experimental features should be exposed or not
 
+	// Rootless runs the daemon as an unprivileged user, inside a user
+	// namespace set up by RootlessKit.
+	Rootless bool `json:"rootless,omitempty"`
+
 	// Exposed node Generic Resources
 	// e.g: ["orange=red", "orange=green", "orange=blue", "apple=3"]
 	NodeGenericResources []string `json:"node-generic-resources,omitempty"`
@@ -191,6 +208,64 @@ type CommonConfig struct {
 	// ContainerAddr is the address used to connect to containerd if we're
 	// not starting it ourselves
 	ContainerdAddr string `json:"containerd,omitempty"`
+
+	// PushCompression is the compression used for the image layers that
+	// are pushed to a registry: "gzip" (the default) or "zstd".
+	PushCompression string `json:"push-compression,omitempty"`
+
+	// PushCompressionLevel is the compression level used for the image
+	// layers that are pushed to a registry. 0 selects the default level.
+	PushCompressionLevel int `json:"push-compression-level,omitempty"`
+
+	// AuthorizationPolicy is the built-in authorization policy, evaluated
+	// before the authorization plugins.
+	AuthorizationPolicy *authorization.PolicyConfig `json:"authorization-policy,omitempty"`
+
+	// ListenerScopes restricts the routes served on the API listeners,
+	// keyed by their address as passed to --host, such as
+	// "unix:///run/docker-ro.sock".
+	ListenerScopes map[string]authorization.Scope `json:"listener-scopes,omitempty"`
+
+	// AuditLog is the destination of the audit log of the API requests:
+	// "file" or "syslog". The audit log is disabled if it is empty.
+	AuditLog string `json:"audit-log,omitempty"`
+
+	// AuditLogOpts are the options of the audit log destination.
+	AuditLogOpts map[string]string `json:"audit-log-opts,omitempty"`
+
+	// AuditLogLevel is the level of detail of the audit log: "metadata"
+	// (the default) or "request", which includes the request bodies.
+	AuditLogLevel string `json:"audit-log-level,omitempty"`
+
+	// ImagePolicy is the image signature policy enforced when pulling
+	// images and when creating containers from images of unknown origin.
+	ImagePolicy *policy.Config `json:"image-policy,omitempty"`
+
+	// ImageGCHighThreshold is the disk usage, in percent of the filesystem
+	// holding the storage driver's data, above which unused images are
+	// garbage collected. 0 disables image garbage collection.
+	ImageGCHighThreshold int `json:"image-gc-high-threshold,omitempty"`
+
+	// ImageGCLowThreshold is the disk usage, in percent, down to which
+	// image garbage collection frees space.
+	ImageGCLowThreshold int `json:"image-gc-low-threshold,omitempty"`
+
+	// ImageGCMinAge is the minimum time an image must have been unused
+	// before it is garbage collected, for example "24h".
+	ImageGCMinAge string `json:"image-gc-min-age,omitempty"`
+
+	// CSIPlugins maps the names of volume drivers to the unix sockets of
+	// the Container Storage Interface (CSI) plugins implementing them.
+	CSIPlugins map[string]string `json:"csi-plugins,omitempty"`
+
+	// StatsHistory enables keeping a history of the stats of containers in
+	// memory, downsampled to 10s for the last hour and 1m for the last day.
+	StatsHistory bool `json:"stats-history,omitempty"`
+
+	// StatsHistoryMaxMemory is the maximum memory used by the stats history.
+	// The history of the least recently updated containers is dropped to
+	// stay within this limit.
+	StatsHistoryMaxMemory opts.MemBytes `json:"stats-history-max-memory,omitempty"`
 }
 
 // IsValueSet returns true if a configuration value
@@ -203,11 +278,40 @@ func (conf *Config) IsValueSet(name string) bool {
 	return ok
 }
 
+// GetPushCompression returns the compression used for the image layers that
+// are pushed to a registry. The configuration must have been validated.
+func (conf *Config) GetPushCompression() archive.Compression {
+	compression, _ := parsePushCompression(conf.PushCompression)
+	return compression
+}
+
+// GetImageGCMinAge returns the minimum time an image must have been unused
+// before it is garbage collected. The configuration must have been
+// validated.
+func (conf *Config) GetImageGCMinAge() time.Duration {
+	minAge, _ := parseImageGCMinAge(conf.ImageGCMinAge)
+	return minAge
+}
+
+func parseImageGCMinAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	minAge, err := time.ParseDuration(value)
+	if err != nil || minAge < 0 {
+		return 0, fmt.Errorf("invalid image GC minimum age: %s", value)
+	}
+	return minAge, nil
+}
+
 // New returns a new fully initialized Config struct
 func New() *Config {
 	config := Config{}
 	config.LogConfig.Config = make(map[string]string)
 	config.ClusterOpts = make(map[string]string)
+	config.CSIPlugins = make(map[string]string)
+	config.AuditLogOpts = make(map[string]string)
+	config.ListenerScopes = make(map[string]authorization.Scope)
 
 	if runtime.GOOS != "linux" {
 		config.V2Only = true
@@ -266,6 +370,18 @@ func Reload(configFile string, flags *pflag.FlagSet, reload func(*Config)) error
 		newConfig = New()
 	}
 
+	// The configuration is only read from the file: an authorization
+	// policy set by a flag is read again from the file the flag points to.
+	if newConfig.AuthorizationPolicy == nil {
+		if f := flags.Lookup("authorization-policy"); f != nil {
+			if o, ok := f.Value.(*AuthorizationPolicyOpt); ok {
+				if newConfig.AuthorizationPolicy, err = o.reload(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	if err := Validate(newConfig); err != nil {
 		return fmt.Errorf("file configuration validation failed (%v)", err)
 	}
@@ -514,6 +630,58 @@ func Validate(config *Config) error {
 		return err
 	}
 
+	compression, err := parsePushCompression(config.PushCompression)
+	if err != nil {
+		return err
+	}
+	if compression == archive.Zstd {
+		// layers are compressed with zstd by the zstd binary
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return fmt.Errorf("push compression zstd requires the zstd binary: %v", err)
+		}
+	}
+	if config.PushCompressionLevel < 0 || (config.PushCompression == "zstd" && config.PushCompressionLevel > 19) ||
+		(config.PushCompression != "zstd" && config.PushCompressionLevel > 9) {
+		return fmt.Errorf("invalid push compression level: %d", config.PushCompressionLevel)
+	}
+
+	if config.ImagePolicy != nil {
+		if _, err := policy.New(*config.ImagePolicy); err != nil {
+			return err
+		}
+	}
+
+	if config.AuthorizationPolicy != nil {
+		if _, err := authorization.NewPolicy(*config.AuthorizationPolicy); err != nil {
+			return err
+		}
+	}
+
+	for host, scope := range config.ListenerScopes {
+		if err := scope.Validate(); err != nil {
+			return fmt.Errorf("invalid scope for listener %s: %v", host, err)
+		}
+	}
+
+	switch config.AuditLog {
+	case "", "file", "syslog":
+	default:
+		return fmt.Errorf("invalid audit log: %s: must be file or syslog", config.AuditLog)
+	}
+	if err := audit.ValidateLevel(config.AuditLogLevel); err != nil {
+		return err
+	}
+
+	if config.ImageGCHighThreshold < 0 || config.ImageGCHighThreshold > 100 {
+		return fmt.Errorf("invalid image GC high threshold: %d", config.ImageGCHighThreshold)
+	}
+	if config.ImageGCHighThreshold > 0 && (config.ImageGCLowThreshold <= 0 || config.ImageGCLowThreshold >= config.ImageGCHighThreshold) {
+		return fmt.Errorf("invalid image GC low threshold: %d: must be between 0 and the high threshold (%d)", config.ImageGCLowThreshold, config.ImageGCHighThreshold)
+	}
+	if _, err := parseImageGCMinAge(config.ImageGCMinAge); err != nil {
+		return err
+	}
+
 	if defaultRuntime := config.GetDefaultRuntimeName(); defaultRuntime != "" && defaultRuntime != StockRuntimeName {
 		runtimes := config.GetAllRuntimes()
 		if _, ok := runtimes[defaultRuntime]; !ok {
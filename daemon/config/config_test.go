@@ -9,6 +9,7 @@ import (
 	"github.com/docker/docker/daemon/discovery"
 	"github.com/docker/docker/internal/testutil"
 	"github.com/docker/docker/opts"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/gotestyourself/gotestyourself/assert"
 	is "github.com/gotestyourself/gotestyourself/assert/cmp"
 	"github.com/gotestyourself/gotestyourself/fs"
@@ -276,6 +277,38 @@ func TestValidateConfigurationErrors(t *testing.T) {
 				},
 			},
 		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCHighThreshold: 101,
+					ImageGCLowThreshold:  80,
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCHighThreshold: 80,
+					ImageGCLowThreshold:  90,
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCMinAge: "1 day",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					AuthorizationPolicy: &authorization.PolicyConfig{
+						DefaultAction: "block",
+					},
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		err := Validate(tc.config)
@@ -347,6 +380,15 @@ func TestValidateConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCHighThreshold: 90,
+					ImageGCLowThreshold:  80,
+					ImageGCMinAge:        "24h",
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		err := Validate(tc.config)
@@ -489,3 +531,112 @@ func TestReloadWithDuplicateLabels(t *testing.T) {
 	err := Reload(configFile, flags, func(c *Config) {})
 	assert.Check(t, err)
 }
+
+func TestDaemonConfigurationAuthorizationPolicy(t *testing.T) {
+	configFile := fs.NewFile(t, "docker-config", fs.WithContent(`{
+		"authorization-policy": {
+			"default-action": "allow",
+			"rules": [{"action": "deny", "reason": "no privileged containers", "privileged": true}]
+		}
+	}`))
+	defer configFile.Remove()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var policy *authorization.PolicyConfig
+	flags.Var(NewAuthorizationPolicyOpt(&policy), "authorization-policy", "")
+
+	cc, err := MergeDaemonConfigurations(&Config{}, flags, configFile.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, cc.AuthorizationPolicy != nil)
+	assert.Check(t, is.Equal("allow", cc.AuthorizationPolicy.DefaultAction))
+	assert.Assert(t, is.Len(cc.AuthorizationPolicy.Rules, 1))
+	assert.Check(t, is.Equal("no privileged containers", cc.AuthorizationPolicy.Rules[0].Reason))
+}
+
+func TestReloadAuthorizationPolicyFlag(t *testing.T) {
+	policyFile := fs.NewFile(t, "policy", fs.WithContent(`{"rules": [{"action": "deny", "privileged": true}]}`))
+	defer policyFile.Remove()
+	configFile := fs.NewFile(t, "docker-config", fs.WithContent(`{"labels": ["foo=bar"]}`))
+	defer configFile.Remove()
+
+	var lbls []string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("config-file", configFile.Path(), "")
+	flags.StringSlice("labels", lbls, "")
+	var policy *authorization.PolicyConfig
+	flags.Var(NewAuthorizationPolicyOpt(&policy), "authorization-policy", "")
+	assert.NilError(t, flags.Set("authorization-policy", policyFile.Path()))
+
+	var reloaded *Config
+	reload := func(c *Config) { reloaded = c }
+	assert.NilError(t, Reload(configFile.Path(), flags, reload))
+	assert.Assert(t, reloaded.AuthorizationPolicy != nil)
+	assert.Check(t, is.Len(reloaded.AuthorizationPolicy.Rules, 1))
+
+	// the changes made to the policy file are applied on reload
+	assert.NilError(t, ioutil.WriteFile(policyFile.Path(), []byte(`{"rules": [{"action": "deny", "privileged": true}, {"action": "deny", "network-modes": ["host"]}]}`), 0644))
+	assert.NilError(t, Reload(configFile.Path(), flags, reload))
+	assert.Assert(t, reloaded.AuthorizationPolicy != nil)
+	assert.Check(t, is.Len(reloaded.AuthorizationPolicy.Rules, 2))
+
+	// without the flag, the policy is only set by the configuration file
+	flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("config-file", configFile.Path(), "")
+	flags.StringSlice("labels", lbls, "")
+	flags.Var(NewAuthorizationPolicyOpt(&policy), "authorization-policy", "")
+	assert.NilError(t, Reload(configFile.Path(), flags, reload))
+	assert.Check(t, reloaded.AuthorizationPolicy == nil)
+}
+
+func TestDaemonConfigurationListenerScopes(t *testing.T) {
+	configFile := fs.NewFile(t, "docker-config", fs.WithContent(`{
+		"listener-scopes": {
+			"unix:///run/docker-ro.sock": {"read-only": true},
+			"tcp://127.0.0.1:2375": {"routes": ["GET /containers/json", "/events"]}
+		}
+	}`))
+	defer configFile.Remove()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var scopes map[string]authorization.Scope
+	flags.Var(NewListenerScopeOpt(&scopes), "listener-scope", "")
+
+	cc, err := MergeDaemonConfigurations(&Config{}, flags, configFile.Path())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]authorization.Scope{
+		"unix:///run/docker-ro.sock": {ReadOnly: true},
+		"tcp://127.0.0.1:2375":       {Routes: []string{"GET /containers/json", "/events"}},
+	}, cc.ListenerScopes))
+}
+
+func TestListenerScopeOpt(t *testing.T) {
+	var scopes map[string]authorization.Scope
+	o := NewListenerScopeOpt(&scopes)
+	assert.Check(t, o.Set("unix:///run/docker-ro.sock=read-only"))
+	assert.Check(t, o.Set("unix:///run/docker-mon.sock=GET /containers/json, /events"))
+	assert.Check(t, is.DeepEqual(map[string]authorization.Scope{
+		"unix:///run/docker-ro.sock":  {ReadOnly: true},
+		"unix:///run/docker-mon.sock": {Routes: []string{"GET /containers/json", "/events"}},
+	}, scopes))
+
+	assert.Check(t, is.ErrorContains(o.Set("unix:///run/docker-ro.sock=/info"), "already defined"))
+	assert.Check(t, is.ErrorContains(o.Set("unix:///run/docker.sock"), "invalid listener scope"))
+	assert.Check(t, is.ErrorContains(o.Set("unix:///run/docker.sock=GET info"), "must be an absolute path"))
+}
+
+func TestValidatePushCompression(t *testing.T) {
+	config := &Config{CommonConfig: CommonConfig{PushCompression: "xz"}}
+	assert.Check(t, is.Error(Validate(config), `invalid push compression "xz": must be gzip or zstd`))
+
+	config = &Config{CommonConfig: CommonConfig{PushCompression: "gzip", PushCompressionLevel: 12}}
+	assert.Check(t, is.Error(Validate(config), "invalid push compression level: 12"))
+
+	// zstd is rejected when the zstd binary is not installed
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	dir := fs.NewDir(t, "push-compression")
+	defer dir.Remove()
+	os.Setenv("PATH", dir.Path())
+
+	config = &Config{CommonConfig: CommonConfig{PushCompression: "zstd"}}
+	assert.Check(t, is.ErrorContains(Validate(config), "push compression zstd requires the zstd binary"))
+}
@@ -4,6 +4,7 @@ package config // import "github.com/docker/docker/daemon/config"
 
 import (
 	"fmt"
+	"strconv"
 
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/opts"
@@ -38,6 +39,20 @@ type Config struct {
 	ShmSize              opts.MemBytes            `json:"default-shm-size,omitempty"`
 	NoNewPrivileges      bool                     `json:"no-new-privileges,omitempty"`
 	IpcMode              string                   `json:"default-ipc-mode,omitempty"`
+	CgroupNamespaceMode  string                   `json:"default-cgroupns-mode,omitempty"`
+
+	// MemoryEventThreshold is the memory usage of a container, in percent
+	// of its memory limit, above which a memory_threshold event is logged.
+	MemoryEventThreshold int `json:"memory-event-threshold,omitempty"`
+
+	// PressureEventThresholds maps the resources "cpu", "memory" and "io"
+	// to the share of time, in percent over a 10s window, some tasks of a
+	// container may be stalled on them before a pressure event is logged.
+	PressureEventThresholds map[string]string `json:"pressure-event-thresholds,omitempty"`
+
+	// ThrottledEventThreshold is the ratio of throttled CPU periods of a
+	// container, in percent, above which a throttled event is logged.
+	ThrottledEventThreshold int `json:"throttled-event-threshold,omitempty"`
 }
 
 // BridgeConfig stores all the bridge driver specific
@@ -82,7 +97,61 @@ func verifyDefaultIpcMode(mode string) error {
 	return nil
 }
 
+func verifyDefaultCgroupNsMode(mode string) error {
+	cm := containertypes.CgroupnsMode(mode)
+	if !cm.Valid() {
+		return fmt.Errorf("Default cgroup namespace mode (%v) is invalid. Use \"host\" or \"private\".", cm)
+	}
+	return nil
+}
+
 // ValidatePlatformConfig checks if any platform-specific configuration settings are invalid.
 func (conf *Config) ValidatePlatformConfig() error {
-	return verifyDefaultIpcMode(conf.IpcMode)
+	if err := verifyDefaultIpcMode(conf.IpcMode); err != nil {
+		return err
+	}
+	if err := verifyDefaultCgroupNsMode(conf.CgroupNamespaceMode); err != nil {
+		return err
+	}
+	return verifyResourceEventThresholds(conf)
+}
+
+func verifyPercent(name string, v float64) error {
+	if v < 0 || v > 100 {
+		return fmt.Errorf("%s (%v) must be a percentage between 0 and 100", name, v)
+	}
+	return nil
+}
+
+func verifyResourceEventThresholds(conf *Config) error {
+	if err := verifyPercent("memory-event-threshold", float64(conf.MemoryEventThreshold)); err != nil {
+		return err
+	}
+	if err := verifyPercent("throttled-event-threshold", float64(conf.ThrottledEventThreshold)); err != nil {
+		return err
+	}
+	_, err := conf.PressureThresholds()
+	return err
+}
+
+// PressureThresholds returns the parsed pressure-event-thresholds, by
+// resource.
+func (conf *Config) PressureThresholds() (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for resource, value := range conf.PressureEventThresholds {
+		switch resource {
+		case "cpu", "memory", "io":
+		default:
+			return nil, fmt.Errorf("invalid pressure-event-thresholds resource %q: must be one of \"cpu\", \"memory\" or \"io\"", resource)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pressure-event-thresholds value %q for %s", value, resource)
+		}
+		if err := verifyPercent("pressure-event-thresholds "+resource, v); err != nil {
+			return nil, err
+		}
+		thresholds[resource] = v
+	}
+	return thresholds, nil
 }
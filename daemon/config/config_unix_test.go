@@ -132,3 +132,25 @@ func TestDaemonConfigurationMergeShmSize(t *testing.T) {
 	expectedValue := 1 * 1024 * 1024 * 1024
 	assert.Check(t, is.Equal(int64(expectedValue), cc.ShmSize.Value()))
 }
+
+func TestValidateResourceEventThresholds(t *testing.T) {
+	c := &Config{
+		MemoryEventThreshold:    90,
+		PressureEventThresholds: map[string]string{"memory": "10", "io": "2.5"},
+		ThrottledEventThreshold: 50,
+	}
+	assert.NilError(t, c.ValidatePlatformConfig())
+	thresholds, err := c.PressureThresholds()
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]float64{"memory": 10, "io": 2.5}, thresholds))
+
+	c.PressureEventThresholds = map[string]string{"net": "10"}
+	assert.Check(t, is.ErrorContains(c.ValidatePlatformConfig(), "invalid pressure-event-thresholds resource"))
+
+	c.PressureEventThresholds = map[string]string{"cpu": "110"}
+	assert.Check(t, is.ErrorContains(c.ValidatePlatformConfig(), "must be a percentage"))
+
+	c.PressureEventThresholds = nil
+	c.MemoryEventThreshold = -1
+	assert.Check(t, is.ErrorContains(c.ValidatePlatformConfig(), "memory-event-threshold"))
+}
@@ -1,9 +1,18 @@
 package config // import "github.com/docker/docker/daemon/config"
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/daemon/cluster/convert"
+	"github.com/docker/docker/distribution/policy"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/swarmkit/api/genericresource"
+	"github.com/pkg/errors"
 )
 
 // ParseGenericResources parses and validates the specified string as a list of GenericResource
@@ -20,3 +29,176 @@ func ParseGenericResources(value []string) ([]swarm.GenericResource, error) {
 	obj := convert.GenericResourcesFromGRPC(resources)
 	return obj, nil
 }
+
+// parsePushCompression parses the compression used for pushed layers. An
+// empty value selects gzip.
+func parsePushCompression(value string) (archive.Compression, error) {
+	switch value {
+	case "", "gzip":
+		return archive.Gzip, nil
+	case "zstd":
+		return archive.Zstd, nil
+	default:
+		return archive.Uncompressed, errors.Errorf("invalid push compression %q: must be gzip or zstd", value)
+	}
+}
+
+// ImagePolicyOpt is a flag value that loads the image signature policy from
+// a JSON file. The file uses the same format as the "image-policy" key of
+// the daemon configuration file.
+type ImagePolicyOpt struct {
+	path  string
+	value **policy.Config
+}
+
+// NewImagePolicyOpt creates a new ImagePolicyOpt storing the policy in ref.
+func NewImagePolicyOpt(ref **policy.Config) *ImagePolicyOpt {
+	return &ImagePolicyOpt{value: ref}
+}
+
+// Set reads and decodes the policy file at path.
+func (o *ImagePolicyOpt) Set(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var c policy.Config
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return errors.Wrapf(err, "invalid image policy file %s", path)
+	}
+	o.path = path
+	*o.value = &c
+	return nil
+}
+
+// String returns the path of the policy file.
+func (o *ImagePolicyOpt) String() string {
+	return o.path
+}
+
+// Type returns the type of the option
+func (o *ImagePolicyOpt) Type() string {
+	return "path"
+}
+
+// AuthorizationPolicyOpt is a flag value that loads the built-in
+// authorization policy from a JSON file. The file uses the same format as
+// the "authorization-policy" key of the daemon configuration file.
+type AuthorizationPolicyOpt struct {
+	path  string
+	value **authorization.PolicyConfig
+}
+
+// NewAuthorizationPolicyOpt creates a new AuthorizationPolicyOpt storing the
+// policy in ref.
+func NewAuthorizationPolicyOpt(ref **authorization.PolicyConfig) *AuthorizationPolicyOpt {
+	return &AuthorizationPolicyOpt{value: ref}
+}
+
+// Set reads and decodes the policy file at path.
+func (o *AuthorizationPolicyOpt) Set(path string) error {
+	c, err := loadAuthorizationPolicy(path)
+	if err != nil {
+		return err
+	}
+	o.path = path
+	*o.value = c
+	return nil
+}
+
+// reload reads and decodes the policy file again, so that the changes made
+// to it since the flag was set are applied. It returns nil if the flag is
+// not set.
+func (o *AuthorizationPolicyOpt) reload() (*authorization.PolicyConfig, error) {
+	if o.path == "" {
+		return nil, nil
+	}
+	return loadAuthorizationPolicy(o.path)
+}
+
+func loadAuthorizationPolicy(path string) (*authorization.PolicyConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c authorization.PolicyConfig
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return nil, errors.Wrapf(err, "invalid authorization policy file %s", path)
+	}
+	return &c, nil
+}
+
+// String returns the path of the policy file.
+func (o *AuthorizationPolicyOpt) String() string {
+	return o.path
+}
+
+// Type returns the type of the option
+func (o *AuthorizationPolicyOpt) Type() string {
+	return "path"
+}
+
+// ListenerScopeOpt is a flag value that restricts the routes served on an
+// API listener. Its values are in the form host=read-only, or
+// host=route[,route...] for an allowlist of routes.
+type ListenerScopeOpt struct {
+	values *map[string]authorization.Scope
+}
+
+// NewListenerScopeOpt creates a new ListenerScopeOpt storing the scopes in
+// ref.
+func NewListenerScopeOpt(ref *map[string]authorization.Scope) *ListenerScopeOpt {
+	if *ref == nil {
+		*ref = make(map[string]authorization.Scope)
+	}
+	return &ListenerScopeOpt{values: ref}
+}
+
+// Name returns the name of the option in the configuration.
+func (o *ListenerScopeOpt) Name() string {
+	return "listener-scopes"
+}
+
+// Set parses and adds the scope of a listener.
+func (o *ListenerScopeOpt) Set(val string) error {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("invalid listener scope: %s: must be host=read-only or host=route[,route...]", val)
+	}
+	host := strings.TrimSpace(parts[0])
+	if _, ok := (*o.values)[host]; ok {
+		return fmt.Errorf("scope of listener %s was already defined", host)
+	}
+
+	var scope authorization.Scope
+	for _, r := range strings.Split(parts[1], ",") {
+		if r = strings.TrimSpace(r); r == "read-only" {
+			scope.ReadOnly = true
+		} else if r != "" {
+			scope.Routes = append(scope.Routes, r)
+		}
+	}
+	if err := scope.Validate(); err != nil {
+		return fmt.Errorf("invalid scope for listener %s: %v", host, err)
+	}
+	(*o.values)[host] = scope
+	return nil
+}
+
+// String returns the scopes of the listeners.
+func (o *ListenerScopeOpt) String() string {
+	var out []string
+	for host, scope := range *o.values {
+		out = append(out, fmt.Sprintf("%s=%s", host, scope.String()))
+	}
+	return fmt.Sprintf("%v", out)
+}
+
+// Type returns the type of the option
+func (o *ListenerScopeOpt) Type() string {
+	return "scope"
+}
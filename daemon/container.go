@@ -339,6 +339,14 @@ func (daemon *Daemon) verifyContainerSettings(platform string, hostConfig *conta
 		return nil, errors.Errorf("invalid restart policy '%s'", p.Name)
 	}
 
+	if err := validateLifecycleHooks(hostConfig.Lifecycle); err != nil {
+		return nil, err
+	}
+
+	if err := validateDependencies(hostConfig.DependsOn); err != nil {
+		return nil, err
+	}
+
 	if !hostConfig.Isolation.IsValid() {
 		return nil, errors.Errorf("invalid isolation '%s' on %s", hostConfig.Isolation, runtime.GOOS)
 	}
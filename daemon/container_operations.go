@@ -345,6 +345,66 @@ func (daemon *Daemon) updateNetwork(container *container.Container) error {
 	return nil
 }
 
+// updatePortBindings programs the port bindings of the running container on
+// its sandbox, after they were updated. libnetwork only applies the port
+// bindings of a sandbox when its endpoints join it, so all the endpoints
+// leave and rejoin the sandbox: the container is briefly disconnected from
+// all its networks. If the new port bindings can't be programmed, the error
+// of the endpoint which failed to rejoin is returned, and the previous port
+// bindings are restored. Callers must hold a lock on the container.
+func (daemon *Daemon) updatePortBindings(c *container.Container, previous nat.PortMap, previousExposed nat.PortSet) error {
+	start := time.Now()
+	sid := c.NetworkSettings.SandboxID
+	sb, err := daemon.netController.SandboxByID(sid)
+	if err != nil {
+		return fmt.Errorf("error locating sandbox id %s: %v", sid, err)
+	}
+	endpoints := sb.Endpoints()
+
+	refresh := func() error {
+		options, err := daemon.buildSandboxOptions(c)
+		if err != nil {
+			return err
+		}
+		if err := sb.Refresh(options...); err != nil {
+			return fmt.Errorf("failure in refresh sandbox %s: %v", sid, err)
+		}
+		// Refresh only logs the errors of the endpoints which fail to
+		// rejoin the sandbox, for instance when a host port is already
+		// allocated, and forgets them: join them again to get the error.
+		joined := make(map[string]bool)
+		for _, ep := range sb.Endpoints() {
+			joined[ep.ID()] = true
+		}
+		var joinErr error
+		for _, ep := range endpoints {
+			if joined[ep.ID()] {
+				continue
+			}
+			if err := ep.Join(sb); err != nil && joinErr == nil {
+				joinErr = fmt.Errorf("failed to program port bindings on endpoint %s: %v", ep.Name(), err)
+			}
+		}
+		return joinErr
+	}
+
+	err = refresh()
+	if err != nil {
+		c.HostConfig.PortBindings = previous
+		c.Config.ExposedPorts = previousExposed
+		if rerr := refresh(); rerr != nil {
+			logrus.WithError(rerr).WithField("container", c.ID).Error("Failed to restore port bindings")
+		}
+	}
+	c.NetworkSettings.Ports = container.GetSandboxPortMapInfo(sb)
+	if err != nil {
+		return err
+	}
+
+	networkActions.WithValues("update").UpdateSince(start)
+	return nil
+}
+
 func (daemon *Daemon) findAndAttachNetwork(container *container.Container, idOrName string, epConfig *networktypes.EndpointSettings) (libnetwork.Network, *networktypes.NetworkingConfig, error) {
 	id := getNetworkID(idOrName, epConfig)
 
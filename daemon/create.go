@@ -19,6 +19,7 @@ import (
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/runconfig"
+	volumestore "github.com/docker/docker/volume/store"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/sirupsen/logrus"
 )
@@ -95,6 +96,9 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 		if err != nil {
 			return nil, err
 		}
+		if err := daemon.imageService.CheckImagePolicy(params.Config.Image, img); err != nil {
+			return nil, err
+		}
 		if img.OS != "" {
 			os = img.OS
 		} else {
@@ -133,6 +137,10 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 		}
 	}()
 
+	if err := daemon.checkDependencyCycle(container, params.HostConfig.DependsOn); err != nil {
+		return nil, err
+	}
+
 	if err := daemon.setSecurityOptions(container, params.HostConfig); err != nil {
 		return nil, err
 	}
@@ -273,6 +281,64 @@ func (daemon *Daemon) VolumeCreate(name, driverName string, opts, labels map[str
 	return apiV, nil
 }
 
+// VolumeClone creates a volume with the specified name, opts and labels,
+// holding a copy of the content of the source volume.
+// This is called directly from the Engine API
+func (daemon *Daemon) VolumeClone(source, name string, opts, labels map[string]string) (*types.Volume, error) {
+	if name == "" {
+		name = stringid.GenerateNonCryptoID()
+	}
+
+	v, err := daemon.volumes.Clone(source, name, opts, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	daemon.LogVolumeEvent(v.Name(), "clone", map[string]string{"driver": v.DriverName(), "source": source})
+	apiV := volumeToAPIType(v)
+	apiV.Mountpoint = v.Path()
+	return apiV, nil
+}
+
+// VolumeSnapshot creates a volume with the specified name and labels,
+// holding a point-in-time snapshot of the source volume.
+// This is called directly from the Engine API
+func (daemon *Daemon) VolumeSnapshot(source, name string, labels map[string]string) (*types.Volume, error) {
+	if name == "" {
+		name = stringid.GenerateNonCryptoID()
+	}
+
+	v, err := daemon.volumes.Snapshot(source, name, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	daemon.LogVolumeEvent(v.Name(), "snapshot", map[string]string{"driver": v.DriverName(), "source": source})
+	apiV := volumeToAPIType(v)
+	apiV.Mountpoint = v.Path()
+	apiV.Snapshot = daemon.volumeSnapshotInfo(v.Name())
+	return apiV, nil
+}
+
+// VolumeUpdate replaces the labels of the volume with the given name, if
+// labels is not nil, and changes the given options of the volume. Options
+// can only be changed if the driver of the volume supports it.
+func (daemon *Daemon) VolumeUpdate(name string, labels, opts map[string]string) (*types.Volume, error) {
+	v, err := daemon.volumes.Update(name, labels, opts)
+	if err != nil {
+		if volumestore.IsNotExist(err) {
+			return nil, volumeNotFound(name)
+		}
+		return nil, err
+	}
+
+	daemon.LogVolumeEvent(v.Name(), "update", map[string]string{"driver": v.DriverName()})
+	apiV := volumeToAPIType(v)
+	apiV.Mountpoint = v.Path()
+	apiV.Snapshot = daemon.volumeSnapshotInfo(v.Name())
+	return apiV, nil
+}
+
 func (daemon *Daemon) mergeAndVerifyConfig(config *containertypes.Config, img *image.Image) error {
 	if img != nil && img.Config != nil {
 		if err := merge(config, img.Config); err != nil {
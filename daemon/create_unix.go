@@ -60,9 +60,15 @@ func (daemon *Daemon) createContainerOSSpecificSettings(container *container.Con
 	return daemon.populateVolumes(container)
 }
 
-// populateVolumes copies data from the container's rootfs into the volume for non-binds.
+// populateVolumes copies data from the container's rootfs into the volume for non-binds,
+// and sets the ownership of empty volumes mounted with the chown option.
 // this is only called when the container is created.
 func (daemon *Daemon) populateVolumes(c *container.Container) error {
+	chownMounts, err := daemon.emptyChownMounts(c)
+	if err != nil {
+		return err
+	}
+
 	for _, mnt := range c.MountPoints {
 		if mnt.Volume == nil {
 			continue
@@ -77,5 +83,11 @@ func (daemon *Daemon) populateVolumes(c *container.Container) error {
 			return err
 		}
 	}
+
+	for _, mnt := range chownMounts {
+		if err := daemon.chownVolume(c, mnt); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -36,6 +36,7 @@ import (
 	_ "github.com/docker/docker/daemon/graphdriver/register"
 	"github.com/docker/docker/daemon/stats"
 	dmetadata "github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/policy"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
@@ -43,8 +44,8 @@ import (
 	"github.com/docker/docker/migrate/v1"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/locker"
+	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/plugingetter"
-	"github.com/docker/docker/pkg/sysinfo"
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/truncindex"
 	"github.com/docker/docker/plugin"
@@ -52,6 +53,7 @@ import (
 	refstore "github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/volume/csi"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/local"
 	"github.com/docker/docker/volume/store"
@@ -88,6 +90,7 @@ type Daemon struct {
 	root              string
 	seccompEnabled    bool
 	apparmorEnabled   bool
+	cgroupUnified     bool
 	shutdown          bool
 	idMappings        *idtools.IDMappings
 	// TODO: move graphDrivers field to an InfoService
@@ -115,6 +118,11 @@ type Daemon struct {
 
 	attachmentStore       network.AttachmentStore
 	attachableNetworkLock *locker.Locker
+
+	stopImageGC context.CancelFunc
+
+	resourceMonitorsMu sync.Mutex
+	resourceMonitors   map[string]chan struct{} // stop channels of the resource event monitors, by container ID
 }
 
 // StoreHosts stores the addresses the daemon is listening on
@@ -301,6 +309,10 @@ func (daemon *Daemon) restore() error {
 				}
 
 				c.ResetRestartManager(false)
+				if c.IsRunning() {
+					daemon.statsCollector.Track(c)
+					daemon.startResourceMonitor(c)
+				}
 				if !c.HostConfig.NetworkMode.IsContainer() && c.IsRunning() {
 					options, err := daemon.buildSandboxOptions(c)
 					if err != nil {
@@ -363,6 +375,15 @@ func (daemon *Daemon) restore() error {
 		}
 	}
 
+	// Start the containers after the containers they depend on. The
+	// containers in a dependency cycle are not started.
+	dependencies, cyclic := daemon.restartOrder(restartContainers)
+	for _, c := range cyclic {
+		logrus.Errorf("Failed to start container %s: dependency cycle", c.ID)
+		close(restartContainers[c])
+		delete(restartContainers, c)
+	}
+
 	group := sync.WaitGroup{}
 	for c, notifier := range restartContainers {
 		group.Add(1)
@@ -370,6 +391,10 @@ func (daemon *Daemon) restore() error {
 		go func(c *container.Container, chNotify chan struct{}) {
 			defer group.Done()
 
+			for _, dep := range dependencies[c] {
+				<-restartContainers[dep]
+			}
+
 			logrus.Debugf("Starting container %s", c.ID)
 
 			// ignore errors here as this is a best effort to wait for children to be
@@ -385,6 +410,12 @@ func (daemon *Daemon) restore() error {
 				}
 			}
 
+			if err := daemon.waitForDependencies(c, true); err != nil {
+				logrus.Errorf("Failed to start container %s: %s", c.ID, err)
+				close(chNotify)
+				return
+			}
+
 			// Make sure networks are available before starting
 			daemon.waitForNetworks(c)
 			if err := daemon.containerStart(c, "", "", true); err != nil {
@@ -662,10 +693,6 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 		logrus.Warnf("Failed to configure golang's threads limit: %v", err)
 	}
 
-	if err := ensureDefaultAppArmorProfile(); err != nil {
-		logrus.Errorf(err.Error())
-	}
-
 	daemonRepo := filepath.Join(config.Root, "containers")
 	if err := idtools.MkdirAllAndChown(daemonRepo, 0700, rootIDs); err != nil {
 		return nil, err
@@ -751,6 +778,7 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 			IDMappings:                idMappings,
 			PluginGetter:              d.PluginStore,
 			ExperimentalEnabled:       config.Experimental,
+			Rootless:                  config.Rootless,
 			OS:                        operatingSystem,
 		})
 		if err != nil {
@@ -837,13 +865,19 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 		return nil, err
 	}
 
-	sysInfo := sysinfo.New(false)
+	sysInfo := getSysInfo(config, false)
 	// Check if Devices cgroup is mounted, it is hard requirement for container security,
-	// on Linux.
-	if runtime.GOOS == "linux" && !sysInfo.CgroupDevicesEnabled {
+	// on Linux. Rootless containers are confined by the user namespace instead.
+	if runtime.GOOS == "linux" && !sysInfo.CgroupDevicesEnabled && !config.Rootless {
 		return nil, errors.New("Devices cgroup isn't mounted")
 	}
 
+	if sysInfo.AppArmor {
+		if err := ensureDefaultAppArmorProfile(); err != nil {
+			logrus.Errorf(err.Error())
+		}
+	}
+
 	d.ID = trustKey.PublicKey().KeyID()
 	d.repository = daemonRepo
 	d.containers = container.NewMemoryStore()
@@ -860,9 +894,17 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 	d.idMappings = idMappings
 	d.seccompEnabled = sysInfo.Seccomp
 	d.apparmorEnabled = sysInfo.AppArmor
+	d.cgroupUnified = sysInfo.CgroupUnified
 
 	d.linkIndex = newLinkIndex()
 
+	var imagePolicy *policy.Policy
+	if config.ImagePolicy != nil {
+		if imagePolicy, err = policy.New(*config.ImagePolicy); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: imageStore, distributionMetadataStore, and ReferenceStore are only
 	// used above to run migration. They could be initialized in ImageService
 	// if migration is called from daemon/images. layerStore might move as well.
@@ -870,10 +912,13 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 		ContainerStore:            d.containers,
 		DistributionMetadataStore: distributionMetadataStore,
 		EventsService:             d.EventsService,
+		ImagePolicy:               imagePolicy,
 		ImageStore:                imageStore,
 		LayerStores:               layerStores,
 		MaxConcurrentDownloads:    *config.MaxConcurrentDownloads,
 		MaxConcurrentUploads:      *config.MaxConcurrentUploads,
+		PushCompression:           config.GetPushCompression(),
+		PushCompressionLevel:      config.PushCompressionLevel,
 		ReferenceStore:            rs,
 		RegistryService:           registryService,
 		TrustKey:                  trustKey,
@@ -976,6 +1021,9 @@ func (daemon *Daemon) ShutdownTimeout() int {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	if daemon.stopImageGC != nil {
+		daemon.stopImageGC()
+	}
 	// Keep mounts and networking running on daemon shutdown if
 	// we are to keep containers running and restore them.
 
@@ -1106,6 +1154,13 @@ func prepareTempDir(rootDir string, rootIDs idtools.IDPair) (string, error) {
 	var tmpDir string
 	if tmpDir = os.Getenv("DOCKER_TMPDIR"); tmpDir == "" {
 		tmpDir = filepath.Join(rootDir, "tmp")
+		// The volume subpaths are bind mounted in the tmp directory; they
+		// are left mounted if the daemon didn't shut down cleanly and must
+		// be unmounted so that the content of the volumes isn't deleted.
+		if err := mount.RecursiveUnmount(tmpDir); err != nil {
+			logrus.WithError(err).Warnf("failed to unmount the mounts left in %s: not deleting it", tmpDir)
+			return tmpDir, idtools.MkdirAllAndChown(tmpDir, 0700, rootIDs)
+		}
 		newName := tmpDir + "-old"
 		if err := os.Rename(tmpDir, newName); err == nil {
 			go func() {
@@ -1153,6 +1208,16 @@ func (daemon *Daemon) configureVolumes(rootIDs idtools.IDPair) (*store.VolumeSto
 	if !drivers.Register(volumeDriver, volumeDriver.Name()) {
 		return nil, errors.New("local volume driver could not be registered")
 	}
+	for name, address := range daemon.configStore.CSIPlugins {
+		d, err := csi.New(name, address, filepath.Join(daemon.configStore.Root, "csi", name))
+		if err != nil {
+			return nil, err
+		}
+		if !drivers.Register(d, name) {
+			d.Close()
+			return nil, errors.Errorf("CSI volume driver %s could not be registered", name)
+		}
+	}
 	return store.New(daemon.configStore.Root, drivers)
 }
 
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
@@ -77,6 +78,10 @@ const (
 	// DefaultRuntimeName is the default runtime to be used by
 	// containerd if none is specified
 	DefaultRuntimeName = "docker-runc"
+
+	// rootlessUserlandProxy is the userland proxy used in rootless mode,
+	// which exposes the ports through RootlessKit
+	rootlessUserlandProxy = "rootlesskit-docker-proxy"
 )
 
 type containerGetter interface {
@@ -304,6 +309,18 @@ func (daemon *Daemon) adaptContainerSettings(hostConfig *containertypes.HostConf
 		hostConfig.IpcMode = containertypes.IpcMode(m)
 	}
 
+	// Set default cgroup namespace mode, if unset for container
+	if hostConfig.CgroupnsMode.IsEmpty() {
+		m := "host"
+		if daemon.cgroupUnified {
+			m = "private"
+		}
+		if daemon.configStore != nil && daemon.configStore.CgroupNamespaceMode != "" {
+			m = daemon.configStore.CgroupNamespaceMode
+		}
+		hostConfig.CgroupnsMode = containertypes.CgroupnsMode(m)
+	}
+
 	adaptSharedNamespaceContainer(daemon, hostConfig)
 
 	var err error
@@ -576,7 +593,7 @@ func UsingSystemd(config *config.Config) bool {
 // hostconfig and config structures.
 func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.HostConfig, config *containertypes.Config, update bool) ([]string, error) {
 	var warnings []string
-	sysInfo := sysinfo.New(true)
+	sysInfo := getSysInfo(daemon.configStore, true)
 
 	w, err := verifyContainerResources(&hostConfig.Resources, sysInfo, update)
 
@@ -612,6 +629,14 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *containertypes.
 			return warnings, fmt.Errorf("cannot share the host PID namespace when user namespaces are enabled")
 		}
 	}
+	if !hostConfig.CgroupnsMode.Valid() {
+		return warnings, fmt.Errorf("invalid cgroup namespace mode: %v", hostConfig.CgroupnsMode)
+	}
+	if hostConfig.CgroupnsMode.IsPrivate() {
+		if _, err := os.Stat("/proc/self/ns/cgroup"); err != nil {
+			return warnings, fmt.Errorf("Your kernel does not support cgroup namespaces")
+		}
+	}
 	if hostConfig.CgroupParent != "" && UsingSystemd(daemon.configStore) {
 		// CgroupParent for systemd cgroup should be named as "xxx.slice"
 		if len(hostConfig.CgroupParent) <= 6 || !strings.HasSuffix(hostConfig.CgroupParent, ".slice") {
@@ -704,6 +729,17 @@ func verifyDaemonSettings(conf *config.Config) error {
 			return fmt.Errorf("cgroup-parent for systemd cgroup should be a valid slice named as \"xxx.slice\"")
 		}
 	}
+	if conf.Rootless {
+		if !rsystem.RunningInUserNS() {
+			return fmt.Errorf("rootless mode requires the daemon to run in a user namespace; use dockerd-rootless.sh to start it")
+		}
+		if conf.RemappedRoot != "" {
+			return fmt.Errorf("--userns-remap is not supported in rootless mode")
+		}
+		if UsingSystemd(conf) {
+			return fmt.Errorf("the systemd cgroup driver is not supported in rootless mode")
+		}
+	}
 
 	if conf.DefaultRuntime == "" {
 		conf.DefaultRuntime = config.StockRuntimeName
@@ -848,11 +884,19 @@ func (daemon *Daemon) initNetworkController(config *config.Config, activeSandbox
 }
 
 func driverOptions(config *config.Config) []nwconfig.Option {
+	userlandProxyPath := config.BridgeConfig.UserlandProxyPath
+	if userlandProxyPath == "" && config.Rootless {
+		// Ports published by a rootless daemon are only reachable from the
+		// host through the port driver of RootlessKit.
+		if p, err := exec.LookPath(rootlessUserlandProxy); err == nil {
+			userlandProxyPath = p
+		}
+	}
 	bridgeConfig := options.Generic{
 		"EnableIPForwarding":  config.BridgeConfig.EnableIPForward,
 		"EnableIPTables":      config.BridgeConfig.EnableIPTables,
 		"EnableUserlandProxy": config.BridgeConfig.EnableUserlandProxy,
-		"UserlandProxyPath":   config.BridgeConfig.UserlandProxyPath}
+		"UserlandProxyPath":   userlandProxyPath}
 	bridgeOption := options.Generic{netlabel.GenericData: bridgeConfig}
 
 	dOptions := []nwconfig.Option{}
@@ -1293,6 +1337,9 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 	if !c.IsRunning() {
 		return nil, errNotRunning(c.ID)
 	}
+	if daemon.cgroupUnified {
+		return daemon.cgroup2Stats(c)
+	}
 	cs, err := daemon.containerd.Stats(context.Background(), c.ID)
 	if err != nil {
 		if strings.Contains(err.Error(), "container not found") {
@@ -1488,7 +1535,7 @@ func (daemon *Daemon) initCgroupsPath(path string) error {
 	}
 
 	path = filepath.Join(mnt, root, path)
-	sysinfo := sysinfo.New(true)
+	sysinfo := getSysInfo(daemon.configStore, true)
 	if err := maybeCreateCPURealTimeFile(sysinfo.CPURealtimePeriod, daemon.configStore.CPURealtimePeriod, "cpu.rt_period_us", path); err != nil {
 		return err
 	}
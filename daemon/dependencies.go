@@ -0,0 +1,233 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultDependencyTimeout is the time to wait for a dependency to
+	// meet its condition if its timeout is not set.
+	defaultDependencyTimeout = 60 * time.Second
+
+	// dependencyPollInterval is the interval between checks of the state
+	// of a dependency.
+	dependencyPollInterval = 250 * time.Millisecond
+)
+
+// validateDependencies checks the dependencies of a container.
+func validateDependencies(deps []containertypes.Dependency) error {
+	for _, dep := range deps {
+		if dep.Container == "" {
+			return errors.New("dependency requires a container name or ID")
+		}
+		switch dep.Condition {
+		case "", containertypes.DependencyStarted, containertypes.DependencyHealthy, containertypes.DependencyCompletedSuccessfully:
+		default:
+			return errors.Errorf("invalid condition '%s' for dependency %s", dep.Condition, dep.Container)
+		}
+		if dep.Timeout < 0 {
+			return errors.Errorf("timeout of dependency %s cannot be negative", dep.Container)
+		}
+		if dep.Timeout != 0 && dep.Timeout < containertypes.MinimumDuration {
+			return errors.Errorf("timeout of dependency %s cannot be less than %s", dep.Container, containertypes.MinimumDuration)
+		}
+	}
+	return nil
+}
+
+// checkDependencyCycle returns an error if the container would depend on
+// itself with the dependencies deps, directly or through the dependencies of
+// its dependencies. Dependencies which don't exist yet are ignored.
+func (daemon *Daemon) checkDependencyCycle(c *container.Container, deps []containertypes.Dependency) error {
+	name := strings.TrimPrefix(c.Name, "/")
+	path := []string{name}
+	visited := make(map[string]bool)
+
+	var visit func(deps []containertypes.Dependency) error
+	visit = func(deps []containertypes.Dependency) error {
+		for _, dep := range deps {
+			d, err := daemon.GetContainer(dep.Container)
+			if isDependencyOn(c, dep.Container, d) {
+				if len(path) == 1 {
+					return errdefs.InvalidParameter(errors.Errorf("container %s cannot depend on itself", name))
+				}
+				return errdefs.InvalidParameter(errors.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), name))
+			}
+			if err != nil || visited[d.ID] {
+				continue
+			}
+			visited[d.ID] = true
+
+			d.Lock()
+			next := d.HostConfig.DependsOn
+			d.Unlock()
+
+			path = append(path, strings.TrimPrefix(d.Name, "/"))
+			if err := visit(next); err != nil {
+				return err
+			}
+			path = path[:len(path)-1]
+		}
+		return nil
+	}
+	return visit(deps)
+}
+
+// isDependencyOn returns whether the dependency name, which resolves to the
+// container d if it exists, refers to the container c. The container c may
+// not be registered yet, so its name and full ID are matched explicitly.
+func isDependencyOn(c *container.Container, name string, d *container.Container) bool {
+	if d != nil {
+		return d.ID == c.ID
+	}
+	return name == c.ID || "/"+strings.TrimPrefix(name, "/") == c.Name
+}
+
+// restartOrder returns the containers to restart on restore which each of
+// the containers depends on, so that they can be started after them. The
+// containers in a dependency cycle, or which depend on one, can't be
+// ordered and are returned separately.
+func (daemon *Daemon) restartOrder(containers map[*container.Container]chan struct{}) (map[*container.Container][]*container.Container, []*container.Container) {
+	deps := make(map[*container.Container][]*container.Container)
+	dependents := make(map[*container.Container][]*container.Container)
+	pending := make(map[*container.Container]int)
+	for c := range containers {
+		pending[c] = 0
+		for _, dep := range c.HostConfig.DependsOn {
+			d, err := daemon.GetContainer(dep.Container)
+			if err != nil {
+				continue
+			}
+			if _, ok := containers[d]; !ok {
+				continue
+			}
+			deps[c] = append(deps[c], d)
+			dependents[d] = append(dependents[d], c)
+			pending[c]++
+		}
+	}
+
+	// Walk the dependency graph in topological order, the containers which
+	// are never reached are in or behind a cycle.
+	var queue []*container.Container
+	for c, n := range pending {
+		if n == 0 {
+			queue = append(queue, c)
+		}
+	}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		delete(pending, c)
+		for _, d := range dependents[c] {
+			pending[d]--
+			if pending[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	var cyclic []*container.Container
+	for c := range pending {
+		delete(deps, c)
+		cyclic = append(cyclic, c)
+	}
+	return deps, cyclic
+}
+
+// waitForDependencies waits for the dependencies of the container to meet
+// their condition, in order. If a dependency can't meet its condition, or
+// doesn't meet it in time, a dependency_failed event is logged for the
+// container and an error is returned. When restoring, the dependencies
+// restarted by the daemon were started before the container, so a
+// dependency which is not running fails immediately.
+func (daemon *Daemon) waitForDependencies(c *container.Container, restoring bool) error {
+	for _, dep := range c.HostConfig.DependsOn {
+		condition := dep.Condition
+		if condition == "" {
+			condition = containertypes.DependencyStarted
+		}
+		timeout := dep.Timeout
+		if timeout == 0 {
+			timeout = defaultDependencyTimeout
+		}
+
+		if err := daemon.waitForDependency(dep.Container, condition, timeout, restoring); err != nil {
+			daemon.LogContainerEventWithAttributes(c, "dependency_failed", map[string]string{
+				"dependency": dep.Container,
+				"condition":  string(condition),
+				"error":      err.Error(),
+			})
+			return errdefs.Conflict(errors.Wrapf(err, "cannot start container %s", c.ID))
+		}
+	}
+	return nil
+}
+
+// waitForDependency polls the state of the container name until it meets
+// the condition, or the timeout expires.
+func (daemon *Daemon) waitForDependency(name string, condition containertypes.DependencyCondition, timeout time.Duration, restoring bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ready, err := daemon.checkDependency(name, condition, restoring)
+		if err != nil {
+			return err
+		}
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("dependency %s is not %s after %s", name, condition, timeout)
+		}
+		time.Sleep(dependencyPollInterval)
+	}
+}
+
+// checkDependency returns whether the container name meets the condition. An
+// error is returned if the container can't meet the condition, or if it is
+// not running when restoring.
+func (daemon *Daemon) checkDependency(name string, condition containertypes.DependencyCondition, restoring bool) (bool, error) {
+	dep, err := daemon.GetContainer(name)
+	if err != nil {
+		return false, errors.Wrapf(err, "dependency %s", name)
+	}
+
+	dep.Lock()
+	defer dep.Unlock()
+
+	ready, err := dependencyReady(dep, name, condition)
+	if err == nil && !ready && restoring && !dep.Running && !dep.Restarting {
+		return false, errors.Errorf("dependency %s is not running", name)
+	}
+	return ready, err
+}
+
+// dependencyReady returns whether the dependency dep meets the condition.
+// Callers must hold a lock on the dependency.
+func dependencyReady(dep *container.Container, name string, condition containertypes.DependencyCondition) (bool, error) {
+	switch condition {
+	case containertypes.DependencyHealthy:
+		hc := dep.Config.Healthcheck
+		if hc == nil || len(hc.Test) == 0 || hc.Test[0] == "NONE" {
+			return false, errors.Errorf("dependency %s has no healthcheck", name)
+		}
+		return dep.Running && !dep.Restarting && dep.Health != nil && dep.Health.Status() == types.Healthy, nil
+	case containertypes.DependencyCompletedSuccessfully:
+		if dep.Running || dep.Restarting || !dep.HasBeenStartedBefore {
+			return false, nil
+		}
+		if dep.ExitCodeValue != 0 {
+			return false, errors.Errorf("dependency %s exited with code %d", name, dep.ExitCodeValue)
+		}
+		return true, nil
+	default:
+		return dep.Running && !dep.Restarting, nil
+	}
+}
@@ -0,0 +1,220 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/truncindex"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	testCases := []struct {
+		deps        []containertypes.Dependency
+		expectedErr string
+	}{
+		{deps: nil},
+		{deps: []containertypes.Dependency{{Container: "db"}, {Container: "init", Condition: containertypes.DependencyCompletedSuccessfully, Timeout: time.Minute}}},
+		{deps: []containertypes.Dependency{{}}, expectedErr: "dependency requires a container name or ID"},
+		{deps: []containertypes.Dependency{{Container: "db", Condition: "ready"}}, expectedErr: "invalid condition 'ready' for dependency db"},
+		{deps: []containertypes.Dependency{{Container: "db", Timeout: -time.Second}}, expectedErr: "timeout of dependency db cannot be negative"},
+	}
+
+	for _, tc := range testCases {
+		err := validateDependencies(tc.deps)
+		if tc.expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.Error(t, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestCheckDependency(t *testing.T) {
+	running := &container.Container{
+		ID:     "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+		Name:   "running",
+		Config: &containertypes.Config{},
+		State:  &container.State{Running: true},
+	}
+	healthy := &container.Container{
+		ID:   "3cdbd1aa394fd68559fd1441d6eff2ab7c1e6363582c82febfaa8045df3bd8de",
+		Name: "healthy",
+		Config: &containertypes.Config{
+			Healthcheck: &containertypes.HealthConfig{Test: []string{"CMD", "true"}},
+		},
+		State: &container.State{Running: true, Health: &container.Health{}},
+	}
+	healthy.State.Health.SetStatus(types.Healthy)
+	completed := &container.Container{
+		ID:                   "75fb0b800922abdbef2d27e60abcdfaf7fb0698b2a96d22d3354da361a6ff4a5",
+		Name:                 "completed",
+		Config:               &containertypes.Config{},
+		State:                &container.State{},
+		HasBeenStartedBefore: true,
+	}
+	failed := &container.Container{
+		ID:                   "d22d69a2b8960bf7fafdcba06e72d2febdba960bf7fafdcba06e72d2f9008b060b",
+		Name:                 "failed",
+		Config:               &containertypes.Config{},
+		State:                &container.State{ExitCodeValue: 1},
+		HasBeenStartedBefore: true,
+	}
+
+	store := container.NewMemoryStore()
+	index := truncindex.NewTruncIndex([]string{})
+	containersReplica, err := container.NewViewDB()
+	assert.NilError(t, err)
+	daemon := &Daemon{
+		containers:        store,
+		containersReplica: containersReplica,
+		idIndex:           index,
+	}
+	for _, c := range []*container.Container{running, healthy, completed, failed} {
+		store.Add(c.ID, c)
+		index.Add(c.ID)
+		daemon.reserveName(c.ID, c.Name)
+	}
+
+	testCases := []struct {
+		name        string
+		condition   containertypes.DependencyCondition
+		ready       bool
+		expectedErr string
+	}{
+		{name: "running", condition: containertypes.DependencyStarted, ready: true},
+		{name: "completed", condition: containertypes.DependencyStarted, ready: false},
+		{name: "healthy", condition: containertypes.DependencyHealthy, ready: true},
+		{name: "running", condition: containertypes.DependencyHealthy, expectedErr: "dependency running has no healthcheck"},
+		{name: "completed", condition: containertypes.DependencyCompletedSuccessfully, ready: true},
+		{name: "running", condition: containertypes.DependencyCompletedSuccessfully, ready: false},
+		{name: "failed", condition: containertypes.DependencyCompletedSuccessfully, expectedErr: "dependency failed exited with code 1"},
+	}
+
+	for _, tc := range testCases {
+		ready, err := daemon.checkDependency(tc.name, tc.condition, false)
+		if tc.expectedErr != "" {
+			assert.Error(t, err, tc.expectedErr)
+			continue
+		}
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(tc.ready, ready), "%s %s", tc.name, tc.condition)
+	}
+
+	_, err = daemon.checkDependency("unknown", containertypes.DependencyStarted, false)
+	assert.Check(t, is.ErrorContains(err, "dependency unknown"))
+
+	err = daemon.waitForDependency("completed", containertypes.DependencyStarted, 10*time.Millisecond, false)
+	assert.Error(t, err, "dependency completed is not started after 10ms")
+
+	// On restore, the dependencies are started first, and a dependency
+	// which is not running fails without waiting for the timeout.
+	_, err = daemon.checkDependency("completed", containertypes.DependencyStarted, true)
+	assert.Error(t, err, "dependency completed is not running")
+	ready, err := daemon.checkDependency("completed", containertypes.DependencyCompletedSuccessfully, true)
+	assert.NilError(t, err)
+	assert.Check(t, ready)
+	ready, err = daemon.checkDependency("running", containertypes.DependencyCompletedSuccessfully, true)
+	assert.NilError(t, err)
+	assert.Check(t, !ready)
+}
+
+func newDependencyTestDaemon(t *testing.T, containers ...*container.Container) *Daemon {
+	store := container.NewMemoryStore()
+	index := truncindex.NewTruncIndex([]string{})
+	containersReplica, err := container.NewViewDB()
+	assert.NilError(t, err)
+	daemon := &Daemon{
+		containers:        store,
+		containersReplica: containersReplica,
+		idIndex:           index,
+	}
+	for _, c := range containers {
+		store.Add(c.ID, c)
+		index.Add(c.ID)
+		daemon.reserveName(c.ID, c.Name)
+	}
+	return daemon
+}
+
+func newDependencyTestContainer(id, name string, deps ...string) *container.Container {
+	c := &container.Container{
+		ID:         id,
+		Name:       "/" + name,
+		Config:     &containertypes.Config{},
+		HostConfig: &containertypes.HostConfig{},
+		State:      &container.State{},
+	}
+	for _, dep := range deps {
+		c.HostConfig.DependsOn = append(c.HostConfig.DependsOn, containertypes.Dependency{Container: dep})
+	}
+	return c
+}
+
+func TestCheckDependencyCycle(t *testing.T) {
+	web := newDependencyTestContainer("2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e0c2e4c7bc3f9cf3a0c8f8e1a9b7e5d3c", "web", "api")
+	api := newDependencyTestContainer("8c2e4c7bc3f9cf3a0c8f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e", "api", "db")
+	daemon := newDependencyTestDaemon(t, web, api)
+
+	// The container being created is not registered yet.
+	db := newDependencyTestContainer("f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e8c2e4c7bc3f9cf3a0c8", "db")
+	daemon.reserveName(db.ID, db.Name)
+
+	testCases := []struct {
+		deps        []string
+		expectedErr string
+	}{
+		{deps: nil},
+		{deps: []string{"cache"}},
+		{deps: []string{"db"}, expectedErr: "container db cannot depend on itself"},
+		{deps: []string{db.ID}, expectedErr: "container db cannot depend on itself"},
+		{deps: []string{"cache", "api"}, expectedErr: "dependency cycle: db -> api -> db"},
+		{deps: []string{"web"}, expectedErr: "dependency cycle: db -> web -> api -> db"},
+	}
+
+	for _, tc := range testCases {
+		var deps []containertypes.Dependency
+		for _, dep := range tc.deps {
+			deps = append(deps, containertypes.Dependency{Container: dep})
+		}
+		err := daemon.checkDependencyCycle(db, deps)
+		if tc.expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.Error(t, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestRestartOrder(t *testing.T) {
+	web := newDependencyTestContainer("2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e0c2e4c7bc3f9cf3a0c8f8e1a9b7e5d3c", "web", "api", "cache")
+	api := newDependencyTestContainer("8c2e4c7bc3f9cf3a0c8f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e", "api", "db")
+	db := newDependencyTestContainer("f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e8c2e4c7bc3f9cf3a0c8", "db")
+	cache := newDependencyTestContainer("c3f9cf3a0c8f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e8c2e4c7b", "cache")
+	// ping and pong were created before dependency cycles were rejected.
+	ping := newDependencyTestContainer("0c8f8e1a9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e8c2e4c7bc3f9cf3a", "ping", "pong")
+	pong := newDependencyTestContainer("9b7e5d3c2a1f5ed3d4f2bd5ea3c8b9c72b7e2d5e8c2e4c7bc3f9cf3a0c8f8e1a", "pong", "ping")
+	daemon := newDependencyTestDaemon(t, web, api, db, cache, ping, pong)
+
+	// cache is not restarted by the daemon.
+	restart := make(map[*container.Container]chan struct{})
+	for _, c := range []*container.Container{web, api, db, ping, pong} {
+		restart[c] = make(chan struct{})
+	}
+
+	deps, cyclic := daemon.restartOrder(restart)
+	assert.Assert(t, is.Len(deps[web], 1))
+	assert.Check(t, deps[web][0] == api)
+	assert.Assert(t, is.Len(deps[api], 1))
+	assert.Check(t, deps[api][0] == db)
+	assert.Check(t, is.Len(deps[db], 0))
+	assert.Check(t, is.Len(deps, 2))
+	assert.Check(t, is.Len(cyclic, 2))
+	for _, c := range cyclic {
+		assert.Check(t, c == ping || c == pong, c.Name)
+	}
+}
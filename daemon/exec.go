@@ -31,6 +31,53 @@ func (d *Daemon) registerExecCommand(container *container.Container, config *exe
 	d.execCommands.Add(config.ID, config)
 }
 
+// execInContainer runs cmd inside the container as the user and in the
+// working directory of the container, writing its output to output, and
+// returns its exit code. The attributes are added to the attributes of the
+// exec_create event. If ctx is cancelled, the process is terminated.
+func (d *Daemon) execInContainer(ctx context.Context, cntr *container.Container, cmd strslice.StrSlice, attributes map[string]string, output io.Writer) (int, error) {
+	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmd)
+	execConfig := exec.NewConfig()
+	execConfig.OpenStdin = false
+	execConfig.OpenStdout = true
+	execConfig.OpenStderr = true
+	execConfig.ContainerID = cntr.ID
+	execConfig.DetachKeys = []byte{}
+	execConfig.Entrypoint = entrypoint
+	execConfig.Args = args
+	execConfig.Tty = false
+	execConfig.Privileged = false
+	execConfig.User = cntr.Config.User
+	execConfig.WorkingDir = cntr.Config.WorkingDir
+
+	linkedEnv, err := d.setupLinkedContainers(cntr)
+	if err != nil {
+		return -1, err
+	}
+	execConfig.Env = container.ReplaceOrAppendEnvValues(cntr.CreateDaemonEnvironment(execConfig.Tty, linkedEnv), execConfig.Env)
+
+	d.registerExecCommand(cntr, execConfig)
+	attrs := map[string]string{
+		"execID": execConfig.ID,
+	}
+	for k, v := range attributes {
+		attrs[k] = v
+	}
+	d.LogContainerEventWithAttributes(cntr, "exec_create: "+execConfig.Entrypoint+" "+strings.Join(execConfig.Args, " "), attrs)
+
+	if err := d.ContainerExecStart(ctx, execConfig.ID, nil, output, output); err != nil {
+		return -1, err
+	}
+	info, err := d.getExecConfig(execConfig.ID)
+	if err != nil {
+		return -1, err
+	}
+	if info.ExitCode == nil {
+		return -1, fmt.Errorf("exec %s in container %s has no exit code", execConfig.ID, cntr.ID)
+	}
+	return *info.ExitCode, nil
+}
+
 // ExecExists looks up the exec instance and returns a bool if it exists or not.
 // It will also return the error produced by `getConfig`
 func (d *Daemon) ExecExists(name string) (bool, error) {
@@ -189,6 +189,8 @@ type Options struct {
 	UIDMaps             []idtools.IDMap
 	GIDMaps             []idtools.IDMap
 	ExperimentalEnabled bool
+	// Rootless selects from the drivers that work without privileges.
+	Rootless bool
 }
 
 // New creates the driver and initializes it at the specified root.
@@ -199,12 +201,16 @@ func New(name string, pg plugingetter.PluginGetter, config Options) (Driver, err
 	}
 
 	// Guess for prior driver
-	driversMap := scanPriorDrivers(config.Root)
+	driversMap := scanPriorDrivers(config.Root, config.Rootless)
 	list := strings.Split(priority, ",")
+	if config.Rootless {
+		list = strings.Split(rootlessPriority, ",")
+	}
 	logrus.Debugf("[graphdriver] priority list: %v", list)
 	for _, name := range list {
-		if name == "vfs" {
-			// don't use vfs even if there is state present.
+		if name == "vfs" && !config.Rootless {
+			// don't use vfs even if there is state present, unless
+			// running rootless where it may be the only driver available.
 			continue
 		}
 		if _, prior := driversMap[name]; prior {
@@ -262,13 +268,14 @@ func New(name string, pg plugingetter.PluginGetter, config Options) (Driver, err
 	return nil, fmt.Errorf("No supported storage backend found")
 }
 
-// scanPriorDrivers returns an un-ordered scan of directories of prior storage drivers
-func scanPriorDrivers(root string) map[string]bool {
+// scanPriorDrivers returns an un-ordered scan of directories of prior storage
+// drivers. The state of vfs is only taken into account if includeVFS is set.
+func scanPriorDrivers(root string, includeVFS bool) map[string]bool {
 	driversMap := make(map[string]bool)
 
 	for driver := range drivers {
 		p := filepath.Join(root, driver)
-		if _, err := os.Stat(p); err == nil && driver != "vfs" {
+		if _, err := os.Stat(p); err == nil && (driver != "vfs" || includeVFS) {
 			if !isEmptyDir(p) {
 				driversMap[driver] = true
 			}
@@ -9,6 +9,9 @@ import (
 var (
 	// List of drivers that should be used in an order
 	priority = "zfs"
+
+	// Rootless mode is not supported on this platform
+	rootlessPriority = priority
 )
 
 // Mounted checks if the given path is mounted as the fs type
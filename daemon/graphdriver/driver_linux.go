@@ -50,6 +50,9 @@ var (
 	// List of drivers that should be used in an order
 	priority = "btrfs,zfs,overlay2,aufs,overlay,devicemapper,vfs"
 
+	// List of drivers that can be used by a rootless daemon, in order
+	rootlessPriority = "fuse-overlayfs,vfs"
+
 	// FsNames maps filesystem id to name of the filesystem.
 	FsNames = map[FsMagic]string{
 		FsMagicAufs:        "aufs",
@@ -34,3 +34,18 @@ func TestIsEmptyDir(t *testing.T) {
 	empty = isEmptyDir(d)
 	assert.Check(t, !empty)
 }
+
+func TestScanPriorDriversVFS(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "test-scan-prior-drivers")
+	assert.NilError(t, err)
+	defer os.RemoveAll(tmp)
+
+	drivers["vfs"] = nil
+	defer delete(drivers, "vfs")
+
+	err = os.MkdirAll(filepath.Join(tmp, "vfs", "dir", "layer"), 0755)
+	assert.NilError(t, err)
+
+	assert.Check(t, !scanPriorDrivers(tmp, false)["vfs"])
+	assert.Check(t, scanPriorDrivers(tmp, true)["vfs"])
+}
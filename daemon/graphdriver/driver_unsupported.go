@@ -5,6 +5,9 @@ package graphdriver // import "github.com/docker/docker/daemon/graphdriver"
 var (
 	// List of drivers that should be used in an order
 	priority = "unsupported"
+
+	// Rootless mode is not supported on this platform
+	rootlessPriority = priority
 )
 
 // GetFSMagic returns the filesystem id given the path.
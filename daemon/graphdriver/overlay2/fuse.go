@@ -0,0 +1,99 @@
+// +build linux
+
+package overlay2 // import "github.com/docker/docker/daemon/graphdriver/overlay2"
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/overlayutils"
+	"github.com/docker/docker/pkg/fsutils"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/locker"
+	"github.com/sirupsen/logrus"
+)
+
+// fuseDriverName is the name of the overlay driver that mounts the layers
+// with the fuse-overlayfs helper instead of the kernel overlay filesystem.
+// It is used by rootless daemons, which are not allowed to mount overlay.
+const (
+	fuseDriverName = "fuse-overlayfs"
+	fuseBinary     = "fuse-overlayfs"
+)
+
+func init() {
+	graphdriver.Register(fuseDriverName, InitFuse)
+}
+
+// InitFuse returns an overlay driver that uses fuse-overlayfs to mount the
+// layers. The on-disk layout is the same as the one of overlay2, but the
+// native diff is never used. If the fuse-overlayfs binary is not found, the
+// error graphdriver.ErrNotSupported is returned.
+func InitFuse(home string, options []string, uidMaps, gidMaps []idtools.IDMap) (graphdriver.Driver, error) {
+	if len(options) > 0 {
+		return nil, fmt.Errorf("%s does not support storage options", fuseDriverName)
+	}
+	if _, err := exec.LookPath(fuseBinary); err != nil {
+		logrus.WithField("storage-driver", fuseDriverName).Debugf("%s not found: %v", fuseBinary, err)
+		return nil, graphdriver.ErrNotSupported
+	}
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		logrus.WithField("storage-driver", fuseDriverName).Debugf("/dev/fuse not available: %v", err)
+		return nil, graphdriver.ErrNotSupported
+	}
+
+	testdir := home
+	if _, err := os.Stat(testdir); os.IsNotExist(err) {
+		testdir = filepath.Dir(testdir)
+	}
+	fsMagic, err := graphdriver.GetFSMagic(testdir)
+	if err != nil {
+		return nil, err
+	}
+	if fsName, ok := graphdriver.FsNames[fsMagic]; ok {
+		backingFs = fsName
+	}
+	supportsDType, err := fsutils.SupportsDType(testdir)
+	if err != nil {
+		return nil, err
+	}
+	if !supportsDType {
+		return nil, overlayutils.ErrDTypeNotSupported(fuseDriverName, backingFs)
+	}
+
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		return nil, err
+	}
+	if err := idtools.MkdirAllAndChown(path.Join(home, linkDir), 0700, idtools.IDPair{UID: rootUID, GID: rootGID}); err != nil {
+		return nil, err
+	}
+
+	d := &Driver{
+		home:          home,
+		uidMaps:       uidMaps,
+		gidMaps:       gidMaps,
+		ctr:           graphdriver.NewRefCounter(graphdriver.NewDefaultChecker()),
+		supportsDType: supportsDType,
+		locker:        locker.New(),
+		fuse:          true,
+	}
+	d.naiveDiff = graphdriver.NewNaiveDiffDriver(d, uidMaps, gidMaps)
+
+	return d, nil
+}
+
+// mountFuse mounts the layers on target with fuse-overlayfs.
+func mountFuse(lowers []string, upper, work, target string) error {
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", strings.Join(lowers, ":"), upper, work)
+	cmd := exec.Command(fuseBinary, "-o", opts, target)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", fuseBinary, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -103,6 +103,7 @@ type Driver struct {
 	naiveDiff     graphdriver.DiffDriver
 	supportsDType bool
 	locker        *locker.Locker
+	fuse          bool // mount the layers with fuse-overlayfs
 }
 
 var (
@@ -291,7 +292,16 @@ func useNaiveDiff(home string) bool {
 	return useNaiveDiffOnly
 }
 
+// useNaiveDiff reports whether the diff of the layers must be computed by
+// comparing their mounts; fuse-overlayfs does not support the native diff.
+func (d *Driver) useNaiveDiff() bool {
+	return d.fuse || useNaiveDiff(d.home)
+}
+
 func (d *Driver) String() string {
+	if d.fuse {
+		return fuseDriverName
+	}
 	return driverName
 }
 
@@ -301,7 +311,7 @@ func (d *Driver) Status() [][2]string {
 	return [][2]string{
 		{"Backing Filesystem", backingFs},
 		{"Supports d_type", strconv.FormatBool(d.supportsDType)},
-		{"Native Overlay Diff", strconv.FormatBool(!useNaiveDiff(d.home))},
+		{"Native Overlay Diff", strconv.FormatBool(!d.useNaiveDiff())},
 	}
 }
 
@@ -572,10 +582,6 @@ func (d *Driver) Get(id, mountLabel string) (_ containerfs.ContainerFS, retErr e
 	for i, s := range splitLowers {
 		absLowers[i] = path.Join(d.home, s)
 	}
-	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", strings.Join(absLowers, ":"), path.Join(dir, "diff"), path.Join(dir, "work"))
-	mountData := label.FormatMountLabel(opts, mountLabel)
-	mount := unix.Mount
-	mountTarget := mergedDir
 
 	rootUID, rootGID, err := idtools.GetRootUIDGID(d.uidMaps, d.gidMaps)
 	if err != nil {
@@ -585,6 +591,18 @@ func (d *Driver) Get(id, mountLabel string) (_ containerfs.ContainerFS, retErr e
 		return nil, err
 	}
 
+	if d.fuse {
+		if err := mountFuse(absLowers, diffDir, workDir, mergedDir); err != nil {
+			return nil, fmt.Errorf("error creating overlay mount to %s: %v", mergedDir, err)
+		}
+		return containerfs.NewLocalContainerFS(mergedDir), nil
+	}
+
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", strings.Join(absLowers, ":"), path.Join(dir, "diff"), path.Join(dir, "work"))
+	mountData := label.FormatMountLabel(opts, mountLabel)
+	mount := unix.Mount
+	mountTarget := mergedDir
+
 	pageSize := unix.Getpagesize()
 
 	// Go can return a larger page size than supported by the system
@@ -723,7 +741,7 @@ func (d *Driver) getDiffPath(id string) string {
 // and its parent and returns the size in bytes of the changes
 // relative to its base filesystem directory.
 func (d *Driver) DiffSize(id, parent string) (size int64, err error) {
-	if useNaiveDiff(d.home) || !d.isParent(id, parent) {
+	if d.useNaiveDiff() || !d.isParent(id, parent) {
 		return d.naiveDiff.DiffSize(id, parent)
 	}
 	return directory.Size(context.TODO(), d.getDiffPath(id))
@@ -732,7 +750,7 @@ func (d *Driver) DiffSize(id, parent string) (size int64, err error) {
 // Diff produces an archive of the changes between the specified
 // layer and its parent layer which may be "".
 func (d *Driver) Diff(id, parent string) (io.ReadCloser, error) {
-	if useNaiveDiff(d.home) || !d.isParent(id, parent) {
+	if d.useNaiveDiff() || !d.isParent(id, parent) {
 		return d.naiveDiff.Diff(id, parent)
 	}
 
@@ -749,7 +767,7 @@ func (d *Driver) Diff(id, parent string) (io.ReadCloser, error) {
 // Changes produces a list of changes between the specified layer and its
 // parent layer. If parent is "", then all changes will be ADD changes.
 func (d *Driver) Changes(id, parent string) ([]archive.Change, error) {
-	if useNaiveDiff(d.home) || !d.isParent(id, parent) {
+	if d.useNaiveDiff() || !d.isParent(id, parent) {
 		return d.naiveDiff.Changes(id, parent)
 	}
 	// Overlay doesn't have snapshots, so we need to get changes from all parent
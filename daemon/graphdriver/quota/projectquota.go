@@ -56,6 +56,7 @@ import (
 	"io/ioutil"
 	"path"
 	"path/filepath"
+	"sync"
 	"unsafe"
 
 	rsystem "github.com/opencontainers/runc/libcontainer/system"
@@ -72,10 +73,32 @@ type Quota struct {
 // who wants to apply project quotas to container dirs
 type Control struct {
 	backingFsBlockDev string
-	nextProjectID     uint32
+	dev               uint64
 	quotas            map[string]uint32
 }
 
+// nextProjectIDs - next project id to be used on each backing filesystem.
+// Several Controls can live on the same filesystem (e.g. the overlay2 home
+// and the local volumes root), and each one only scans its own base path,
+// so project ids are allocated per device rather than per Control.
+var nextProjectIDs = struct {
+	sync.Mutex
+	ids map[uint64]uint32
+}{ids: make(map[uint64]uint32)}
+
+// allocateProjectID - return a project id of at least minProjectID that
+// was not handed out before on dev
+func allocateProjectID(dev uint64, minProjectID uint32) uint32 {
+	nextProjectIDs.Lock()
+	defer nextProjectIDs.Unlock()
+	projectID := nextProjectIDs.ids[dev]
+	if projectID < minProjectID {
+		projectID = minProjectID
+	}
+	nextProjectIDs.ids[dev] = projectID + 1
+	return projectID
+}
+
 // NewControl - initialize project quota support.
 // Test to make sure that quota can be set on a test dir and find
 // the first project id to be used for the next container create.
@@ -94,9 +117,10 @@ type Control struct {
 // and all containers will be assigned larger project ids (e.g. >= 1000).
 // This is a way to prevent xfs_quota management from conflicting with docker.
 //
-// Then try to create a test directory with the next project id and set a quota
-// on it. If that works, continue to scan existing containers to map allocated
-// project ids.
+// Then scan existing containers to map allocated project ids, and try to set a
+// quota on the next project id. Project ids are shared by all the Controls on
+// the same filesystem, so that e.g. container and volume quotas never use the
+// same project id.
 //
 func NewControl(basePath string) (*Control, error) {
 	//
@@ -107,6 +131,11 @@ func NewControl(basePath string) (*Control, error) {
 		return nil, ErrQuotaNotSupported
 	}
 
+	var stat unix.Stat_t
+	if err := unix.Stat(basePath, &stat); err != nil {
+		return nil, err
+	}
+
 	//
 	// create backing filesystem device node
 	//
@@ -134,32 +163,36 @@ func NewControl(basePath string) (*Control, error) {
 	}
 	minProjectID++
 
-	//
-	// Test if filesystem supports project quotas by trying to set
-	// a quota on the first available project id
-	//
-	quota := Quota{
-		Size: 0,
-	}
-	if err := setProjectQuota(backingFsBlockDev, minProjectID, quota); err != nil {
-		return nil, err
-	}
-
 	q := Control{
 		backingFsBlockDev: backingFsBlockDev,
-		nextProjectID:     minProjectID + 1,
+		dev:               uint64(stat.Dev),
 		quotas:            make(map[string]uint32),
 	}
 
 	//
-	// get first project id to be used for next container
+	// scan existing containers to map allocated project ids, and get the
+	// first project id above them
 	//
-	err = q.findNextProjectID(basePath)
+	nextProjectID, err := q.findNextProjectID(basePath, minProjectID)
 	if err != nil {
 		return nil, err
 	}
 
-	logrus.Debugf("NewControl(%s): nextProjectID = %d", basePath, q.nextProjectID)
+	//
+	// Test if filesystem supports project quotas by trying to set
+	// a quota on the first available project id. Other Controls on the
+	// same filesystem may already use it, so take it from the ids that
+	// are free on the device.
+	//
+	probeProjectID := allocateProjectID(q.dev, nextProjectID)
+	quota := Quota{
+		Size: 0,
+	}
+	if err := setProjectQuota(backingFsBlockDev, probeProjectID, quota); err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("NewControl(%s): nextProjectID = %d", basePath, probeProjectID+1)
 	return &q, nil
 }
 
@@ -169,7 +202,7 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 
 	projectID, ok := q.quotas[targetPath]
 	if !ok {
-		projectID = q.nextProjectID
+		projectID = allocateProjectID(q.dev, 0)
 
 		//
 		// assign project id to new container directory
@@ -180,7 +213,6 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 		}
 
 		q.quotas[targetPath] = projectID
-		q.nextProjectID++
 	}
 
 	//
@@ -190,6 +222,22 @@ func (q *Control) SetQuota(targetPath string, quota Quota) error {
 	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
 }
 
+// ClearQuota - remove the quota limits of a directory that was configured
+// with SetQuota, before the directory is removed
+func (q *Control) ClearQuota(targetPath string) error {
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		return nil
+	}
+
+	logrus.Debugf("ClearQuota(%s): projectID=%d", targetPath, projectID)
+	if err := setProjectQuota(q.backingFsBlockDev, projectID, Quota{Size: 0}); err != nil {
+		return err
+	}
+	delete(q.quotas, targetPath)
+	return nil
+}
+
 // setProjectQuota - set the quota for project id on xfs block device
 func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
 	var d C.fs_disk_quota_t
@@ -217,17 +265,35 @@ func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) er
 
 // GetQuota - get the quota limits of a directory that was configured with SetQuota
 func (q *Control) GetQuota(targetPath string, quota *Quota) error {
+	d, err := q.getProjectQuota(targetPath)
+	if err != nil {
+		return err
+	}
+	quota.Size = uint64(d.d_blk_hardlimit) * 512
 
-	projectID, ok := q.quotas[targetPath]
-	if !ok {
-		return fmt.Errorf("quota not found for path : %s", targetPath)
+	return nil
+}
+
+// GetUsage - get the number of bytes used by a directory that was configured
+// with SetQuota
+func (q *Control) GetUsage(targetPath string) (uint64, error) {
+	d, err := q.getProjectQuota(targetPath)
+	if err != nil {
+		return 0, err
 	}
+	return uint64(d.d_bcount) * 512, nil
+}
 
-	//
-	// get the quota limit for the container's project id
-	//
+// getProjectQuota - get the quota limits and usage of the project id of
+// a directory
+func (q *Control) getProjectQuota(targetPath string) (C.fs_disk_quota_t, error) {
 	var d C.fs_disk_quota_t
 
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		return d, fmt.Errorf("quota not found for path : %s", targetPath)
+	}
+
 	var cs = C.CString(q.backingFsBlockDev)
 	defer C.free(unsafe.Pointer(cs))
 
@@ -235,12 +301,10 @@ func (q *Control) GetQuota(targetPath string, quota *Quota) error {
 		uintptr(unsafe.Pointer(cs)), uintptr(C.__u32(projectID)),
 		uintptr(unsafe.Pointer(&d)), 0, 0)
 	if errno != 0 {
-		return fmt.Errorf("Failed to get quota limit for projid %d on %s: %v",
+		return d, fmt.Errorf("Failed to get quota limit for projid %d on %s: %v",
 			projectID, q.backingFsBlockDev, errno.Error())
 	}
-	quota.Size = uint64(d.d_blk_hardlimit) * 512
-
-	return nil
+	return d, nil
 }
 
 // getProjectID - get the project id of path on xfs
@@ -288,10 +352,10 @@ func setProjectID(targetPath string, projectID uint32) error {
 
 // findNextProjectID - find the next project id to be used for containers
 // by scanning driver home directory to find used project ids
-func (q *Control) findNextProjectID(home string) error {
+func (q *Control) findNextProjectID(home string, nextProjectID uint32) (uint32, error) {
 	files, err := ioutil.ReadDir(home)
 	if err != nil {
-		return fmt.Errorf("read directory failed : %s", home)
+		return 0, fmt.Errorf("read directory failed : %s", home)
 	}
 	for _, file := range files {
 		if !file.IsDir() {
@@ -300,17 +364,17 @@ func (q *Control) findNextProjectID(home string) error {
 		path := filepath.Join(home, file.Name())
 		projid, err := getProjectID(path)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if projid > 0 {
 			q.quotas[path] = projid
 		}
-		if q.nextProjectID <= projid {
-			q.nextProjectID = projid + 1
+		if nextProjectID <= projid {
+			nextProjectID = projid + 1
 		}
 	}
 
-	return nil
+	return nextProjectID, nil
 }
 
 func free(p *C.char) {
@@ -58,6 +58,8 @@ func TestBlockDev(t *testing.T) {
 	t.Run("testSmallerThanQuota", wrapMountTest(imageFileName, true, wrapQuotaTest(testSmallerThanQuota)))
 	t.Run("testBiggerThanQuota", wrapMountTest(imageFileName, true, wrapQuotaTest(testBiggerThanQuota)))
 	t.Run("testRetrieveQuota", wrapMountTest(imageFileName, true, wrapQuotaTest(testRetrieveQuota)))
+	t.Run("testSharedProjectIDs", wrapMountTest(imageFileName, true, wrapQuotaTest(testSharedProjectIDs)))
+	t.Run("testClearQuota", wrapMountTest(imageFileName, true, wrapQuotaTest(testClearQuota)))
 }
 
 func wrapMountTest(imageFileName string, enableQuota bool, testFunc func(t *testing.T, mountPoint, backingFsDev string)) func(*testing.T) {
@@ -150,3 +152,40 @@ func testRetrieveQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir
 	assert.NilError(t, ctrl.GetQuota(testSubDir, &q))
 	assert.Check(t, is.Equal(uint64(testQuotaSize), q.Size))
 }
+
+func testSharedProjectIDs(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir string) {
+	// A second Control on the same filesystem must not hand out the
+	// project ids of the first one
+	otherDir, err := ioutil.TempDir(homeDir, "other-control")
+	assert.NilError(t, err)
+	defer os.RemoveAll(otherDir)
+	otherCtrl, err := NewControl(otherDir)
+	assert.NilError(t, err)
+	otherSubDir, err := ioutil.TempDir(otherDir, "quota-test")
+	assert.NilError(t, err)
+
+	assert.NilError(t, ctrl.SetQuota(testSubDir, Quota{testQuotaSize}))
+	assert.NilError(t, otherCtrl.SetQuota(otherSubDir, Quota{2 * testQuotaSize}))
+
+	projectID, err := getProjectID(testSubDir)
+	assert.NilError(t, err)
+	otherProjectID, err := getProjectID(otherSubDir)
+	assert.NilError(t, err)
+	assert.Check(t, projectID != otherProjectID)
+
+	var q Quota
+	assert.NilError(t, ctrl.GetQuota(testSubDir, &q))
+	assert.Check(t, is.Equal(uint64(testQuotaSize), q.Size))
+}
+
+func testClearQuota(t *testing.T, ctrl *Control, homeDir, testDir, testSubDir string) {
+	assert.NilError(t, ctrl.SetQuota(testSubDir, Quota{testQuotaSize}))
+	projectID := ctrl.quotas[testSubDir]
+	assert.NilError(t, ctrl.ClearQuota(testSubDir))
+
+	var q Quota
+	assert.Check(t, is.ErrorContains(ctrl.GetQuota(testSubDir, &q), "quota not found"))
+	ctrl.quotas[testSubDir] = projectID
+	assert.NilError(t, ctrl.GetQuota(testSubDir, &q))
+	assert.Check(t, is.Equal(uint64(0), q.Size))
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"runtime"
-	"strings"
 	"sync"
 	"time"
 
@@ -13,7 +12,6 @@ import (
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/container"
-	"github.com/docker/docker/daemon/exec"
 	"github.com/sirupsen/logrus"
 )
 
@@ -67,49 +65,16 @@ func (p *cmdProbe) run(ctx context.Context, d *Daemon, cntr *container.Container
 	if p.shell {
 		cmdSlice = append(getShell(cntr.Config), cmdSlice...)
 	}
-	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmdSlice)
-	execConfig := exec.NewConfig()
-	execConfig.OpenStdin = false
-	execConfig.OpenStdout = true
-	execConfig.OpenStderr = true
-	execConfig.ContainerID = cntr.ID
-	execConfig.DetachKeys = []byte{}
-	execConfig.Entrypoint = entrypoint
-	execConfig.Args = args
-	execConfig.Tty = false
-	execConfig.Privileged = false
-	execConfig.User = cntr.Config.User
-	execConfig.WorkingDir = cntr.Config.WorkingDir
-
-	linkedEnv, err := d.setupLinkedContainers(cntr)
-	if err != nil {
-		return nil, err
-	}
-	execConfig.Env = container.ReplaceOrAppendEnvValues(cntr.CreateDaemonEnvironment(execConfig.Tty, linkedEnv), execConfig.Env)
-
-	d.registerExecCommand(cntr, execConfig)
-	attributes := map[string]string{
-		"execID": execConfig.ID,
-	}
-	d.LogContainerEventWithAttributes(cntr, "exec_create: "+execConfig.Entrypoint+" "+strings.Join(execConfig.Args, " "), attributes)
-
 	output := &limitedBuffer{}
-	err = d.ContainerExecStart(ctx, execConfig.ID, nil, output, output)
+	exitCode, err := d.execInContainer(ctx, cntr, cmdSlice, nil, output)
 	if err != nil {
 		return nil, err
 	}
-	info, err := d.getExecConfig(execConfig.ID)
-	if err != nil {
-		return nil, err
-	}
-	if info.ExitCode == nil {
-		return nil, fmt.Errorf("healthcheck for container %s has no exit code", cntr.ID)
-	}
 	// Note: Go's json package will handle invalid UTF-8 for us
 	out := output.String()
 	return &types.HealthcheckResult{
 		End:      time.Now(),
-		ExitCode: *info.ExitCode,
+		ExitCode: exitCode,
 		Output:   out,
 	}, nil
 }
@@ -0,0 +1,43 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/docker/docker/daemon/images"
+	"github.com/sirupsen/logrus"
+)
+
+// StartImageGC starts the garbage collection of unused images if disk usage
+// thresholds are configured. buildCache is pruned before any image is
+// removed; it may be nil.
+func (daemon *Daemon) StartImageGC(buildCache images.BuildCachePruner) {
+	conf := daemon.configStore
+	if conf.ImageGCHighThreshold == 0 {
+		return
+	}
+
+	// Monitor the filesystem holding the storage driver's data, which may
+	// be mounted separately from the daemon root.
+	root := filepath.Join(daemon.root, daemon.graphDrivers[runtime.GOOS])
+	if _, err := os.Stat(root); err != nil {
+		root = daemon.root
+	}
+
+	gcConfig := images.GCConfig{
+		Root:          root,
+		HighThreshold: conf.ImageGCHighThreshold,
+		LowThreshold:  conf.ImageGCLowThreshold,
+		MinAge:        conf.GetImageGCMinAge(),
+		BuildCache:    buildCache,
+	}
+
+	logrus.Infof("Starting image garbage collection for %s (high threshold %d%%, low threshold %d%%, minimum age %s)",
+		root, gcConfig.HighThreshold, gcConfig.LowThreshold, gcConfig.MinAge)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	daemon.stopImageGC = cancel
+	go daemon.imageService.RunGC(ctx, gcConfig)
+}
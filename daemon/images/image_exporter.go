@@ -4,15 +4,17 @@ import (
 	"io"
 
 	"github.com/docker/docker/image/tarexport"
+	"github.com/docker/docker/pkg/archive"
 )
 
 // ExportImage exports a list of images to the given output stream. The
 // exported images are archived into a tar when written to the output
 // stream. All images with the given tag and all versions containing
-// the same tag are exported. names is the set of tags to export, and
-// outStream is the writer which the images are written to.
-func (i *ImageService) ExportImage(names []string, outStream io.Writer) error {
-	imageExporter := tarexport.NewTarExporter(i.imageStore, i.layerStores, i.referenceStore, i)
+// the same tag are exported. names is the set of tags to export, the layers
+// are compressed with compression, and outStream is the writer which the
+// images are written to.
+func (i *ImageService) ExportImage(names []string, compression archive.Compression, outStream io.Writer) error {
+	imageExporter := tarexport.NewCompressedTarExporter(i.imageStore, i.layerStores, i.referenceStore, i, compression)
 	return imageExporter.Save(names, outStream)
 }
 
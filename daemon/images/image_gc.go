@@ -0,0 +1,201 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/sirupsen/logrus"
+)
+
+// gcInterval is the interval at which the disk usage is checked.
+const gcInterval = time.Minute
+
+// BuildCachePruner removes unused build cache. It is implemented by
+// builder/fscache.FSCache.
+type BuildCachePruner interface {
+	Prune(ctx context.Context) (uint64, error)
+}
+
+// GCConfig is the configuration of the image garbage collector.
+type GCConfig struct {
+	// Root is a directory on the filesystem holding the layers, whose
+	// disk usage is monitored.
+	Root string
+	// HighThreshold is the disk usage, in percent, above which a
+	// collection is started.
+	HighThreshold int
+	// LowThreshold is the disk usage, in percent, down to which a
+	// collection frees space.
+	LowThreshold int
+	// MinAge is the time an image must have been unused before it is
+	// collected.
+	MinAge time.Duration
+	// BuildCache is pruned before any image is removed. It is optional.
+	BuildCache BuildCachePruner
+}
+
+// RunGC monitors the disk usage of the filesystem holding the layers, and
+// collects unused images until ctx is cancelled.
+func (i *ImageService) RunGC(ctx context.Context, config GCConfig) {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		if err := i.collectGarbage(ctx, config); err != nil {
+			logrus.WithError(err).Error("image garbage collection failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectGarbage removes the dangling build cache, then unused images least
+// recently used first, as long as the disk usage is above the low threshold.
+// Nothing is removed unless the disk usage is above the high threshold.
+func (i *ImageService) collectGarbage(ctx context.Context, config GCConfig) error {
+	usage, err := diskUsagePercent(config.Root)
+	if err != nil {
+		return err
+	}
+	if usage < config.HighThreshold {
+		return nil
+	}
+
+	// Image prune and garbage collection must not remove the same images
+	// concurrently.
+	if !atomic.CompareAndSwapInt32(&i.pruneRunning, 0, 1) {
+		logrus.Debug("image garbage collection skipped: a prune operation is running")
+		return nil
+	}
+	defer atomic.StoreInt32(&i.pruneRunning, 0)
+
+	logrus.Infof("Disk usage of %s is %d%%, collecting unused images down to %d%%", config.Root, usage, config.LowThreshold)
+
+	if config.BuildCache != nil {
+		reclaimed, err := config.BuildCache.Prune(ctx)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to prune build cache")
+		} else {
+			logrus.Infof("Pruned %d bytes of build cache", reclaimed)
+		}
+		if usage, err = diskUsagePercent(config.Root); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range i.gcCandidates(time.Now().Add(-config.MinAge)) {
+		if usage <= config.LowThreshold {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if !i.removeUnusedImage(id) {
+			continue
+		}
+		if usage, err = diskUsagePercent(config.Root); err != nil {
+			return err
+		}
+	}
+	if usage > config.LowThreshold {
+		logrus.Warnf("Disk usage of %s is still %d%% after image garbage collection", config.Root, usage)
+	}
+	return nil
+}
+
+// gcCandidates returns the images which have not been used since cutoff,
+// least recently used first.
+func (i *ImageService) gcCandidates(cutoff time.Time) []image.ID {
+	type candidate struct {
+		id       image.ID
+		lastUsed time.Time
+	}
+	var candidates []candidate
+	for id, img := range i.imageStore.Map() {
+		lastUsed := i.lastUsed(id, img)
+		if lastUsed.After(cutoff) {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, lastUsed: lastUsed})
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		return candidates[a].lastUsed.Before(candidates[b].lastUsed)
+	})
+
+	ids := make([]image.ID, len(candidates))
+	for n, c := range candidates {
+		ids[n] = c.id
+	}
+	return ids
+}
+
+// lastUsed returns the last time a container was created from the image.
+// Images stored before this was tracked fall back to the time they were
+// last tagged, or created.
+func (i *ImageService) lastUsed(id image.ID, img *image.Image) time.Time {
+	if t, err := i.imageStore.GetLastUsed(id); err == nil && !t.IsZero() {
+		return t
+	}
+	if t, err := i.imageStore.GetLastUpdated(id); err == nil && !t.IsZero() {
+		return t
+	}
+	return img.Created
+}
+
+// removeUnusedImage removes all references to an image, and the image
+// itself, unless it has children or is used by a container. A "gc" event is
+// logged for every removed image.
+func (i *ImageService) removeUnusedImage(id image.ID) bool {
+	img, err := i.imageStore.Get(id)
+	if err != nil {
+		// already removed along with a child image
+		return false
+	}
+	if len(i.imageStore.Children(id)) != 0 {
+		return false
+	}
+	// Check before removing any reference: deleting one of several
+	// references only untags the image, even if it's in use.
+	if c := i.containers.First(func(c *container.Container) bool { return c.ImageID == id }); c != nil {
+		return false
+	}
+
+	refs := i.referenceStore.References(id.Digest())
+	var refName string
+	if len(refs) == 0 {
+		hex := id.Digest().Hex()
+		if _, err := i.ImageDelete(hex, false, true); imageDeleteFailed(hex, err) {
+			return false
+		}
+	} else {
+		refName = refs[0].String()
+		for _, ref := range refs {
+			if _, err := i.ImageDelete(ref.String(), false, true); imageDeleteFailed(ref.String(), err) {
+				return false
+			}
+		}
+	}
+
+	attributes := map[string]string{}
+	if img.Config != nil {
+		copyAttributes(attributes, img.Config.Labels)
+	}
+	if refName != "" {
+		attributes["name"] = refName
+	}
+	i.eventsService.Log("gc", events.ImageEventType, events.Actor{
+		ID:         id.String(),
+		Attributes: attributes,
+	})
+	logrus.Infof("Image garbage collection removed %s", id)
+	return true
+}
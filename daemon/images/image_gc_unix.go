@@ -0,0 +1,24 @@
+// +build !windows
+
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// diskUsagePercent returns the percentage of used blocks of the filesystem
+// holding path.
+func diskUsagePercent(path string) (int, error) {
+	var buf unix.Statfs_t
+	if err := unix.Statfs(path, &buf); err != nil {
+		return 0, err
+	}
+	if buf.Blocks == 0 {
+		return 0, nil
+	}
+	used := buf.Blocks - buf.Bfree
+	// Blocks reserved for root are not available to the daemon's
+	// users, so they are left out of the total like df(1) does.
+	total := used + uint64(buf.Bavail)
+	return int(used * 100 / total), nil
+}
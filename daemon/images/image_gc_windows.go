@@ -0,0 +1,7 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import "github.com/pkg/errors"
+
+func diskUsagePercent(path string) (int, error) {
+	return 0, errors.New("image garbage collection is not supported on Windows")
+}
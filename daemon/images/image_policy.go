@@ -0,0 +1,41 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/image"
+)
+
+// CheckImagePolicy enforces the image signature policy on an image that a
+// container is about to be created from. Images with a digest reference
+// were verified when they were pulled or pushed, and images built or
+// committed on top of them inherit their origin. Any other image, for example
+// one that was loaded or imported from a tarball, has an unknown origin and
+// is checked here.
+func (i *ImageService) CheckImagePolicy(refOrID string, img *image.Image) error {
+	if i.imagePolicy == nil {
+		return nil
+	}
+
+	for id := img.ID(); id != ""; {
+		for _, ref := range i.referenceStore.References(id.Digest()) {
+			if _, ok := ref.(reference.Canonical); ok {
+				return nil
+			}
+		}
+		parent, err := i.imageStore.GetParent(id)
+		if err != nil {
+			break
+		}
+		id = parent
+	}
+
+	// Only apply the rules of a repository if img was referenced by name;
+	// images referenced by ID get the default requirement.
+	var named reference.Named
+	if ref, err := reference.ParseNormalizedNamed(refOrID); err == nil {
+		if _, err := i.referenceStore.Get(reference.TagNameOnly(ref)); err == nil {
+			named = ref
+		}
+	}
+	return i.imagePolicy.VerifyUnknownOrigin(named)
+}
@@ -9,6 +9,7 @@ import (
 	dist "github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/distribution"
 	progressutils "github.com/docker/docker/distribution/utils"
 	"github.com/docker/docker/errdefs"
@@ -82,6 +83,10 @@ func (i *ImageService) pullImageWithReference(ctx context.Context, ref reference
 		OS:              os,
 	}
 
+	if i.imagePolicy != nil {
+		imagePullConfig.ManifestVerifier = i.imagePolicy.Verify
+	}
+
 	err := distribution.Pull(ctx, ref, imagePullConfig)
 	close(progressChan)
 	<-writesDone
@@ -125,3 +130,12 @@ func (i *ImageService) GetRepository(ctx context.Context, ref reference.Named, a
 	}
 	return repository, confirmedV2, lastError
 }
+
+// InspectManifest returns the manifest resolved in the registry for ref,
+// including the manifests, image configurations and layers it refers to.
+func (i *ImageService) InspectManifest(ctx context.Context, ref reference.Named, authConfig *types.AuthConfig) (*registrytypes.DistributionManifest, error) {
+	return distribution.InspectManifest(ctx, ref, &distribution.Config{
+		AuthConfig:      authConfig,
+		RegistryService: i.registryService,
+	})
+}
@@ -50,10 +50,16 @@ func (i *ImageService) PushImage(ctx context.Context, image, tag string, metaHea
 			ImageStore:       distribution.NewImageConfigStoreFromStore(i.imageStore),
 			ReferenceStore:   i.referenceStore,
 		},
-		ConfigMediaType: schema2.MediaTypeImageConfig,
-		LayerStores:     distribution.NewLayerProvidersFromStores(i.layerStores),
-		TrustKey:        i.trustKey,
-		UploadManager:   i.uploadManager,
+		ConfigMediaType:       schema2.MediaTypeImageConfig,
+		LayerStores:           distribution.NewLayerProvidersFromStores(i.layerStores),
+		TrustKey:              i.trustKey,
+		UploadManager:         i.uploadManager,
+		LayerCompression:      i.pushCompression,
+		LayerCompressionLevel: i.pushCompressionLevel,
+	}
+
+	if i.imagePolicy != nil {
+		imagePushConfig.ManifestVerifier = i.imagePolicy.Verify
 	}
 
 	err = distribution.Push(ctx, ref, imagePushConfig)
@@ -0,0 +1,131 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"encoding/json"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/system"
+	"github.com/pkg/errors"
+)
+
+// RebaseImage creates a new image from refOrID, which was built on top of
+// oldBase, with the layers of oldBase replaced by the layers of newBase. The
+// layers the image adds to oldBase are registered again on top of newBase;
+// as their content is unchanged they keep their diff IDs, so the new image
+// shares them with the original image when it is pushed. The new image is
+// tagged repository:tag if repository is set.
+func (i *ImageService) RebaseImage(refOrID, oldBase, newBase, repository, tag string) (string, error) {
+	var newRef reference.Named
+	if repository != "" {
+		var err error
+		if newRef, err = reference.ParseNormalizedNamed(repository); err != nil {
+			return "", errdefs.InvalidParameter(err)
+		}
+		if tag != "" {
+			if newRef, err = reference.WithTag(reference.TrimNamed(newRef), tag); err != nil {
+				return "", errdefs.InvalidParameter(err)
+			}
+		}
+	}
+
+	img, err := i.GetImage(refOrID)
+	if err != nil {
+		return "", err
+	}
+	oldBaseImg, err := i.GetImage(oldBase)
+	if err != nil {
+		return "", err
+	}
+	newBaseImg, err := i.GetImage(newBase)
+	if err != nil {
+		return "", err
+	}
+	if oldBaseImg.ID() == newBaseImg.ID() {
+		return i.tagRebasedImage(img.ID(), newRef)
+	}
+
+	rebased, err := image.NewRebasedImage(img, oldBaseImg, newBaseImg)
+	if err != nil {
+		return "", errdefs.InvalidParameter(err)
+	}
+
+	os := img.OperatingSystem()
+	if !system.IsOSSupported(os) {
+		return "", errdefs.InvalidParameter(system.ErrNotSupportedOperatingSystem)
+	}
+	layerStore, ok := i.layerStores[os]
+	if !ok {
+		return "", errdefs.InvalidParameter(system.ErrNotSupportedOperatingSystem)
+	}
+
+	// Register every layer the image adds to the old base on top of the new
+	// base. The layers are released once the image holds a reference to the
+	// top-most one.
+	baseLen := len(oldBaseImg.RootFS.DiffIDs)
+	parent := newBaseImg.RootFS.ChainID()
+	for n := baseLen; n < len(img.RootFS.DiffIDs); n++ {
+		l, err := rebaseLayer(layerStore, layer.CreateChainID(img.RootFS.DiffIDs[:n+1]), parent)
+		if err != nil {
+			return "", err
+		}
+		defer layer.ReleaseAndLog(layerStore, l)
+		parent = l.ChainID()
+	}
+	if parent != rebased.RootFS.ChainID() {
+		return "", errors.Errorf("rebased layers don't match the image configuration: %s != %s", parent, rebased.RootFS.ChainID())
+	}
+
+	config, err := json.Marshal(rebased)
+	if err != nil {
+		return "", err
+	}
+	id, err := i.imageStore.Create(config)
+	if err != nil {
+		return "", err
+	}
+
+	i.LogImageEventWithAttributes(id.String(), "", "rebase", map[string]string{
+		"image":    img.ID().String(),
+		"old_base": oldBaseImg.ID().String(),
+		"new_base": newBaseImg.ID().String(),
+	})
+	return i.tagRebasedImage(id, newRef)
+}
+
+// tagRebasedImage tags the image id with newRef, if it is set.
+func (i *ImageService) tagRebasedImage(id image.ID, newRef reference.Named) (string, error) {
+	if newRef != nil {
+		if err := i.TagImageWithReference(id, newRef); err != nil {
+			return "", err
+		}
+	}
+	return id.String(), nil
+}
+
+// rebaseLayer registers the content of the layer chainID on top of parent.
+func rebaseLayer(layerStore layer.Store, chainID, parent layer.ChainID) (layer.Layer, error) {
+	src, err := layerStore.Get(chainID)
+	if err != nil {
+		return nil, err
+	}
+	defer layer.ReleaseAndLog(layerStore, src)
+
+	ts, err := src.TarStream()
+	if err != nil {
+		return nil, err
+	}
+	defer ts.Close()
+
+	l, err := layerStore.Register(ts, parent)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to register layer %s", src.DiffID())
+	}
+	if l.DiffID() != src.DiffID() {
+		layer.ReleaseAndLog(layerStore, l)
+		return nil, errors.Errorf("layer content changed while rebasing: expected %s, got %s", src.DiffID(), l.DiffID())
+	}
+	return l, nil
+}
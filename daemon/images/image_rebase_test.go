@@ -0,0 +1,155 @@
+package images // import "github.com/docker/docker/daemon/images"
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	containertypes "github.com/docker/docker/api/types/container"
+	eventtypes "github.com/docker/docker/api/types/events"
+	daemonevents "github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	refstore "github.com/docker/docker/reference"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/skip"
+)
+
+func init() {
+	graphdriver.ApplyUncompressedLayer = archive.UnpackLayer
+	vfs.CopyDir = archive.NewDefaultArchiver().CopyWithTar
+}
+
+func newRebaseTestService(t *testing.T, root string) (*ImageService, layer.Store) {
+	ls, err := layer.NewStoreFromOptions(layer.StoreOptions{
+		Root:                      root,
+		MetadataStorePathTemplate: filepath.Join(root, "image", "%s", "layerdb"),
+		GraphDriver:               "vfs",
+		IDMappings:                &idtools.IDMappings{},
+		OS:                        runtime.GOOS,
+	})
+	assert.NilError(t, err)
+	ifs, err := image.NewFSStoreBackend(filepath.Join(root, "imagedb"))
+	assert.NilError(t, err)
+	imageStore, err := image.NewImageStore(ifs, map[string]image.LayerGetReleaser{runtime.GOOS: ls})
+	assert.NilError(t, err)
+	referenceStore, err := refstore.NewReferenceStore(filepath.Join(root, "repositories.json"))
+	assert.NilError(t, err)
+
+	return NewImageService(ImageServiceConfig{
+		EventsService:  daemonevents.New(),
+		ImageStore:     imageStore,
+		LayerStores:    map[string]layer.Store{runtime.GOOS: ls},
+		ReferenceStore: referenceStore,
+	}), ls
+}
+
+// registerTestLayer registers a layer holding a single file on top of parent.
+func registerTestLayer(t *testing.T, ls layer.Store, parent layer.ChainID, name string) layer.Layer {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte(name))
+	assert.NilError(t, err)
+	assert.NilError(t, tw.Close())
+
+	l, err := ls.Register(buf, parent)
+	assert.NilError(t, err)
+	return l
+}
+
+func createTestImage(t *testing.T, s *ImageService, config *containertypes.Config, layers ...layer.Layer) image.ID {
+	img := &image.Image{
+		V1Image: image.V1Image{
+			OS:           runtime.GOOS,
+			Architecture: runtime.GOARCH,
+			Config:       config,
+		},
+		RootFS: image.NewRootFS(),
+	}
+	for _, l := range layers {
+		img.RootFS.Append(l.DiffID())
+	}
+	b, err := json.Marshal(img)
+	assert.NilError(t, err)
+	id, err := s.imageStore.Create(b)
+	assert.NilError(t, err)
+	return id
+}
+
+func TestRebaseImage(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux")
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	root, err := ioutil.TempDir("", "rebase-image")
+	assert.NilError(t, err)
+	defer os.RemoveAll(root)
+	s, ls := newRebaseTestService(t, root)
+
+	oldLayer := registerTestLayer(t, ls, "", "old")
+	defer layer.ReleaseAndLog(ls, oldLayer)
+	newLayer := registerTestLayer(t, ls, "", "new")
+	defer layer.ReleaseAndLog(ls, newLayer)
+	appLayer := registerTestLayer(t, ls, oldLayer.ChainID(), "app")
+	defer layer.ReleaseAndLog(ls, appLayer)
+
+	oldBase := createTestImage(t, s, &containertypes.Config{Env: []string{"BASE=1"}}, oldLayer)
+	newBase := createTestImage(t, s, &containertypes.Config{Env: []string{"BASE=2"}}, newLayer)
+	app := createTestImage(t, s, &containertypes.Config{Env: []string{"BASE=1", "APP=1"}}, oldLayer, appLayer)
+
+	_, events, cancel := s.eventsService.Subscribe()
+	defer cancel()
+
+	id, err := s.RebaseImage(app.String(), oldBase.String(), newBase.String(), "app", "patched")
+	assert.NilError(t, err)
+
+	rebased, err := s.imageStore.Get(image.ID(id))
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]layer.DiffID{newLayer.DiffID(), appLayer.DiffID()}, rebased.RootFS.DiffIDs))
+	assert.Check(t, is.DeepEqual([]string{"BASE=2", "APP=1"}, rebased.Config.Env))
+	// the layers of the rebased image are registered on top of the new base
+	l, err := ls.Get(rebased.RootFS.ChainID())
+	assert.NilError(t, err)
+	layer.ReleaseAndLog(ls, l)
+
+	ref, err := reference.ParseNormalizedNamed("app:patched")
+	assert.NilError(t, err)
+	tagged, err := s.referenceStore.Get(ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(id, tagged.String()))
+
+	for _, action := range []string{"rebase", "tag"} {
+		select {
+		case ev := <-events:
+			msg := ev.(eventtypes.Message)
+			assert.Check(t, is.Equal(action, msg.Action))
+			assert.Check(t, is.Equal(id, msg.Actor.ID))
+			if action == "rebase" {
+				assert.Check(t, is.Equal(app.String(), msg.Actor.Attributes["image"]))
+				assert.Check(t, is.Equal(newBase.String(), msg.Actor.Attributes["new_base"]))
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout waiting for the %s event", action)
+		}
+	}
+
+	// the new base is not based on the app image
+	_, err = s.RebaseImage(newBase.String(), app.String(), oldBase.String(), "", "")
+	assert.Check(t, errdefs.IsInvalidParameter(err), "%v", err)
+
+	_, err = s.RebaseImage(app.String(), oldBase.String(), newBase.String(), "Invalid Repo", "")
+	assert.Check(t, errdefs.IsInvalidParameter(err), "%v", err)
+}
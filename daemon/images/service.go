@@ -7,9 +7,11 @@ import (
 	"github.com/docker/docker/container"
 	daemonevents "github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/policy"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	dockerreference "github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
 	"github.com/docker/libtrust"
@@ -32,10 +34,13 @@ type ImageServiceConfig struct {
 	ContainerStore            containerStore
 	DistributionMetadataStore metadata.Store
 	EventsService             *daemonevents.Events
+	ImagePolicy               *policy.Policy
 	ImageStore                image.Store
 	LayerStores               map[string]layer.Store
 	MaxConcurrentDownloads    int
 	MaxConcurrentUploads      int
+	PushCompression           archive.Compression
+	PushCompressionLevel      int
 	ReferenceStore            dockerreference.Store
 	RegistryService           registry.Service
 	TrustKey                  libtrust.PrivateKey
@@ -50,8 +55,11 @@ func NewImageService(config ImageServiceConfig) *ImageService {
 		distributionMetadataStore: config.DistributionMetadataStore,
 		downloadManager:           xfer.NewLayerDownloadManager(config.LayerStores, config.MaxConcurrentDownloads),
 		eventsService:             config.EventsService,
+		imagePolicy:               config.ImagePolicy,
 		imageStore:                config.ImageStore,
 		layerStores:               config.LayerStores,
+		pushCompression:           config.PushCompression,
+		pushCompressionLevel:      config.PushCompressionLevel,
 		referenceStore:            config.ReferenceStore,
 		registryService:           config.RegistryService,
 		trustKey:                  config.TrustKey,
@@ -65,9 +73,12 @@ type ImageService struct {
 	distributionMetadataStore metadata.Store
 	downloadManager           *xfer.LayerDownloadManager
 	eventsService             *daemonevents.Events
+	imagePolicy               *policy.Policy
 	imageStore                image.Store
 	layerStores               map[string]layer.Store // By operating system
 	pruneRunning              int32
+	pushCompression           archive.Compression
+	pushCompressionLevel      int
 	referenceStore            dockerreference.Store
 	registryService           registry.Service
 	trustKey                  libtrust.PrivateKey
@@ -98,6 +109,10 @@ func (i *ImageService) CreateLayer(container *container.Container, initFunc laye
 			return nil, err
 		}
 		layerID = img.RootFS.ChainID()
+		// used by the image garbage collector
+		if err := i.imageStore.SetLastUsed(container.ImageID); err != nil {
+			logrus.Warnf("failed to set last used time of image %s: %v", container.ImageID, err)
+		}
 	}
 
 	rwLayerOpts := &layer.CreateRWLayerOpts{
@@ -55,7 +55,7 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		meminfo = &system.MemInfo{}
 	}
 
-	sysInfo := sysinfo.New(true)
+	sysInfo := getSysInfo(daemon.configStore, true)
 	cRunning, cPaused, cStopped := stateCtr.get()
 
 	securityOptions := []string{}
@@ -76,6 +76,9 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 	if rootIDs.UID != 0 || rootIDs.GID != 0 {
 		securityOptions = append(securityOptions, "name=userns")
 	}
+	if daemon.configStore.Rootless {
+		securityOptions = append(securityOptions, "name=rootless")
+	}
 
 	var ds [][2]string
 	drivers := ""
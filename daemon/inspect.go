@@ -249,6 +249,7 @@ func (daemon *Daemon) VolumeInspect(name string) (*types.Volume, error) {
 	apiV := volumeToAPIType(v)
 	apiV.Mountpoint = v.Path()
 	apiV.Status = v.Status()
+	apiV.Snapshot = daemon.volumeSnapshotInfo(v.Name())
 	return apiV, nil
 }
 
@@ -0,0 +1,148 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultHookTimeout is the time a lifecycle hook is allowed to run if its
+// timeout is not set.
+const defaultHookTimeout = 30 * time.Second
+
+// validateLifecycleHooks checks the lifecycle hooks of a container.
+func validateLifecycleHooks(hooks *containertypes.LifecycleHooks) error {
+	if hooks == nil {
+		return nil
+	}
+	for name, hook := range map[string]*containertypes.LifecycleHook{
+		"post-start": hooks.PostStart,
+		"pre-stop":   hooks.PreStop,
+	} {
+		if hook == nil {
+			continue
+		}
+		if len(hook.Cmd) == 0 {
+			return errors.Errorf("%s hook requires a command", name)
+		}
+		if hook.Timeout < 0 {
+			return errors.Errorf("timeout of %s hook cannot be negative", name)
+		}
+		if hook.Timeout != 0 && hook.Timeout < containertypes.MinimumDuration {
+			return errors.Errorf("timeout of %s hook cannot be less than %s", name, containertypes.MinimumDuration)
+		}
+	}
+	if hooks.PreStop != nil && hooks.PreStop.OnFailure != "" {
+		return errors.New("failure policy is only supported for post-start hooks")
+	}
+	if hooks.PostStart != nil {
+		switch hooks.PostStart.OnFailure {
+		case "", containertypes.HookFailureIgnore, containertypes.HookFailureKill:
+		default:
+			return errors.Errorf("invalid failure policy '%s' for post-start hook", hooks.PostStart.OnFailure)
+		}
+	}
+	return nil
+}
+
+// runLifecycleHook execs the hook inside the container, and returns an error
+// if it fails or doesn't complete within the given timeout.
+func (daemon *Daemon) runLifecycleHook(c *container.Container, name string, hook *containertypes.LifecycleHook, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output := &limitedBuffer{}
+	exitCode, err := daemon.execInContainer(ctx, c, hook.Cmd, map[string]string{"hook": name}, output)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.Errorf("%s hook did not complete within %s", name, timeout)
+		}
+		return errors.Wrapf(err, "%s hook failed", name)
+	}
+	if exitCode != 0 {
+		return errors.Errorf("%s hook exited with code %d: %s", name, exitCode, output.String())
+	}
+	return nil
+}
+
+// runPostStartHook runs the post-start hook of the container in the
+// background, once the container is started. The container is killed if the
+// hook fails and its failure policy is "kill".
+func (daemon *Daemon) runPostStartHook(c *container.Container) {
+	if c.HostConfig.Lifecycle == nil || c.HostConfig.Lifecycle.PostStart == nil {
+		return
+	}
+	hook := c.HostConfig.Lifecycle.PostStart
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+
+	go func() {
+		err := daemon.runLifecycleHook(c, "post-start", hook, timeout)
+		if err == nil {
+			return
+		}
+		logger := logrus.WithError(err).WithField("container", c.ID)
+		if hook.OnFailure != containertypes.HookFailureKill {
+			logger.Warn("Ignoring failure of post-start hook")
+			return
+		}
+		logger.Error("Killing container after failure of post-start hook")
+		if err := daemon.Kill(c); err != nil {
+			logrus.WithError(err).WithField("container", c.ID).Error("Error killing container after failure of post-start hook")
+		}
+	}()
+}
+
+// runPreStopHook runs the pre-stop hook of the container, if any, and
+// returns the part of the stop timeout left once it completed. A negative
+// stop timeout means no timeout, and is returned unchanged.
+func (daemon *Daemon) runPreStopHook(c *container.Container, stopTimeout time.Duration) time.Duration {
+	if c.HostConfig.Lifecycle == nil || c.HostConfig.Lifecycle.PreStop == nil {
+		return stopTimeout
+	}
+	hook := c.HostConfig.Lifecycle.PreStop
+	timeout := preStopHookTimeout(hook.Timeout, stopTimeout)
+	if timeout == 0 {
+		return stopTimeout
+	}
+
+	start := time.Now()
+	if err := daemon.runLifecycleHook(c, "pre-stop", hook, timeout); err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Warn("Ignoring failure of pre-stop hook")
+	}
+	return remainingStopTimeout(stopTimeout, timeout, time.Since(start))
+}
+
+// preStopHookTimeout returns the time the pre-stop hook is allowed to run.
+// The hook may use at most half of the stop timeout, so that the container
+// always has the other half to exit after the stop signal, before it is
+// killed.
+func preStopHookTimeout(hookTimeout, stopTimeout time.Duration) time.Duration {
+	if hookTimeout == 0 {
+		hookTimeout = defaultHookTimeout
+	}
+	if stopTimeout >= 0 && stopTimeout/2 < hookTimeout {
+		return stopTimeout / 2
+	}
+	return hookTimeout
+}
+
+// remainingStopTimeout returns the part of the stop timeout left after the
+// pre-stop hook ran for elapsed. It's never less than the part of the stop
+// timeout which the hook wasn't allowed to use, even if the hook took a bit
+// longer than its timeout to be stopped.
+func remainingStopTimeout(stopTimeout, hookTimeout, elapsed time.Duration) time.Duration {
+	if stopTimeout < 0 {
+		return stopTimeout
+	}
+	if remaining := stopTimeout - elapsed; remaining > stopTimeout-hookTimeout {
+		return remaining
+	}
+	return stopTimeout - hookTimeout
+}
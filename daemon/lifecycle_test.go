@@ -0,0 +1,90 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestValidateLifecycleHooks(t *testing.T) {
+	cmd := []string{"/bin/sh", "-c", "true"}
+	testCases := []struct {
+		hooks       *containertypes.LifecycleHooks
+		expectedErr string
+	}{
+		{hooks: nil},
+		{hooks: &containertypes.LifecycleHooks{}},
+		{
+			hooks: &containertypes.LifecycleHooks{
+				PostStart: &containertypes.LifecycleHook{Cmd: cmd, Timeout: time.Second, OnFailure: containertypes.HookFailureKill},
+				PreStop:   &containertypes.LifecycleHook{Cmd: cmd},
+			},
+		},
+		{
+			hooks:       &containertypes.LifecycleHooks{PostStart: &containertypes.LifecycleHook{}},
+			expectedErr: "post-start hook requires a command",
+		},
+		{
+			hooks:       &containertypes.LifecycleHooks{PreStop: &containertypes.LifecycleHook{Cmd: cmd, Timeout: -time.Second}},
+			expectedErr: "timeout of pre-stop hook cannot be negative",
+		},
+		{
+			hooks:       &containertypes.LifecycleHooks{PreStop: &containertypes.LifecycleHook{Cmd: cmd, Timeout: time.Microsecond}},
+			expectedErr: "timeout of pre-stop hook cannot be less than 1ms",
+		},
+		{
+			hooks:       &containertypes.LifecycleHooks{PreStop: &containertypes.LifecycleHook{Cmd: cmd, OnFailure: containertypes.HookFailureKill}},
+			expectedErr: "failure policy is only supported for post-start hooks",
+		},
+		{
+			hooks:       &containertypes.LifecycleHooks{PostStart: &containertypes.LifecycleHook{Cmd: cmd, OnFailure: "restart"}},
+			expectedErr: "invalid failure policy 'restart' for post-start hook",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateLifecycleHooks(tc.hooks)
+		if tc.expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.Error(t, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestPreStopHookTimeout(t *testing.T) {
+	testCases := []struct {
+		hookTimeout, stopTimeout, expected time.Duration
+	}{
+		// the hook may use at most half of the stop timeout
+		{hookTimeout: 0, stopTimeout: 10 * time.Second, expected: 5 * time.Second},
+		{hookTimeout: 8 * time.Second, stopTimeout: 10 * time.Second, expected: 5 * time.Second},
+		{hookTimeout: 2 * time.Second, stopTimeout: 10 * time.Second, expected: 2 * time.Second},
+		{hookTimeout: 0, stopTimeout: 0, expected: 0},
+		// without stop timeout, the hook timeout applies
+		{hookTimeout: 0, stopTimeout: -1, expected: defaultHookTimeout},
+		{hookTimeout: time.Minute, stopTimeout: -1, expected: time.Minute},
+	}
+	for _, tc := range testCases {
+		assert.Check(t, is.Equal(tc.expected, preStopHookTimeout(tc.hookTimeout, tc.stopTimeout)), "hook timeout %s, stop timeout %s", tc.hookTimeout, tc.stopTimeout)
+	}
+}
+
+func TestRemainingStopTimeout(t *testing.T) {
+	testCases := []struct {
+		stopTimeout, hookTimeout, elapsed, expected time.Duration
+	}{
+		{stopTimeout: 10 * time.Second, hookTimeout: 5 * time.Second, elapsed: time.Second, expected: 9 * time.Second},
+		// a hook which used its whole timeout, or more, leaves the rest of
+		// the stop timeout to the container
+		{stopTimeout: 10 * time.Second, hookTimeout: 5 * time.Second, elapsed: 5 * time.Second, expected: 5 * time.Second},
+		{stopTimeout: 10 * time.Second, hookTimeout: 5 * time.Second, elapsed: 7 * time.Second, expected: 5 * time.Second},
+		{stopTimeout: -1, hookTimeout: defaultHookTimeout, elapsed: time.Minute, expected: -1},
+	}
+	for _, tc := range testCases {
+		assert.Check(t, is.Equal(tc.expected, remainingStopTimeout(tc.stopTimeout, tc.hookTimeout, tc.elapsed)), "stop timeout %s, elapsed %s", tc.stopTimeout, tc.elapsed)
+	}
+}
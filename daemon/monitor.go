@@ -80,6 +80,8 @@ func (daemon *Daemon) ProcessEvent(id string, e libcontainerd.EventType, ei libc
 			// cancel healthcheck here, they will be automatically
 			// restarted if/when the container is started again
 			daemon.stopHealthchecks(c)
+			daemon.statsCollector.Untrack(c)
+			daemon.stopResourceMonitor(c)
 			attributes := map[string]string{
 				"exitCode": strconv.Itoa(int(ei.ExitCode)),
 			}
@@ -9,11 +9,20 @@ import (
 	volumestore "github.com/docker/docker/volume/store"
 )
 
+// prepareMountPoints initializes the volumes of a restored container. The
+// volumes of a container which is still running, with live-restore, are
+// told about its mounts.
 func (daemon *Daemon) prepareMountPoints(container *container.Container) error {
+	alive := container.IsRunning()
 	for _, config := range container.MountPoints {
 		if err := daemon.lazyInitializeVolume(container.ID, config); err != nil {
 			return err
 		}
+		if alive {
+			if err := config.LiveRestore(); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
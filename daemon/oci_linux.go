@@ -338,6 +338,13 @@ func setNamespaces(daemon *Daemon, s *specs.Spec, c *container.Container) error
 		return fmt.Errorf("Invalid IPC mode: %v", ipcMode)
 	}
 
+	// cgroup
+	if c.HostConfig.CgroupnsMode.IsPrivate() {
+		setNamespace(s, specs.LinuxNamespace{Type: specs.CgroupNamespace})
+	} else {
+		oci.RemoveNamespace(s, specs.CgroupNamespace)
+	}
+
 	// pid
 	if c.HostConfig.PidMode.IsContainer() {
 		ns := specs.LinuxNamespace{Type: "pid"}
@@ -880,7 +887,17 @@ func (daemon *Daemon) createSpec(c *container.Container) (retSpec *specs.Spec, e
 		}
 	}
 
-	if apparmor.IsEnabled() {
+	if daemon.cgroupUnified {
+		if s.Hooks == nil {
+			s.Hooks = &specs.Hooks{}
+		}
+		s.Hooks.Prestart = append(s.Hooks.Prestart, specs.Hook{
+			Path: filepath.Join("/proc", strconv.Itoa(os.Getpid()), "exe"),
+			Args: []string{cgroup2ResourcesHook},
+		})
+	}
+
+	if apparmor.IsEnabled() && daemon.apparmorEnabled {
 		var appArmorProfile string
 		if c.AppArmorProfile != "" {
 			appArmorProfile = c.AppArmorProfile
@@ -909,6 +926,10 @@ func (daemon *Daemon) createSpec(c *container.Container) (retSpec *specs.Spec, e
 	s.Process.OOMScoreAdj = &c.HostConfig.OomScoreAdj
 	s.Linux.MountLabel = c.MountLabel
 
+	if daemon.configStore.Rootless {
+		daemon.toRootlessSpec(&s)
+	}
+
 	return &s, nil
 }
 
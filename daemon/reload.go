@@ -6,6 +6,7 @@ import (
 
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/discovery"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,6 +23,7 @@ import (
 // - Insecure registries
 // - Registry mirrors
 // - Daemon live restore
+// - Authorization policy
 func (daemon *Daemon) Reload(conf *config.Config) (err error) {
 	daemon.configStore.Lock()
 	attributes := map[string]string{}
@@ -64,6 +66,9 @@ func (daemon *Daemon) Reload(conf *config.Config) (err error) {
 	if err := daemon.reloadLiveRestore(conf, attributes); err != nil {
 		return err
 	}
+	if err := daemon.reloadAuthorizationPolicy(conf, attributes); err != nil {
+		return err
+	}
 	return daemon.reloadNetworkDiagnosticPort(conf, attributes)
 }
 
@@ -306,6 +311,36 @@ func (daemon *Daemon) reloadLiveRestore(conf *config.Config, attributes map[stri
 	return nil
 }
 
+// reloadAuthorizationPolicy replaces the built-in authorization policy and
+// updates the passed attributes. The policy is removed if neither the
+// configuration file nor the --authorization-policy flag sets it anymore. An
+// empty policy allows all the requests.
+func (daemon *Daemon) reloadAuthorizationPolicy(conf *config.Config, attributes map[string]string) error {
+	if conf.AuthorizationPolicy != nil {
+		p, err := authorization.NewPolicy(*conf.AuthorizationPolicy)
+		if err != nil {
+			return err
+		}
+		daemon.configStore.AuthorizationPolicy = conf.AuthorizationPolicy
+		if daemon.configStore.AuthzMiddleware != nil {
+			daemon.configStore.AuthzMiddleware.SetPolicy(p)
+		}
+	} else {
+		daemon.configStore.AuthorizationPolicy = nil
+		if daemon.configStore.AuthzMiddleware != nil {
+			daemon.configStore.AuthzMiddleware.SetPolicy(nil)
+		}
+	}
+
+	// prepare reload event attributes with updatable configurations
+	rules := 0
+	if daemon.configStore.AuthorizationPolicy != nil {
+		rules = len(daemon.configStore.AuthorizationPolicy.Rules)
+	}
+	attributes["authorization-policy-rules"] = fmt.Sprintf("%d", rules)
+	return nil
+}
+
 // reloadNetworkDiagnosticPort updates the network controller starting the diagnostic if the config is valid
 func (daemon *Daemon) reloadNetworkDiagnosticPort(conf *config.Config, attributes map[string]string) error {
 	if conf == nil || daemon.netController == nil || !conf.IsValueSet("network-diagnostic-port") ||
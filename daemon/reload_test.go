@@ -1,13 +1,18 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/images"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/discovery"
 	_ "github.com/docker/docker/pkg/discovery/memory"
 	"github.com/docker/docker/registry"
@@ -567,3 +572,59 @@ func TestDaemonReloadNetworkDiagnosticPort(t *testing.T) {
 	}
 
 }
+
+func TestDaemonReloadAuthorizationPolicy(t *testing.T) {
+	middleware := authorization.NewMiddleware(nil, nil)
+	daemon := &Daemon{
+		configStore: &config.Config{
+			CommonConfig: config.CommonConfig{
+				AuthzMiddleware: middleware,
+			},
+		},
+		imageService: images.NewImageService(images.ImageServiceConfig{}),
+	}
+
+	valuesSets := make(map[string]interface{})
+	valuesSets["authorization-policy"] = map[string]interface{}{}
+	newConfig := &config.Config{
+		CommonConfig: config.CommonConfig{
+			AuthorizationPolicy: &authorization.PolicyConfig{
+				Rules: []authorization.PolicyRule{{Action: authorization.PolicyDeny, Reason: "no access"}},
+			},
+			ValuesSet: valuesSets,
+		},
+	}
+	if err := daemon.Reload(newConfig); err != nil {
+		t.Fatal(err)
+	}
+	if daemon.configStore.AuthorizationPolicy != newConfig.AuthorizationPolicy {
+		t.Fatal("Expected the authorization policy to be reloaded")
+	}
+
+	var called bool
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		called = true
+		return nil
+	}
+	req := httptest.NewRequest("GET", "/v1.37/info", nil)
+	err := middleware.WrapHandler(handler)(context.Background(), httptest.NewRecorder(), req, nil)
+	if err == nil || !strings.Contains(err.Error(), "no access") || called {
+		t.Fatalf("Expected the request to be denied by the reloaded policy, got %v", err)
+	}
+
+	newConfig.AuthorizationPolicy.Rules[0].Action = "block"
+	if err := daemon.Reload(newConfig); err == nil {
+		t.Fatal("Expected an error reloading an invalid authorization policy")
+	}
+
+	// removing the policy from the configuration allows the requests again
+	if err := daemon.Reload(&config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if daemon.configStore.AuthorizationPolicy != nil {
+		t.Fatal("Expected the authorization policy to be removed")
+	}
+	if err := middleware.WrapHandler(handler)(context.Background(), httptest.NewRecorder(), req, nil); err != nil || !called {
+		t.Fatalf("Expected the request to be allowed once the policy is removed, got %v", err)
+	}
+}
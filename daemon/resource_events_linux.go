@@ -0,0 +1,450 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// resourceEventsInterval is the interval between checks of the resource
+	// usage of containers, for the thresholds which have no cgroup
+	// notification.
+	resourceEventsInterval = time.Second
+
+	// pressureWindow is the window over which the stall time of a pressure
+	// trigger is measured.
+	pressureWindow = 10 * time.Second
+)
+
+// resourceThresholds are the thresholds of the resource events, in percent.
+// A zero threshold disables the event.
+type resourceThresholds struct {
+	memory    int
+	pressure  map[string]float64
+	throttled int
+}
+
+func (t resourceThresholds) enabled() bool {
+	return t.memory > 0 || len(t.pressure) > 0 || t.throttled > 0
+}
+
+// resourceThresholds returns the thresholds of the resource events set in
+// the daemon configuration.
+func (daemon *Daemon) resourceThresholds() resourceThresholds {
+	conf := daemon.configStore
+	if conf == nil {
+		return resourceThresholds{}
+	}
+	// The thresholds are validated when the configuration is loaded.
+	pressure, _ := conf.PressureThresholds()
+	for resource, threshold := range pressure {
+		if threshold == 0 {
+			delete(pressure, resource)
+		}
+	}
+	return resourceThresholds{
+		memory:    conf.MemoryEventThreshold,
+		pressure:  pressure,
+		throttled: conf.ThrottledEventThreshold,
+	}
+}
+
+// startResourceMonitor starts watching the resource usage of the running
+// container c, to log events when it exceeds the thresholds set in the
+// daemon configuration. The monitor runs until stopResourceMonitor is
+// called, or the cgroup of the container is removed.
+func (daemon *Daemon) startResourceMonitor(c *container.Container) {
+	thresholds := daemon.resourceThresholds()
+	if !thresholds.enabled() {
+		return
+	}
+
+	daemon.resourceMonitorsMu.Lock()
+	defer daemon.resourceMonitorsMu.Unlock()
+	if _, exists := daemon.resourceMonitors[c.ID]; exists {
+		return
+	}
+	if daemon.resourceMonitors == nil {
+		daemon.resourceMonitors = make(map[string]chan struct{})
+	}
+	stop := make(chan struct{})
+	daemon.resourceMonitors[c.ID] = stop
+
+	m := &resourceMonitor{
+		daemon:     daemon,
+		c:          c,
+		pid:        c.State.Pid,
+		thresholds: thresholds,
+		stop:       stop,
+	}
+	go m.run()
+}
+
+// stopResourceMonitor stops watching the resource usage of the container c.
+func (daemon *Daemon) stopResourceMonitor(c *container.Container) {
+	daemon.resourceMonitorsMu.Lock()
+	defer daemon.resourceMonitorsMu.Unlock()
+	if stop, exists := daemon.resourceMonitors[c.ID]; exists {
+		close(stop)
+		delete(daemon.resourceMonitors, c.ID)
+	}
+}
+
+// resourceMonitor watches the cgroup of a container. The memory threshold
+// is notified through an eventfd with cgroup v1, and the pressure thresholds
+// through PSI triggers with cgroup v2. The other thresholds are polled.
+type resourceMonitor struct {
+	daemon     *Daemon
+	c          *container.Container
+	pid        int
+	thresholds resourceThresholds
+	stop       chan struct{}
+}
+
+func (m *resourceMonitor) logger() *logrus.Entry {
+	return logrus.WithField("container", m.c.ID)
+}
+
+func (m *resourceMonitor) run() {
+	var memoryDir, cpuDir string
+	if m.daemon.cgroupUnified {
+		dir, err := cgroup2Path(m.pid)
+		if err != nil {
+			m.logger().WithError(err).Debug("Failed to find cgroup of container for resource events")
+			return
+		}
+		cpuDir = dir
+		if m.thresholds.memory > 0 {
+			// The memory controller may not be enabled in the cgroup of the
+			// container, in which case only the memory check is skipped.
+			if hasCgroup2Memory(dir) {
+				memoryDir = dir
+			} else {
+				m.logger().Debug("Memory events require the memory controller of cgroup v2")
+			}
+		}
+		for resource, threshold := range m.thresholds.pressure {
+			go m.watchPressure(filepath.Join(dir, resource+".pressure"), resource, threshold)
+		}
+	} else {
+		if len(m.thresholds.pressure) > 0 {
+			m.logger().Debug("Pressure events require cgroup v2")
+		}
+		var err error
+		if memoryDir, err = cgroupV1Path(m.pid, "memory"); err != nil {
+			m.logger().WithError(err).Debug("Failed to find memory cgroup of container for resource events")
+		}
+		if cpuDir, err = cgroupV1Path(m.pid, "cpu"); err != nil {
+			m.logger().WithError(err).Debug("Failed to find cpu cgroup of container for resource events")
+		}
+		if m.thresholds.memory > 0 && memoryDir != "" {
+			if err := m.watchMemoryV1(memoryDir); err != nil {
+				m.logger().WithError(err).Warn("Failed to register memory threshold notification")
+			}
+		}
+	}
+
+	ticker := time.NewTicker(resourceEventsInterval)
+	defer ticker.Stop()
+	var (
+		memoryExceeded    bool
+		throttledExceeded bool
+		prevCPU           map[string]uint64
+	)
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if m.daemon.cgroupUnified && m.thresholds.memory > 0 && memoryDir != "" {
+			exceeded, err := m.checkMemory(memoryDir, memoryExceeded)
+			if os.IsNotExist(err) {
+				return
+			}
+			memoryExceeded = exceeded
+		}
+
+		if m.thresholds.throttled > 0 && cpuDir != "" {
+			cpu, err := readCgroup2KeyValues(filepath.Join(cpuDir, "cpu.stat"))
+			if err != nil {
+				if os.IsNotExist(err) {
+					return
+				}
+				continue
+			}
+			if ratio, ok := throttledRatio(prevCPU, cpu); ok {
+				exceeded := ratio >= float64(m.thresholds.throttled)
+				if exceeded && !throttledExceeded {
+					m.daemon.LogContainerEventWithAttributes(m.c, "throttled", map[string]string{
+						"ratio":     strconv.FormatFloat(ratio, 'f', 1, 64),
+						"threshold": strconv.Itoa(m.thresholds.throttled),
+					})
+				}
+				throttledExceeded = exceeded
+			}
+			prevCPU = cpu
+		}
+	}
+}
+
+// hasCgroup2Memory returns whether the memory controller is enabled in the
+// cgroup v2 dir.
+func hasCgroup2Memory(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "memory.current"))
+	return err == nil
+}
+
+// checkMemory compares the memory usage of the cgroup v2 dir to the memory
+// threshold, and logs a memory_threshold event when it goes above it.
+func (m *resourceMonitor) checkMemory(dir string, exceeded bool) (bool, error) {
+	usage, err := readCgroup2Uint(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return exceeded, err
+	}
+	limit, err := readCgroup2Uint(filepath.Join(dir, "memory.max"))
+	if err != nil {
+		return exceeded, err
+	}
+	limit = effectiveMemoryLimit(limit, m.daemon.machineMemory)
+	above := limit > 0 && usage >= memoryThreshold(limit, m.thresholds.memory)
+	if above && !exceeded {
+		m.logMemoryEvent(usage, limit)
+	}
+	return above, nil
+}
+
+func (m *resourceMonitor) logMemoryEvent(usage, limit uint64) {
+	m.daemon.LogContainerEventWithAttributes(m.c, "memory_threshold", map[string]string{
+		"usage":     strconv.FormatUint(usage, 10),
+		"limit":     strconv.FormatUint(limit, 10),
+		"threshold": strconv.Itoa(m.thresholds.memory),
+	})
+}
+
+// watchMemoryV1 registers an eventfd notified when the memory usage of the
+// cgroup v1 dir crosses the memory threshold. The threshold is computed from
+// the memory limit of the container when the monitor starts.
+func (m *resourceMonitor) watchMemoryV1(dir string) error {
+	limit, err := readCgroup2Uint(filepath.Join(dir, "memory.limit_in_bytes"))
+	if err != nil {
+		return err
+	}
+	limit = effectiveMemoryLimit(limit, m.daemon.machineMemory)
+	if limit == 0 {
+		return nil
+	}
+	threshold := memoryThreshold(limit, m.thresholds.memory)
+
+	usagePath := filepath.Join(dir, "memory.usage_in_bytes")
+	usage, err := os.Open(usagePath)
+	if err != nil {
+		return err
+	}
+	efd, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	if err != nil {
+		usage.Close()
+		return errors.Wrap(err, "failed to create eventfd")
+	}
+	control := fmt.Sprintf("%d %d %d", efd, usage.Fd(), threshold)
+	if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.event_control"), []byte(control), 0); err != nil {
+		unix.Close(efd)
+		usage.Close()
+		return err
+	}
+
+	// The eventfd is only used by this goroutine, which closes it once the
+	// monitor is stopped.
+	go func() {
+		defer unix.Close(efd)
+		defer usage.Close()
+		for waitEventfd(efd, m.stop) {
+			// The eventfd is also notified when the cgroup is removed.
+			if _, err := os.Stat(filepath.Join(dir, "cgroup.event_control")); os.IsNotExist(err) {
+				return
+			}
+			// The eventfd is notified when the threshold is crossed in
+			// either direction.
+			current, err := readCgroup2Uint(usagePath)
+			if err == nil && current >= threshold {
+				m.logMemoryEvent(current, limit)
+			}
+		}
+	}()
+	return nil
+}
+
+// waitEventfd waits until the eventfd is notified, and resets its counter.
+// It returns false if stop is closed first, or if the eventfd can't be read.
+// The stop channel is checked every resourceEventsInterval.
+func waitEventfd(efd int, stop <-chan struct{}) bool {
+	fds := []unix.PollFd{{Fd: int32(efd), Events: unix.POLLIN}}
+	buf := make([]byte, 8)
+	for {
+		select {
+		case <-stop:
+			return false
+		default:
+		}
+		n, err := unix.Poll(fds, int(resourceEventsInterval/time.Millisecond))
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return false
+		}
+		if n == 0 {
+			continue
+		}
+		if _, err := unix.Read(efd, buf); err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return false
+		}
+		return true
+	}
+}
+
+// watchPressure registers a PSI trigger on the pressure file path of a cgroup
+// v2, and logs a pressure event each time it fires. The trigger fires at
+// most once per pressureWindow.
+func (m *resourceMonitor) watchPressure(path, resource string, threshold float64) {
+	logger := m.logger().WithField("resource", resource)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		logger.WithError(err).Debug("Failed to open pressure file of container")
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte(pressureTrigger(threshold))); err != nil {
+		logger.WithError(err).Warn("Failed to register pressure trigger")
+		return
+	}
+
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLPRI}}
+	for {
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		n, err := unix.Poll(fds, int(resourceEventsInterval/time.Millisecond))
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			logger.WithError(err).Warn("Failed to poll pressure trigger")
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		if fds[0].Revents&unix.POLLERR != 0 {
+			// The cgroup was removed.
+			return
+		}
+		if fds[0].Revents&unix.POLLPRI != 0 {
+			attributes := map[string]string{
+				"resource":  resource,
+				"threshold": strconv.FormatFloat(threshold, 'f', -1, 64),
+			}
+			if content, err := ioutil.ReadFile(path); err == nil {
+				if avg10, ok := pressureAvg10(content); ok {
+					attributes["avg10"] = avg10
+				}
+			}
+			m.daemon.LogContainerEventWithAttributes(m.c, "pressure", attributes)
+		}
+	}
+}
+
+// cgroupV1Path returns the directory of the cgroup v1 of the process pid for
+// the subsystem.
+func cgroupV1Path(pid int, subsystem string) (string, error) {
+	cg, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	p, ok := cg[subsystem]
+	if !ok {
+		return "", errors.Errorf("process %d is not in a %s cgroup", pid, subsystem)
+	}
+	mnt, root, err := cgroups.FindCgroupMountpointAndRoot(subsystem)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mnt, rel), nil
+}
+
+// effectiveMemoryLimit returns the memory limit of a container, which is the
+// memory of the machine if the container has no lower limit.
+func effectiveMemoryLimit(limit, machineMemory uint64) uint64 {
+	if limit == 0 || (machineMemory > 0 && limit > machineMemory) {
+		return machineMemory
+	}
+	return limit
+}
+
+// memoryThreshold returns percent of the memory limit, in bytes.
+func memoryThreshold(limit uint64, percent int) uint64 {
+	return limit / 100 * uint64(percent)
+}
+
+// throttledRatio returns the percentage of the CPU periods which were
+// throttled between the cpu.stat values prev and cur.
+func throttledRatio(prev, cur map[string]uint64) (float64, bool) {
+	if prev == nil || cur["nr_periods"] <= prev["nr_periods"] || cur["nr_throttled"] < prev["nr_throttled"] {
+		return 0, false
+	}
+	periods := cur["nr_periods"] - prev["nr_periods"]
+	throttled := cur["nr_throttled"] - prev["nr_throttled"]
+	return float64(throttled) / float64(periods) * 100, true
+}
+
+// pressureTrigger returns the PSI trigger firing when some tasks are stalled
+// for percent of pressureWindow, see the PSI documentation of the kernel.
+func pressureTrigger(percent float64) string {
+	window := int64(pressureWindow / time.Microsecond)
+	stall := int64(float64(window) * percent / 100)
+	if stall < 1 {
+		stall = 1
+	}
+	return fmt.Sprintf("some %d %d", stall, window)
+}
+
+// pressureAvg10 returns the share of time some tasks were stalled over the
+// last 10 seconds, from the content of a pressure file.
+func pressureAvg10(content []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "some" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "avg10=") {
+				return strings.TrimPrefix(f, "avg10="), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/fs"
+	"golang.org/x/sys/unix"
+)
+
+func TestResourceEventThresholds(t *testing.T) {
+	assert.Check(t, is.Equal(uint64(1000), effectiveMemoryLimit(0, 1000)))
+	assert.Check(t, is.Equal(uint64(1000), effectiveMemoryLimit(1<<62, 1000)))
+	assert.Check(t, is.Equal(uint64(500), effectiveMemoryLimit(500, 1000)))
+	assert.Check(t, is.Equal(uint64(900), memoryThreshold(1000, 90)))
+
+	assert.Check(t, is.Equal("some 1500000 10000000", pressureTrigger(15)))
+	assert.Check(t, is.Equal("some 1 10000000", pressureTrigger(0.000001)))
+}
+
+func TestThrottledRatio(t *testing.T) {
+	_, ok := throttledRatio(nil, map[string]uint64{"nr_periods": 10})
+	assert.Check(t, !ok)
+
+	prev := map[string]uint64{"nr_periods": 100, "nr_throttled": 10}
+	ratio, ok := throttledRatio(prev, map[string]uint64{"nr_periods": 110, "nr_throttled": 18})
+	assert.Check(t, ok)
+	assert.Check(t, is.Equal(float64(80), ratio))
+
+	// No period elapsed while the container was idle.
+	_, ok = throttledRatio(prev, prev)
+	assert.Check(t, !ok)
+}
+
+func TestHasCgroup2Memory(t *testing.T) {
+	dir := fs.NewDir(t, "cgroup", fs.WithFile("cpu.stat", "nr_periods 0\n"))
+	defer dir.Remove()
+	assert.Check(t, !hasCgroup2Memory(dir.Path()))
+
+	withMemory := fs.NewDir(t, "cgroup", fs.WithFile("cpu.stat", "nr_periods 0\n"), fs.WithFile("memory.current", "4096\n"))
+	defer withMemory.Remove()
+	assert.Check(t, hasCgroup2Memory(withMemory.Path()))
+}
+
+func TestPressureAvg10(t *testing.T) {
+	content := []byte("some avg10=12.50 avg60=3.10 avg300=0.80 total=123456\nfull avg10=1.00 avg60=0.20 avg300=0.00 total=2345\n")
+	avg10, ok := pressureAvg10(content)
+	assert.Check(t, ok)
+	assert.Check(t, is.Equal("12.50", avg10))
+
+	_, ok = pressureAvg10([]byte("full avg10=1.00\n"))
+	assert.Check(t, !ok)
+}
+
+func TestWaitEventfd(t *testing.T) {
+	efd, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	assert.NilError(t, err)
+	defer unix.Close(efd)
+
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, 1)
+	_, err = unix.Write(efd, buf)
+	assert.NilError(t, err)
+	stop := make(chan struct{})
+	assert.Check(t, waitEventfd(efd, stop))
+
+	// the monitor is stopped without writing to the eventfd
+	done := make(chan bool)
+	go func() {
+		done <- waitEventfd(efd, stop)
+	}()
+	close(stop)
+	select {
+	case notified := <-done:
+		assert.Check(t, !notified)
+	case <-time.After(5 * resourceEventsInterval):
+		t.Fatal("waitEventfd did not return after the monitor was stopped")
+	}
+}
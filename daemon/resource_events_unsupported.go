@@ -0,0 +1,11 @@
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import "github.com/docker/docker/container"
+
+func (daemon *Daemon) startResourceMonitor(c *container.Container) {
+}
+
+func (daemon *Daemon) stopResourceMonitor(c *container.Container) {
+}
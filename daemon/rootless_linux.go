@@ -0,0 +1,77 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// defaultCgroupParent is the cgroup the containers are created in when no
+// cgroup parent is configured.
+const defaultCgroupParent = "/docker"
+
+// getSysInfo returns the features supported by the host. In rootless mode,
+// AppArmor is reported as unsupported as the daemon is not allowed to load
+// profiles, and so are the cgroup controllers if the cgroups have not been
+// delegated to the daemon, so that the resource limits of the containers are
+// discarded with a warning instead of failing their start.
+func getSysInfo(conf *config.Config, quiet bool) *sysinfo.SysInfo {
+	sysInfo := sysinfo.New(quiet)
+	if !conf.Rootless {
+		return sysInfo
+	}
+	sysInfo.AppArmor = false
+
+	parent := conf.CgroupParent
+	if parent == "" {
+		parent = defaultCgroupParent
+	}
+	if !cgroupWritable(parent, sysInfo.CgroupUnified) {
+		if !quiet {
+			logrus.Warn("Running in rootless mode without cgroups. To enable cgroups in rootless mode, delegate the cgroup hierarchy to the user running the daemon")
+		}
+		sysInfo.ClearCgroupControllers()
+	}
+	return sysInfo
+}
+
+// cgroupWritable reports whether the daemon can create the cgroup at path,
+// relative to the root of the memory hierarchy on cgroup v1, or of the
+// unified hierarchy on cgroup v2. That is the case if the cgroup, or its
+// closest existing ancestor, is writable.
+func cgroupWritable(path string, unified bool) bool {
+	root := cgroup2Mountpoint
+	if !unified {
+		var err error
+		if root, err = cgroups.FindCgroupMountpoint("memory"); err != nil {
+			return false
+		}
+	}
+	dir := filepath.Join(root, filepath.Clean("/"+path))
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return unix.Access(dir, unix.W_OK) == nil
+		}
+		if dir == root {
+			return false
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// toRootlessSpec adjusts the spec of a container created by a rootless
+// daemon: the cgroup settings are removed if the daemon cannot create the
+// cgroup of the container.
+func (daemon *Daemon) toRootlessSpec(s *specs.Spec) {
+	if !cgroupWritable(filepath.Dir(s.Linux.CgroupsPath), daemon.cgroupUnified) {
+		logrus.Debugf("cgroup %s is not writable, ignoring the resources of the container", s.Linux.CgroupsPath)
+		s.Linux.CgroupsPath = ""
+		s.Linux.Resources = nil
+	}
+}
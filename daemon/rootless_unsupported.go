@@ -0,0 +1,13 @@
+// +build !linux
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/pkg/sysinfo"
+)
+
+// getSysInfo returns the features supported by the host.
+func getSysInfo(conf *config.Config, quiet bool) *sysinfo.SysInfo {
+	return sysinfo.New(quiet)
+}
@@ -92,6 +92,9 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 			return errdefs.InvalidParameter(err)
 		}
 	}
+	if err := daemon.waitForDependencies(container, false); err != nil {
+		return err
+	}
 	return daemon.containerStart(container, checkpoint, checkpointDir, true)
 }
 
@@ -199,6 +202,8 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 	daemon.setStateCounter(container)
 
 	daemon.initHealthMonitor(container)
+	daemon.statsCollector.Track(container)
+	daemon.startResourceMonitor(container)
 
 	if err := container.CheckpointTo(daemon.containersReplica); err != nil {
 		logrus.WithError(err).WithField("container", container.ID).
@@ -208,6 +213,8 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 	daemon.LogContainerEvent(container, "start")
 	containerActions.WithValues("start").UpdateSince(start)
 
+	daemon.runPostStartHook(container)
+
 	return nil
 }
 
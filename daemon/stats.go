@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/backend"
+	timetypes "github.com/docker/docker/api/types/time"
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/api/types/versions/v1p20"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/ioutils"
 )
 
@@ -129,6 +132,207 @@ func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, c
 	}
 }
 
+// containerStatsSample is a sample of the stats of a container, received
+// by ContainersStats.
+type containerStatsSample struct {
+	id    string
+	stats types.StatsJSON
+}
+
+// containerStatsSubscription is the subscription of ContainersStats to the
+// stats of a container.
+type containerStatsSubscription struct {
+	container   *container.Container
+	updates     chan interface{}
+	preCPUStats types.CPUStats
+	preRead     time.Time
+	latest      *types.StatsJSON
+}
+
+// ContainersStats writes the stats of the containers matching the filters
+// given in the config object to the stream, in one document per interval of
+// the stats collector. Containers join and leave the stream as they start
+// and stop, or start and stop matching the filters.
+func (daemon *Daemon) ContainersStats(ctx context.Context, config *backend.ContainersStatsConfig) error {
+	listOptions := &types.ContainerListOptions{
+		All:     config.All,
+		Filters: config.Filters,
+	}
+	// List the containers once before the stream starts, so that invalid
+	// filters are reported with the appropriate status code.
+	if _, err := daemon.Containers(listOptions); err != nil {
+		return err
+	}
+
+	outStream := config.OutStream
+	if config.Stream {
+		wf := ioutils.NewWriteFlusher(outStream)
+		defer wf.Close()
+		wf.Flush()
+		outStream = wf
+	}
+	enc := json.NewEncoder(outStream)
+
+	done := make(chan struct{})
+	defer close(done)
+	samples := make(chan containerStatsSample)
+	subscriptions := make(map[string]*containerStatsSubscription)
+	defer func() {
+		for _, sub := range subscriptions {
+			daemon.unsubscribeToContainerStats(sub.container, sub.updates)
+		}
+	}()
+
+	forward := func(id string, updates chan interface{}) {
+		for v := range updates {
+			select {
+			case samples <- containerStatsSample{id: id, stats: v.(types.StatsJSON)}:
+			case <-done:
+				return
+			}
+		}
+	}
+
+	// refresh subscribes to the containers which started matching the
+	// filters, and unsubscribes from the ones which stopped matching them.
+	refresh := func() error {
+		containers, err := daemon.Containers(listOptions)
+		if err != nil {
+			return err
+		}
+		matching := make(map[string]bool, len(containers))
+		for _, c := range containers {
+			matching[c.ID] = true
+			if _, exists := subscriptions[c.ID]; exists {
+				continue
+			}
+			ctr, err := daemon.GetContainer(c.ID)
+			if err != nil {
+				continue
+			}
+			sub := &containerStatsSubscription{
+				container: ctr,
+				updates:   daemon.subscribeToContainerStats(ctr),
+			}
+			subscriptions[c.ID] = sub
+			go forward(c.ID, sub.updates)
+		}
+		for id, sub := range subscriptions {
+			if !matching[id] {
+				daemon.unsubscribeToContainerStats(sub.container, sub.updates)
+				delete(subscriptions, id)
+			}
+		}
+		return nil
+	}
+	if err := refresh(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(daemon.statsCollector.Interval())
+	defer ticker.Stop()
+
+	noStreamFirstFrame := true
+	for {
+		select {
+		case s := <-samples:
+			sub, exists := subscriptions[s.id]
+			if !exists {
+				continue
+			}
+			ss := s.stats
+			ss.Name = sub.container.Name
+			ss.ID = sub.container.ID
+			ss.PreCPUStats = sub.preCPUStats
+			ss.PreRead = sub.preRead
+			sub.preCPUStats = ss.CPUStats
+			sub.preRead = ss.Read
+			sub.latest = &ss
+		case <-ticker.C:
+			if !config.Stream && noStreamFirstFrame {
+				// prime the cpu stats so they aren't 0 in the final output
+				noStreamFirstFrame = false
+				continue
+			}
+
+			doc := types.ContainersStatsJSON{
+				Read:  time.Now(),
+				Stats: []types.StatsJSON{},
+			}
+			for _, sub := range subscriptions {
+				if sub.latest != nil {
+					doc.Stats = append(doc.Stats, *sub.latest)
+				}
+			}
+			sort.Slice(doc.Stats, func(i, j int) bool {
+				return doc.Stats[i].Name < doc.Stats[j].Name
+			})
+			if err := enc.Encode(&doc); err != nil {
+				return err
+			}
+
+			if !config.Stream {
+				return nil
+			}
+			if err := refresh(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// ContainerStatsHistory returns the stats history of the container, if the
+// stats history is enabled on the daemon.
+func (daemon *Daemon) ContainerStatsHistory(name string, options types.ContainerStatsHistoryOptions) (*types.StatsHistory, error) {
+	history := daemon.statsCollector.History()
+	if history == nil {
+		return nil, errdefs.Unavailable(errors.New("stats history is not enabled on this daemon"))
+	}
+
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var since, until time.Time
+	if options.Since != "" {
+		s, n, err := timetypes.ParseTimestamps(options.Since, 0)
+		if err != nil {
+			return nil, errdefs.InvalidParameter(err)
+		}
+		since = time.Unix(s, n)
+	}
+	if options.Until != "" && options.Until != "0" {
+		s, n, err := timetypes.ParseTimestamps(options.Until, 0)
+		if err != nil {
+			return nil, errdefs.InvalidParameter(err)
+		}
+		until = time.Unix(s, n)
+	}
+	var resolution time.Duration
+	if options.Resolution != "" {
+		if resolution, err = time.ParseDuration(options.Resolution); err != nil {
+			return nil, errdefs.InvalidParameter(err)
+		}
+		if resolution < 0 {
+			return nil, errdefs.InvalidParameter(errors.New("resolution cannot be negative"))
+		}
+	}
+
+	samples, resolution := history.Get(container.ID, since, until, resolution)
+	if samples == nil {
+		samples = []types.StatsHistorySample{}
+	}
+	return &types.StatsHistory{
+		Name:       container.Name,
+		ID:         container.ID,
+		Resolution: resolution,
+		Samples:    samples,
+	}, nil
+}
+
 func (daemon *Daemon) subscribeToContainerStats(c *container.Container) chan interface{} {
 	return daemon.statsCollector.Collect(c)
 }
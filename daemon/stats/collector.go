@@ -19,6 +19,10 @@ type Collector struct {
 	publishers map[*container.Container]*pubsub.Publisher
 	bufReader  *bufio.Reader
 
+	// history keeps the stats of the tracked containers, if enabled.
+	history *History
+	tracked map[*container.Container]bool
+
 	// The following fields are not set on Windows currently.
 	clockTicksPerSecond uint64
 }
@@ -30,6 +34,7 @@ func NewCollector(supervisor supervisor, interval time.Duration) *Collector {
 		supervisor: supervisor,
 		publishers: make(map[*container.Container]*pubsub.Publisher),
 		bufReader:  bufio.NewReaderSize(nil, 128),
+		tracked:    make(map[*container.Container]bool),
 	}
 
 	platformNewStatsCollector(s)
@@ -56,6 +61,57 @@ func (s *Collector) Collect(c *container.Container) chan interface{} {
 	return publisher.Subscribe()
 }
 
+// Interval returns the interval at which the stats are collected.
+func (s *Collector) Interval() time.Duration {
+	return s.interval
+}
+
+// EnableHistory keeps the stats collected for the containers in h.
+func (s *Collector) EnableHistory(h *History) {
+	s.m.Lock()
+	s.history = h
+	s.m.Unlock()
+}
+
+// History returns the stats history of the collector, or nil if it is not
+// enabled.
+func (s *Collector) History() *History {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.history
+}
+
+// Track registers the container with the collector so that its stats are
+// collected for the history, even without subscribers, until Untrack or
+// StopCollection is called. It does nothing if the history is not enabled.
+func (s *Collector) Track(c *container.Container) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.history == nil {
+		return
+	}
+	if _, exists := s.publishers[c]; !exists {
+		s.publishers[c] = pubsub.NewPublisher(100*time.Millisecond, 1024)
+	}
+	s.tracked[c] = true
+}
+
+// Untrack stops collecting the stats of the container for the history. Its
+// history, including the stats of the current interval, is kept until
+// StopCollection is called.
+func (s *Collector) Untrack(c *container.Container) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.tracked[c] {
+		return
+	}
+	delete(s.tracked, c)
+	s.history.Flush(c.ID)
+	if publisher := s.publishers[c]; publisher != nil && publisher.Len() == 0 {
+		delete(s.publishers, c)
+	}
+}
+
 // StopCollection closes the channels for all subscribers and removes
 // the container from metrics collection.
 func (s *Collector) StopCollection(c *container.Container) {
@@ -64,6 +120,10 @@ func (s *Collector) StopCollection(c *container.Container) {
 		publisher.Close()
 		delete(s.publishers, c)
 	}
+	delete(s.tracked, c)
+	if s.history != nil {
+		s.history.Remove(c.ID)
+	}
 	s.m.Unlock()
 }
 
@@ -73,7 +133,7 @@ func (s *Collector) Unsubscribe(c *container.Container, ch chan interface{}) {
 	publisher := s.publishers[c]
 	if publisher != nil {
 		publisher.Evict(ch)
-		if publisher.Len() == 0 {
+		if publisher.Len() == 0 && !s.tracked[c] {
 			delete(s.publishers, c)
 		}
 	}
@@ -104,6 +164,7 @@ func (s *Collector) Run() {
 			// copy pointers here to release the lock ASAP
 			pairs = append(pairs, publishersPair{container, publisher})
 		}
+		history := s.history
 		s.m.Unlock()
 		if len(pairs) == 0 {
 			continue
@@ -133,6 +194,9 @@ func (s *Collector) Run() {
 				stats.CPUStats.OnlineCPUs = onlineCPUs
 
 				pair.publisher.Publish(*stats)
+				if history != nil {
+					history.Add(pair.container.ID, stats)
+				}
 
 			case notRunningErr, notFoundErr:
 				// publish empty stats containing only name and ID if not running or not found
@@ -0,0 +1,324 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/docker/docker/api/types"
+)
+
+// historyTier is a resolution of the stats history, and the time samples are
+// kept at this resolution.
+type historyTier struct {
+	resolution time.Duration
+	retention  time.Duration
+}
+
+// size returns the number of samples kept for the tier.
+func (t historyTier) size() int {
+	return int(t.retention / t.resolution)
+}
+
+// historyTiers are the tiers of the stats history, from the finest to the
+// coarsest resolution.
+var historyTiers = []historyTier{
+	{resolution: 10 * time.Second, retention: time.Hour},
+	{resolution: time.Minute, retention: 24 * time.Hour},
+}
+
+// historySampleSize is the memory used by a sample of the history.
+const historySampleSize = int64(unsafe.Sizeof(types.StatsHistorySample{}))
+
+// historyBucket accumulates the stats collected during an interval of a tier.
+type historyBucket struct {
+	sample   types.StatsHistorySample
+	cpuSum   float64
+	cpuCount int
+}
+
+func (b *historyBucket) add(s types.StatsHistorySample, cpuPercent float64, hasCPU bool) {
+	b.sample.MemoryUsage = s.MemoryUsage
+	if s.MemoryUsage > b.sample.MemoryMaxUsage {
+		b.sample.MemoryMaxUsage = s.MemoryUsage
+	}
+	b.sample.MemoryLimit = s.MemoryLimit
+	b.sample.NetworkRxBytes = s.NetworkRxBytes
+	b.sample.NetworkTxBytes = s.NetworkTxBytes
+	b.sample.BlockReadBytes = s.BlockReadBytes
+	b.sample.BlockWriteBytes = s.BlockWriteBytes
+	if hasCPU {
+		b.cpuSum += cpuPercent
+		b.cpuCount++
+	}
+}
+
+func (b *historyBucket) flush() types.StatsHistorySample {
+	if b.cpuCount > 0 {
+		b.sample.CPUPercent = b.cpuSum / float64(b.cpuCount)
+	}
+	return b.sample
+}
+
+// historyRing is a ring buffer of the samples of a tier.
+type historyRing struct {
+	tier    historyTier
+	samples []types.StatsHistorySample
+	next    int
+	bucket  *historyBucket
+}
+
+func (r *historyRing) add(s types.StatsHistorySample, cpuPercent float64, hasCPU bool) {
+	start := s.Time.Truncate(r.tier.resolution)
+	if r.bucket != nil && !r.bucket.sample.Time.Equal(start) {
+		r.push(r.bucket.flush())
+		r.bucket = nil
+	}
+	if r.bucket == nil {
+		r.bucket = &historyBucket{sample: types.StatsHistorySample{Time: start}}
+	}
+	r.bucket.add(s, cpuPercent, hasCPU)
+}
+
+// flush pushes the samples accumulated in the current interval to the ring.
+func (r *historyRing) flush() {
+	if r.bucket != nil {
+		r.push(r.bucket.flush())
+		r.bucket = nil
+	}
+}
+
+func (r *historyRing) push(s types.StatsHistorySample) {
+	if len(r.samples) < r.tier.size() {
+		r.samples = append(r.samples, s)
+		return
+	}
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+}
+
+// list returns the samples of the ring overlapping the interval [since,
+// until), from the oldest to the newest, including the samples accumulated
+// in the current interval. A zero until means now.
+func (r *historyRing) list(since, until time.Time) []types.StatsHistorySample {
+	ordered := append(append([]types.StatsHistorySample{}, r.samples[r.next:]...), r.samples[:r.next]...)
+	if r.bucket != nil {
+		ordered = append(ordered, r.bucket.flush())
+	}
+	var samples []types.StatsHistorySample
+	for _, s := range ordered {
+		if !s.Time.Add(r.tier.resolution).After(since) {
+			continue
+		}
+		if !until.IsZero() && !s.Time.Before(until) {
+			break
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// containerHistory is the stats history of a container.
+type containerHistory struct {
+	rings      []*historyRing
+	prevCPU    uint64
+	prevSystem uint64
+	updated    time.Time
+}
+
+func newContainerHistory() *containerHistory {
+	h := &containerHistory{}
+	for _, t := range historyTiers {
+		h.rings = append(h.rings, &historyRing{tier: t})
+	}
+	return h
+}
+
+// History keeps the stats of containers in memory, downsampled at the
+// resolutions of historyTiers.
+type History struct {
+	mu            sync.Mutex
+	maxContainers int
+	containers    map[string]*containerHistory
+}
+
+// NewHistory creates a stats history using at most maxMemory bytes for the
+// samples. The history of the least recently updated containers is dropped
+// to stay within this limit.
+func NewHistory(maxMemory int64) *History {
+	var containerMemory int64
+	for _, t := range historyTiers {
+		containerMemory += int64(t.size()) * historySampleSize
+	}
+	maxContainers := int(maxMemory / containerMemory)
+	if maxContainers < 1 {
+		maxContainers = 1
+	}
+	return &History{
+		maxContainers: maxContainers,
+		containers:    make(map[string]*containerHistory),
+	}
+}
+
+// Add adds the stats of the container id to its history.
+func (h *History) Add(id string, stats *types.StatsJSON) {
+	if stats.Read.IsZero() {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch, exists := h.containers[id]
+	if !exists {
+		if len(h.containers) >= h.maxContainers {
+			h.evict()
+		}
+		ch = newContainerHistory()
+		h.containers[id] = ch
+	}
+
+	var (
+		cpuPercent float64
+		hasCPU     bool
+	)
+	cpu, system := stats.CPUStats.CPUUsage.TotalUsage, stats.CPUStats.SystemUsage
+	if ch.prevSystem != 0 && system > ch.prevSystem && cpu >= ch.prevCPU {
+		onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+		if onlineCPUs == 0 {
+			onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+		}
+		cpuPercent = float64(cpu-ch.prevCPU) / float64(system-ch.prevSystem) * onlineCPUs * 100
+		hasCPU = true
+	}
+	ch.prevCPU, ch.prevSystem = cpu, system
+	ch.updated = stats.Read
+
+	sample := historySample(stats)
+	for _, r := range ch.rings {
+		r.add(sample, cpuPercent, hasCPU)
+	}
+}
+
+// evict drops the history of the least recently updated container.
+func (h *History) evict() {
+	var (
+		oldest  string
+		updated time.Time
+	)
+	for id, ch := range h.containers {
+		if oldest == "" || ch.updated.Before(updated) {
+			oldest, updated = id, ch.updated
+		}
+	}
+	delete(h.containers, oldest)
+}
+
+// Flush adds the stats collected in the current interval of each tier to the
+// history of the container id, when its stats are no longer collected.
+func (h *History) Flush(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ch, exists := h.containers[id]; exists {
+		for _, r := range ch.rings {
+			r.flush()
+		}
+	}
+}
+
+// Remove drops the history of the container id.
+func (h *History) Remove(id string) {
+	h.mu.Lock()
+	delete(h.containers, id)
+	h.mu.Unlock()
+}
+
+// Get returns the samples of the container id overlapping the interval
+// [since, until), and their resolution. The samples are taken from the
+// finest tier covering since, or the last hour if since is zero, and merged
+// if the requested resolution is coarser than the one of the tier.
+func (h *History) Get(id string, since, until time.Time, resolution time.Duration) ([]types.StatsHistorySample, time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	now := time.Now()
+	for i < len(historyTiers)-1 {
+		if since.IsZero() || !since.Before(now.Add(-historyTiers[i].retention)) {
+			break
+		}
+		i++
+	}
+	tier := historyTiers[i]
+	if resolution < tier.resolution {
+		resolution = tier.resolution
+	}
+
+	ch, exists := h.containers[id]
+	if !exists {
+		return nil, resolution
+	}
+	samples := ch.rings[i].list(since, until)
+	if resolution == tier.resolution {
+		return samples, resolution
+	}
+	return downsample(samples, resolution), resolution
+}
+
+// downsample merges the consecutive samples falling in the same interval of
+// the given resolution.
+func downsample(samples []types.StatsHistorySample, resolution time.Duration) []types.StatsHistorySample {
+	var (
+		merged []types.StatsHistorySample
+		bucket *historyBucket
+	)
+	for _, s := range samples {
+		start := s.Time.Truncate(resolution)
+		if bucket != nil && !bucket.sample.Time.Equal(start) {
+			merged = append(merged, bucket.flush())
+			bucket = nil
+		}
+		if bucket == nil {
+			bucket = &historyBucket{sample: types.StatsHistorySample{Time: start}}
+		}
+		if s.MemoryMaxUsage > bucket.sample.MemoryMaxUsage {
+			bucket.sample.MemoryMaxUsage = s.MemoryMaxUsage
+		}
+		bucket.add(s, s.CPUPercent, true)
+	}
+	if bucket != nil {
+		merged = append(merged, bucket.flush())
+	}
+	return merged
+}
+
+// historySample extracts the values kept in the history from stats.
+func historySample(stats *types.StatsJSON) types.StatsHistorySample {
+	s := types.StatsHistorySample{
+		Time:        stats.Read,
+		MemoryUsage: stats.MemoryStats.Usage,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+	// The private working set is the closest to the memory usage on
+	// Windows.
+	if s.MemoryUsage == 0 {
+		s.MemoryUsage = stats.MemoryStats.PrivateWorkingSet
+	}
+	for _, n := range stats.Networks {
+		s.NetworkRxBytes += n.RxBytes
+		s.NetworkTxBytes += n.TxBytes
+	}
+	for _, e := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			s.BlockReadBytes += e.Value
+		case "write":
+			s.BlockWriteBytes += e.Value
+		}
+	}
+	if len(stats.BlkioStats.IoServiceBytesRecursive) == 0 {
+		s.BlockReadBytes = stats.StorageStats.ReadSizeBytes
+		s.BlockWriteBytes = stats.StorageStats.WriteSizeBytes
+	}
+	return s
+}
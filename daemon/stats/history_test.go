@@ -0,0 +1,135 @@
+package stats // import "github.com/docker/docker/daemon/stats"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func historyStats(read time.Time, cpu, system, memory uint64) *types.StatsJSON {
+	s := &types.StatsJSON{}
+	s.Read = read
+	s.CPUStats.CPUUsage.TotalUsage = cpu
+	s.CPUStats.SystemUsage = system
+	s.CPUStats.OnlineCPUs = 2
+	s.MemoryStats.Usage = memory
+	s.Networks = map[string]types.NetworkStats{
+		"eth0": {RxBytes: memory, TxBytes: 1},
+		"eth1": {RxBytes: 1, TxBytes: 1},
+	}
+	s.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "Read", Value: 10},
+		{Op: "Write", Value: 20},
+		{Op: "Total", Value: 30},
+	}
+	return s
+}
+
+func TestHistoryDownsampling(t *testing.T) {
+	h := NewHistory(1 << 30)
+	start := time.Now().Add(-30 * time.Minute).Truncate(time.Minute)
+
+	// The container uses half a CPU, and 1024 bytes of memory except for a
+	// peak in the second interval of 10s.
+	for i := 0; i <= 30; i++ {
+		memory := uint64(1024)
+		if i == 15 {
+			memory = 4096
+		}
+		h.Add("id", historyStats(start.Add(time.Duration(i)*time.Second), uint64(i)*1e9, uint64(i)*4e9, memory))
+	}
+
+	samples, resolution := h.Get("id", time.Time{}, time.Time{}, 0)
+	assert.Check(t, is.Equal(10*time.Second, resolution))
+	// The last sample is the current interval, which is not complete yet.
+	assert.Assert(t, is.Len(samples, 4))
+	assert.Check(t, is.DeepEqual(types.StatsHistorySample{
+		Time:            start.Add(10 * time.Second),
+		CPUPercent:      50,
+		MemoryUsage:     1024,
+		MemoryMaxUsage:  4096,
+		NetworkRxBytes:  1025,
+		NetworkTxBytes:  2,
+		BlockReadBytes:  10,
+		BlockWriteBytes: 20,
+	}, samples[1]))
+	assert.Check(t, is.Equal(start.Add(20*time.Second), samples[2].Time))
+	assert.Check(t, is.Equal(start.Add(30*time.Second), samples[3].Time))
+	assert.Check(t, is.Equal(float64(50), samples[3].CPUPercent))
+
+	samples, resolution = h.Get("id", time.Time{}, time.Time{}, 30*time.Second)
+	assert.Check(t, is.Equal(30*time.Second, resolution))
+	assert.Assert(t, is.Len(samples, 2))
+	assert.Check(t, is.Equal(float64(50), samples[0].CPUPercent))
+	assert.Check(t, is.Equal(uint64(4096), samples[0].MemoryMaxUsage))
+
+	samples, _ = h.Get("id", start.Add(15*time.Second), start.Add(20*time.Second), 0)
+	assert.Assert(t, is.Len(samples, 1))
+	assert.Check(t, is.Equal(start.Add(10*time.Second), samples[0].Time))
+
+	// Samples older than an hour are only kept at a resolution of 1m.
+	samples, resolution = h.Get("id", time.Now().Add(-2*time.Hour), time.Time{}, 0)
+	assert.Check(t, is.Equal(time.Minute, resolution))
+	assert.Assert(t, is.Len(samples, 1))
+	assert.Check(t, is.Equal(start, samples[0].Time))
+
+	samples, _ = h.Get("unknown", time.Time{}, time.Time{}, 0)
+	assert.Check(t, is.Len(samples, 0))
+}
+
+func TestHistoryRing(t *testing.T) {
+	r := &historyRing{tier: historyTier{resolution: time.Second, retention: 3 * time.Second}}
+	start := time.Unix(1500000000, 0)
+	for i := 0; i < 5; i++ {
+		r.add(types.StatsHistorySample{Time: start.Add(time.Duration(i) * time.Second), MemoryUsage: uint64(i)}, 0, false)
+	}
+
+	// the samples of the last 3 intervals, and of the current one
+	samples := r.list(time.Time{}, time.Time{})
+	assert.Assert(t, is.Len(samples, 4))
+	for i, s := range samples {
+		assert.Check(t, is.Equal(uint64(i+1), s.MemoryUsage))
+	}
+
+	r.flush()
+	assert.Check(t, r.bucket == nil)
+	assert.Check(t, is.DeepEqual(samples[1:], r.list(time.Time{}, time.Time{})))
+}
+
+func TestHistoryFlush(t *testing.T) {
+	h := NewHistory(1 << 30)
+	now := time.Now()
+	h.Add("id", historyStats(now, 0, 0, 1024))
+	h.Flush("id")
+	h.Flush("unknown")
+
+	for _, r := range h.containers["id"].rings {
+		assert.Check(t, r.bucket == nil)
+		assert.Assert(t, is.Len(r.samples, 1))
+		assert.Check(t, is.Equal(uint64(1024), r.samples[0].MemoryUsage))
+	}
+}
+
+func TestHistoryMaxMemory(t *testing.T) {
+	var containerMemory int64
+	for _, t := range historyTiers {
+		containerMemory += int64(t.size()) * historySampleSize
+	}
+	h := NewHistory(2 * containerMemory)
+
+	now := time.Now()
+	h.Add("a", historyStats(now, 0, 0, 0))
+	h.Add("b", historyStats(now.Add(time.Second), 0, 0, 0))
+	h.Add("a", historyStats(now.Add(2*time.Second), 0, 0, 0))
+	h.Add("c", historyStats(now.Add(3*time.Second), 0, 0, 0))
+
+	assert.Check(t, is.Len(h.containers, 2))
+	_, exists := h.containers["b"]
+	assert.Check(t, !exists, "expected the least recently updated container to be dropped")
+
+	h.Remove("a")
+	assert.Check(t, is.Len(h.containers, 1))
+}
@@ -4,6 +4,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/stats"
 	"github.com/docker/docker/pkg/system"
 )
@@ -21,6 +22,13 @@ func (daemon *Daemon) newStatsCollector(interval time.Duration) *stats.Collector
 		}
 	}
 	s := stats.NewCollector(daemon, interval)
+	if daemon.configStore.StatsHistory {
+		maxMemory := daemon.configStore.StatsHistoryMaxMemory.Value()
+		if maxMemory <= 0 {
+			maxMemory = config.DefaultStatsHistoryMaxMemory
+		}
+		s.EnableHistory(stats.NewHistory(maxMemory))
+	}
 	go s.Run()
 	return s
 }
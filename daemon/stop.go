@@ -34,18 +34,22 @@ func (daemon *Daemon) ContainerStop(name string, seconds *int) error {
 	return nil
 }
 
-// containerStop halts a container by sending a stop signal, waiting for the given
-// duration in seconds, and then calling SIGKILL and waiting for the
-// process to exit. If a negative duration is given, Stop will wait
-// for the initial signal forever. If the container is not running Stop returns
-// immediately.
+// containerStop halts a container by running its pre-stop hook, sending a stop
+// signal, waiting for the given duration in seconds, and then calling SIGKILL
+// and waiting for the process to exit. The time taken by the pre-stop hook
+// counts toward the given duration, but the hook may use at most half of it.
+// If a negative duration is given, Stop will wait for the initial signal
+// forever. If the container is not running Stop returns immediately.
 func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds int) error {
 	if !container.IsRunning() {
 		return nil
 	}
 
+	// 1. Run the pre-stop hook, within the stop timeout
+	timeout := daemon.runPreStopHook(container, time.Duration(seconds)*time.Second)
+
 	stopSignal := container.StopSignal()
-	// 1. Send a stop signal
+	// 2. Send a stop signal
 	if err := daemon.killPossiblyDeadProcess(container, stopSignal); err != nil {
 		// While normally we might "return err" here we're not going to
 		// because if we can't stop the container by this point then
@@ -68,13 +72,13 @@ func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds i
 		}
 	}
 
-	// 2. Wait for the process to exit on its own
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(seconds)*time.Second)
+	// 3. Wait for the process to exit on its own
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	if status := <-container.Wait(ctx, containerpkg.WaitConditionNotRunning); status.Err() != nil {
 		logrus.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, stopSignal)
-		// 3. If it doesn't, then send SIGKILL
+		// 4. If it doesn't, then send SIGKILL
 		if err := daemon.Kill(container); err != nil {
 			// Wait without a timeout, ignore result.
 			<-container.Wait(context.Background(), containerpkg.WaitConditionNotRunning)
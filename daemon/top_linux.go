@@ -0,0 +1,306 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/opencontainers/runc/libcontainer/system"
+	"github.com/opencontainers/runc/libcontainer/user"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// procfsRoot is the mount point of the proc filesystem the processes of
+// containers are read from.
+const procfsRoot = "/proc"
+
+// topTitles are the titles of the process list, as printed by "ps -ef".
+var topTitles = []string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"}
+
+// procfsInfo holds the system-wide information needed to compute the
+// details of processes.
+type procfsInfo struct {
+	root       string
+	bootTime   time.Time
+	uptime     time.Duration
+	clockTicks uint64
+	pageSize   uint64
+}
+
+// readProcfsInfo reads the boot time and uptime of the system from the proc
+// filesystem mounted at root.
+func readProcfsInfo(root string) (*procfsInfo, error) {
+	info := &procfsInfo{
+		root:       root,
+		clockTicks: uint64(system.GetClockTicks()),
+		pageSize:   uint64(os.Getpagesize()),
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "uptime"))
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return nil, errors.New("invalid uptime")
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid uptime")
+	}
+	info.uptime = time.Duration(uptime * float64(time.Second))
+
+	f, err := os.Open(filepath.Join(root, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			btime, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid boot time")
+			}
+			info.bootTime = time.Unix(btime, 0)
+			return info, scanner.Err()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("boot time not found")
+}
+
+// readProcess reads the details of the process pid. The UID of the process
+// is the one on the host.
+func (info *procfsInfo) readProcess(pid int) (*containertypes.TopProcess, error) {
+	dir := filepath.Join(info.root, strconv.Itoa(pid))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// The command name is between parentheses, and may contain spaces and
+	// parentheses itself.
+	stat := string(content)
+	start, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return nil, errors.Errorf("invalid stat of process %d", pid)
+	}
+	comm := stat[start+1 : end]
+	// fields[0] is the third field of the stat file, see proc(5).
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return nil, errors.Errorf("invalid stat of process %d", pid)
+	}
+	values := make(map[int]uint64)
+	for _, i := range []int{4, 7, 14, 15, 20, 22, 24} {
+		v, err := strconv.ParseUint(fields[i-3], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid stat of process %d", pid)
+		}
+		values[i] = v
+	}
+
+	p := &containertypes.TopProcess{
+		PID:     int64(pid),
+		PPID:    int64(values[4]),
+		State:   fields[0],
+		Threads: int64(values[20]),
+		RSS:     values[24] * info.pageSize,
+		TTY:     ttyName(values[7]),
+	}
+
+	cpuTime := info.ticksToDuration(values[14] + values[15])
+	p.CPUTime = int64(cpuTime)
+	started := info.ticksToDuration(values[22])
+	p.StartedAt = info.bootTime.Add(started).Format(time.RFC3339Nano)
+	if elapsed := info.uptime - started; elapsed > 0 {
+		p.CPUPercent = math.Round(float64(cpuTime)/float64(elapsed)*1000) / 10
+	}
+
+	uid, err := readProcessUID(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid status of process %d", pid)
+	}
+	p.UID = int64(uid)
+
+	content, err = ioutil.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	if content = bytes.TrimRight(content, "\x00"); len(content) > 0 {
+		p.Cmdline = strings.Split(string(content), "\x00")
+	} else {
+		// Kernel threads and zombies have no command line.
+		p.Cmdline = []string{"[" + comm + "]"}
+	}
+	return p, nil
+}
+
+// ticksToDuration converts a number of clock ticks to a duration.
+func (info *procfsInfo) ticksToDuration(ticks uint64) time.Duration {
+	return time.Duration(float64(ticks) / float64(info.clockTicks) * float64(time.Second))
+}
+
+// readProcessUID returns the effective UID from the status file of a process.
+func readProcessUID(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Uid: real effective saved filesystem
+		if len(fields) == 5 && fields[0] == "Uid:" {
+			return strconv.Atoi(fields[2])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("uid not found")
+}
+
+// ttyName returns the name of the controlling terminal tty_nr of a process,
+// or "?" if it has none or it isn't a terminal or pseudo-terminal.
+func ttyName(ttyNr uint64) string {
+	major := (ttyNr >> 8) & 0xfff
+	minor := (ttyNr & 0xff) | ((ttyNr >> 12) & 0xfff00)
+	switch {
+	case major >= 136 && major <= 143:
+		return fmt.Sprintf("pts/%d", minor+(major-136)*256)
+	case major == 4 && minor < 64:
+		return fmt.Sprintf("tty%d", minor)
+	default:
+		return "?"
+	}
+}
+
+// topProcessRow formats the process p as a row of the process list, in the
+// columns of topTitles.
+func topProcessRow(p containertypes.TopProcess, now time.Time) []string {
+	uid := p.User
+	if uid == "" {
+		uid = strconv.FormatInt(p.UID, 10)
+	}
+
+	// StartedAt keeps the time zone of the daemon, like ps does.
+	var stime string
+	startedAt, err := time.Parse(time.RFC3339Nano, p.StartedAt)
+	switch {
+	case err != nil:
+		stime = "?"
+	case startedAt.YearDay() == now.YearDay() && startedAt.Year() == now.Year():
+		stime = startedAt.Format("15:04")
+	case startedAt.Year() == now.Year():
+		stime = startedAt.Format("Jan02")
+	default:
+		stime = startedAt.Format("2006")
+	}
+
+	seconds := p.CPUTime / int64(time.Second)
+	cputime := fmt.Sprintf("%02d:%02d:%02d", seconds/3600%24, seconds/60%60, seconds%60)
+	if days := seconds / 86400; days > 0 {
+		cputime = fmt.Sprintf("%d-%s", days, cputime)
+	}
+
+	return []string{
+		uid,
+		strconv.FormatInt(p.PID, 10),
+		strconv.FormatInt(p.PPID, 10),
+		strconv.Itoa(int(p.CPUPercent)),
+		stime,
+		p.TTY,
+		cputime,
+		strings.Join(p.Cmdline, " "),
+	}
+}
+
+// containerUsers returns the names of the users of the container by UID,
+// read from the passwd file of the container.
+func containerUsers(c *container.Container) map[int]string {
+	users := make(map[int]string)
+	passwdPath, err := user.GetPasswdPath()
+	if err != nil {
+		return users
+	}
+	passwdFile, err := readUserFile(c, passwdPath)
+	if err != nil {
+		return users
+	}
+	defer passwdFile.Close()
+
+	entries, err := user.ParsePasswdFilter(passwdFile, nil)
+	if err != nil {
+		logrus.WithError(err).WithField("container", c.ID).Debug("Failed to parse passwd file of container")
+		return users
+	}
+	for _, u := range entries {
+		if _, ok := users[u.Uid]; !ok {
+			users[u.Uid] = u.Name
+		}
+	}
+	return users
+}
+
+// listProcesses lists the processes pids of the container c by reading the
+// proc filesystem. The details of each process are only returned in
+// structured mode.
+func (daemon *Daemon) listProcesses(c *container.Container, pids []uint32, structured bool) (*containertypes.ContainerTopOKBody, error) {
+	info, err := readProcfsInfo(procfsRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading system information")
+	}
+	users := containerUsers(c)
+
+	processes := make([]containertypes.TopProcess, 0, len(pids))
+	for _, pid := range pids {
+		p, err := info.readProcess(int(pid))
+		if err != nil {
+			// The process exited after the pids were listed.
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return nil, err
+		}
+		if daemon.idMappings != nil && !daemon.idMappings.Empty() {
+			uid, _, err := daemon.idMappings.ToContainer(idtools.IDPair{UID: int(p.UID), GID: daemon.idMappings.RootPair().GID})
+			if err == nil {
+				p.UID = int64(uid)
+			}
+		}
+		p.User = users[int(p.UID)]
+		processes = append(processes, *p)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].PID < processes[j].PID
+	})
+
+	procList := &containertypes.ContainerTopOKBody{Titles: topTitles}
+	now := time.Now()
+	for _, p := range processes {
+		procList.Processes = append(procList.Processes, topProcessRow(p, now))
+	}
+	if structured {
+		procList.Details = processes
+	}
+	return procList, nil
+}
@@ -0,0 +1,91 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func writeProcfsFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NilError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+func TestReadProcess(t *testing.T) {
+	root, err := ioutil.TempDir("", "procfs")
+	assert.NilError(t, err)
+	defer os.RemoveAll(root)
+
+	writeProcfsFiles(t, root, map[string]string{
+		"uptime": "1000.00 3000.00\n",
+		"stat":   "cpu  1 2 3 4\nbtime 1500000000\nprocesses 42\n",
+		// 500s of CPU time, started 600s after boot.
+		"42/stat":    "42 (my (weird) cmd) S 1 42 42 34817 42 4194560 100 0 0 0 30000 20000 0 0 20 0 3 0 60000 10000000 256 18446744073709551615\n",
+		"42/status":  "Name:\tcmd\nUid:\t1000\t1001\t1000\t1000\nGid:\t0\t0\t0\t0\n",
+		"42/cmdline": "sleep\x00infinity\x00",
+		"43/stat":    "43 (kworker) I 2 0 0 0 -1 69238880 0 0 0 0 0 0 0 0 20 0 1 0 100 0 0 18446744073709551615\n",
+		"43/status":  "Name:\tkworker\nUid:\t0\t0\t0\t0\n",
+		"43/cmdline": "",
+	})
+
+	info, err := readProcfsInfo(root)
+	assert.NilError(t, err)
+	info.clockTicks = 100
+	info.pageSize = 4096
+
+	p, err := info.readProcess(42)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(&containertypes.TopProcess{
+		PID:        42,
+		PPID:       1,
+		UID:        1001,
+		State:      "S",
+		Threads:    3,
+		CPUPercent: 125,
+		CPUTime:    int64(500 * time.Second),
+		RSS:        256 * 4096,
+		TTY:        "pts/1",
+		StartedAt:  time.Unix(1500000600, 0).Format(time.RFC3339Nano),
+		Cmdline:    []string{"sleep", "infinity"},
+	}, p))
+
+	p, err = info.readProcess(43)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("?", p.TTY))
+	assert.Check(t, is.DeepEqual([]string{"[kworker]"}, p.Cmdline))
+
+	_, err = info.readProcess(44)
+	assert.Check(t, os.IsNotExist(err))
+}
+
+func TestTopProcessRow(t *testing.T) {
+	now := time.Date(2018, 3, 10, 18, 0, 0, 0, time.UTC)
+	p := containertypes.TopProcess{
+		PID:        42,
+		PPID:       1,
+		UID:        1000,
+		CPUPercent: 12.7,
+		CPUTime:    int64(26*time.Hour + 3*time.Minute + 4*time.Second),
+		TTY:        "?",
+		StartedAt:  "2018-03-10T09:05:00Z",
+		Cmdline:    []string{"sleep", "infinity"},
+	}
+	assert.Check(t, is.DeepEqual([]string{"1000", "42", "1", "12", "09:05", "?", "1-02:03:04", "sleep infinity"}, topProcessRow(p, now)))
+
+	p.User = "app"
+	p.CPUTime = int64(time.Second)
+	p.StartedAt = "2018-02-01T09:05:00Z"
+	assert.Check(t, is.DeepEqual([]string{"app", "42", "1", "12", "Feb01", "?", "00:00:01", "sleep infinity"}, topProcessRow(p, now)))
+
+	p.StartedAt = "2017-02-01T09:05:00Z"
+	assert.Check(t, is.Equal("2017", topProcessRow(p, now)[4]))
+}
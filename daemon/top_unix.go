@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
 )
 
 func validatePSArgs(psArgs string) error {
@@ -113,45 +115,55 @@ func parsePSOutput(output []byte, procs []uint32) (*container.ContainerTopOKBody
 }
 
 // ContainerTop lists the processes running inside of the given
-// container by calling ps with the given args, or with the flags
-// "-ef" if no args are given.  An error is returned if the container
-// is not found, or is not running, or if there are any problems
-// running ps, or parsing the output.
-func (daemon *Daemon) ContainerTop(name string, psArgs string) (*container.ContainerTopOKBody, error) {
-	if psArgs == "" {
-		psArgs = "-ef"
-	}
-
-	if err := validatePSArgs(psArgs); err != nil {
-		return nil, err
+// container. If no args are given, the processes are read from the proc
+// filesystem, and their details are returned in structured mode. Otherwise,
+// ps is called with the given args. An error is returned if the container
+// is not found, or is not running, or if there are any problems listing the
+// processes.
+func (daemon *Daemon) ContainerTop(name string, psArgs string, structured bool) (*container.ContainerTopOKBody, error) {
+	if psArgs != "" {
+		if structured {
+			return nil, errdefs.InvalidParameter(errors.New("ps_args cannot be used in structured mode"))
+		}
+		if err := validatePSArgs(psArgs); err != nil {
+			return nil, err
+		}
 	}
 
-	container, err := daemon.GetContainer(name)
+	ctr, err := daemon.GetContainer(name)
 	if err != nil {
 		return nil, err
 	}
 
-	if !container.IsRunning() {
-		return nil, errNotRunning(container.ID)
+	if !ctr.IsRunning() {
+		return nil, errNotRunning(ctr.ID)
 	}
 
-	if container.IsRestarting() {
-		return nil, errContainerIsRestarting(container.ID)
+	if ctr.IsRestarting() {
+		return nil, errContainerIsRestarting(ctr.ID)
 	}
 
-	procs, err := daemon.containerd.ListPids(context.Background(), container.ID)
+	procs, err := daemon.containerd.ListPids(context.Background(), ctr.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	output, err := exec.Command("ps", strings.Split(psArgs, " ")...).Output()
-	if err != nil {
-		return nil, fmt.Errorf("Error running ps: %v", err)
-	}
-	procList, err := parsePSOutput(output, procs)
-	if err != nil {
-		return nil, err
+	var procList *container.ContainerTopOKBody
+	if psArgs == "" {
+		procList, err = daemon.listProcesses(ctr, procs, structured)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		output, err := exec.Command("ps", strings.Split(psArgs, " ")...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("Error running ps: %v", err)
+		}
+		procList, err = parsePSOutput(output, procs)
+		if err != nil {
+			return nil, err
+		}
 	}
-	daemon.LogContainerEvent(container, "top")
+	daemon.LogContainerEvent(ctr, "top")
 	return procList, nil
 }
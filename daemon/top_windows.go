@@ -24,11 +24,14 @@ import (
 //    task manager does and use the private working set as the memory counter.
 //    We could return more info for those who really understand how memory
 //    management works in Windows if we introduced a "raw" stats (above).
-func (daemon *Daemon) ContainerTop(name string, psArgs string) (*containertypes.ContainerTopOKBody, error) {
+func (daemon *Daemon) ContainerTop(name string, psArgs string, structured bool) (*containertypes.ContainerTopOKBody, error) {
 	// It's not at all an equivalent to linux 'ps' on Windows
 	if psArgs != "" {
 		return nil, errors.New("Windows does not support arguments to top")
 	}
+	if structured {
+		return nil, errors.New("Windows does not support structured mode of top")
+	}
 
 	container, err := daemon.GetContainer(name)
 	if err != nil {
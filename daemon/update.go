@@ -1,7 +1,6 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/docker/docker/api/types/container"
@@ -42,10 +41,12 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 
 	restoreConfig := false
 	backupHostConfig := *container.HostConfig
+	backupExposedPorts := container.Config.ExposedPorts
 	defer func() {
 		if restoreConfig {
 			container.Lock()
 			container.HostConfig = &backupHostConfig
+			container.Config.ExposedPorts = backupExposedPorts
 			container.CheckpointTo(daemon.containersReplica)
 			container.Unlock()
 		}
@@ -78,11 +79,23 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 	// If container is running (including paused), we need to update configs
 	// to the real world.
 	if container.IsRunning() && !container.IsRestarting() {
-		if err := daemon.containerd.UpdateResources(context.Background(), container.ID, toContainerdResources(hostConfig.Resources)); err != nil {
+		if err := daemon.updateResources(container, hostConfig.Resources); err != nil {
 			restoreConfig = true
 			// TODO: it would be nice if containerd responded with better errors here so we can classify this better.
 			return errCannotUpdate(container.ID, errdefs.System(err))
 		}
+		if hostConfig.PortBindings != nil {
+			container.Lock()
+			err := daemon.updatePortBindings(container, backupHostConfig.PortBindings, backupExposedPorts)
+			if err == nil {
+				err = container.CheckpointTo(daemon.containersReplica)
+			}
+			container.Unlock()
+			if err != nil {
+				restoreConfig = true
+				return errCannotUpdate(container.ID, errdefs.System(err))
+			}
+		}
 	}
 
 	daemon.LogContainerEvent(container, "update")
@@ -1,13 +1,30 @@
 package daemon // import "github.com/docker/docker/daemon"
 
 import (
+	"context"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/docker/libcontainerd"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// updateResources updates the resources of the running container c through
+// containerd. On cgroup v2, the resources are then written to the cgroup of
+// the container as well, as runtimes predating cgroup v2 only update the
+// cgroup v1 controllers.
+func (daemon *Daemon) updateResources(c *containerpkg.Container, resources container.Resources) error {
+	r := toContainerdResources(resources)
+	if err := daemon.containerd.UpdateResources(context.Background(), c.ID, r); err != nil {
+		return err
+	}
+	if daemon.cgroupUnified {
+		return applyCgroup2Resources(c.GetPID(), (*specs.LinuxResources)(r))
+	}
+	return nil
+}
+
 func toContainerdResources(resources container.Resources) *libcontainerd.Resources {
 	var r libcontainerd.Resources
 
@@ -0,0 +1,145 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"io"
+	"os"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/volume"
+	volumestore "github.com/docker/docker/volume/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// VolumeExport writes the content of the volume with the given name to out
+// as a tar archive, compressed with the given compression ("none", "gzip"
+// or "zstd"). File ownership is translated to the IDs of the remapped root,
+// as for container exports. An error is returned if the volume cannot be
+// found.
+func (daemon *Daemon) VolumeExport(name, compression string, out io.Writer) error {
+	c, err := parseVolumeArchiveCompression(compression)
+	if err != nil {
+		return err
+	}
+
+	v, path, release, err := daemon.mountVolumeForArchive(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	data, err := chrootarchive.Tar(path, &archive.TarOptions{
+		Compression: c,
+		UIDMaps:     daemon.idMappings.UIDs(),
+		GIDMaps:     daemon.idMappings.GIDs(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error exporting volume %s", v.Name())
+	}
+	defer data.Close()
+
+	if _, err := io.Copy(out, data); err != nil {
+		return errors.Wrapf(err, "error exporting volume %s", v.Name())
+	}
+	daemon.LogVolumeEvent(v.Name(), "export", map[string]string{"driver": v.DriverName()})
+	return nil
+}
+
+// VolumeImport extracts the tar archive read from content, which may be
+// compressed, into the volume with the given name. File ownership is
+// translated to the IDs of the remapped root. The import is refused if the
+// volume is not empty, unless overwrite is set: existing files of the volume
+// are then overwritten by files of the archive. An error is returned if the
+// volume cannot be found.
+func (daemon *Daemon) VolumeImport(name string, content io.Reader, overwrite bool) error {
+	v, path, release, err := daemon.mountVolumeForArchive(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !overwrite {
+		empty, err := isEmptyDir(path)
+		if err != nil {
+			return errdefs.System(errors.Wrapf(err, "error importing into volume %s", v.Name()))
+		}
+		if !empty {
+			return errdefs.Conflict(errors.Errorf("volume %s is not empty", v.Name()))
+		}
+	}
+
+	if err := chrootarchive.Untar(content, path, &archive.TarOptions{
+		UIDMaps: daemon.idMappings.UIDs(),
+		GIDMaps: daemon.idMappings.GIDs(),
+	}); err != nil {
+		return errors.Wrapf(err, "error importing into volume %s", v.Name())
+	}
+	daemon.LogVolumeEvent(v.Name(), "import", map[string]string{"driver": v.DriverName()})
+	return nil
+}
+
+// mountVolumeForArchive mounts the volume with the given name using a
+// transient reference, which prevents the volume from being removed while it
+// is mounted. The returned release function unmounts the volume and drops the
+// reference.
+func (daemon *Daemon) mountVolumeForArchive(name string) (volume.Volume, string, func(), error) {
+	v, err := daemon.volumes.Get(name)
+	if err != nil {
+		if volumestore.IsNotExist(err) {
+			return nil, "", nil, volumeNotFound(name)
+		}
+		return nil, "", nil, errdefs.System(err)
+	}
+
+	ref := "archive-" + stringid.GenerateNonCryptoID()
+	v, err = daemon.volumes.GetWithRef(v.Name(), v.DriverName(), ref)
+	if err != nil {
+		return nil, "", nil, errdefs.System(err)
+	}
+
+	path, err := v.Mount(ref)
+	if err != nil {
+		daemon.volumes.Dereference(v, ref)
+		return nil, "", nil, errdefs.System(errors.Wrapf(err, "error mounting volume %s", v.Name()))
+	}
+	release := func() {
+		if err := v.Unmount(ref); err != nil {
+			logrus.WithError(err).WithField("volume", v.Name()).Warn("Error unmounting volume")
+		}
+		daemon.volumes.Dereference(v, ref)
+	}
+	return v, path, release, nil
+}
+
+// isEmptyDir returns whether the directory at path has no entries.
+func isEmptyDir(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := f.Readdirnames(1); err != nil {
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+func parseVolumeArchiveCompression(compression string) (archive.Compression, error) {
+	switch compression {
+	case "", "none":
+		return archive.Uncompressed, nil
+	case "gzip":
+		return archive.Gzip, nil
+	case "zstd":
+		return archive.Zstd, nil
+	default:
+		return archive.Uncompressed, errdefs.InvalidParameter(errors.Errorf("invalid compression %q: must be none, gzip or zstd", compression))
+	}
+}
@@ -0,0 +1,26 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestParseVolumeArchiveCompression(t *testing.T) {
+	for value, expected := range map[string]archive.Compression{
+		"":     archive.Uncompressed,
+		"none": archive.Uncompressed,
+		"gzip": archive.Gzip,
+		"zstd": archive.Zstd,
+	} {
+		c, err := parseVolumeArchiveCompression(value)
+		assert.Check(t, err, value)
+		assert.Check(t, is.Equal(expected, c), value)
+	}
+
+	_, err := parseVolumeArchiveCompression("bzip2")
+	assert.Check(t, errdefs.IsInvalidParameter(err))
+}
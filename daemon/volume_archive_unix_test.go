@@ -0,0 +1,81 @@
+// +build !windows
+
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/fs"
+	"github.com/gotestyourself/gotestyourself/skip"
+)
+
+func init() {
+	reexec.Init()
+}
+
+func TestVolumeExportImport(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	tmp := fs.NewDir(t, "volume-archive")
+	defer tmp.Remove()
+	daemon, err := initDaemonWithVolumeStore(tmp.Path())
+	assert.NilError(t, err)
+	daemon.EventsService = events.New()
+	// The root of the containers is the host user 100000.
+	idMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	daemon.idMappings = idtools.NewIDMappingsFromMaps(idMaps, idMaps)
+
+	src, err := daemon.volumes.Create("src", "local", nil, nil)
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chown(src.Path(), 100000, 100000))
+	assert.NilError(t, os.Mkdir(filepath.Join(src.Path(), "dir"), 0750))
+	assert.NilError(t, os.Chown(filepath.Join(src.Path(), "dir"), 101000, 101000))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(src.Path(), "dir", "file"), []byte("content"), 0640))
+	assert.NilError(t, os.Chown(filepath.Join(src.Path(), "dir", "file"), 101000, 101001))
+
+	var archive bytes.Buffer
+	assert.NilError(t, daemon.VolumeExport("src", "gzip", &archive))
+	exported := archive.Bytes()
+
+	dst, err := daemon.volumes.Create("dst", "local", nil, nil)
+	assert.NilError(t, err)
+	assert.NilError(t, daemon.VolumeImport("dst", bytes.NewReader(exported), false))
+
+	content, err := ioutil.ReadFile(filepath.Join(dst.Path(), "dir", "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("content", string(content)))
+	for path, expected := range map[string][2]uint32{
+		"dir":      {101000, 101000},
+		"dir/file": {101000, 101001},
+	} {
+		fi, err := os.Lstat(filepath.Join(dst.Path(), path))
+		assert.NilError(t, err)
+		st := fi.Sys().(*syscall.Stat_t)
+		assert.Check(t, is.DeepEqual(expected, [2]uint32{st.Uid, st.Gid}), path)
+	}
+
+	// The volume is not empty anymore: a second import is refused unless
+	// it overwrites the volume.
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dst.Path(), "dir", "file"), []byte("changed"), 0640))
+	err = daemon.VolumeImport("dst", bytes.NewReader(exported), false)
+	assert.Check(t, errdefs.IsConflict(err), "%v", err)
+	content, err = ioutil.ReadFile(filepath.Join(dst.Path(), "dir", "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("changed", string(content)))
+
+	assert.NilError(t, daemon.VolumeImport("dst", bytes.NewReader(exported), true))
+	content, err = ioutil.ReadFile(filepath.Join(dst.Path(), "dir", "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("content", string(content)))
+}
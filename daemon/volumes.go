@@ -44,6 +44,19 @@ func volumeToAPIType(v volume.Volume) *types.Volume {
 	return tv
 }
 
+// volumeSnapshotInfo returns the origin of a volume created as a snapshot of
+// another volume, in the format used by the Engine API.
+func (daemon *Daemon) volumeSnapshotInfo(name string) *types.VolumeSnapshot {
+	info := daemon.volumes.SnapshotInfo(name)
+	if info == nil {
+		return nil
+	}
+	return &types.VolumeSnapshot{
+		Source:    info.Source,
+		CreatedAt: info.CreatedAt.Format(time.RFC3339),
+	}
+}
+
 // Len returns the number of mounts. Used in sorting.
 func (m mounts) Len() int {
 	return len(m)
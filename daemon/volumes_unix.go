@@ -4,15 +4,20 @@ package daemon // import "github.com/docker/docker/daemon"
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
+	mounttypes "github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
 	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/sirupsen/logrus"
 )
 
 // setupMounts iterates through each of the mount points for a container and
@@ -154,3 +159,72 @@ func (daemon *Daemon) mountVolumes(container *container.Container) error {
 
 	return nil
 }
+
+// emptyChownMounts returns the volume mounts of the container which have the
+// chown option set, and are mounted for the first time: the volume, or its
+// subpath, is empty.
+func (daemon *Daemon) emptyChownMounts(c *container.Container) ([]*volumemounts.MountPoint, error) {
+	var mounts []*volumemounts.MountPoint
+	for _, m := range c.MountPoints {
+		if m.Volume == nil || m.Type != mounttypes.TypeVolume || m.Spec.VolumeOptions == nil || !m.Spec.VolumeOptions.Chown {
+			continue
+		}
+		empty, err := mountIsEmpty(c, m, daemon.idMappings.RootPair())
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts, nil
+}
+
+func mountIsEmpty(c *container.Container, m *volumemounts.MountPoint, rootIDs idtools.IDPair) (bool, error) {
+	path, err := m.Setup(c.MountLabel, rootIDs, nil)
+	if err != nil {
+		return false, err
+	}
+	defer m.Cleanup()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err != io.EOF {
+		return false, err
+	}
+	return true, nil
+}
+
+// chownVolume sets the ownership of the content of a volume mount to the user
+// of the container, which is resolved with the passwd and group files of the
+// container.
+func (daemon *Daemon) chownVolume(c *container.Container, m *volumemounts.MountPoint) error {
+	if c.Config.User == "" {
+		return nil
+	}
+	uid, gid, _, err := getUser(c, c.Config.User)
+	if err != nil {
+		return err
+	}
+	ids, err := daemon.idMappings.ToHost(idtools.IDPair{UID: int(uid), GID: int(gid)})
+	if err != nil {
+		return err
+	}
+
+	path, err := m.Setup(c.MountLabel, daemon.idMappings.RootPair(), nil)
+	if err != nil {
+		return err
+	}
+	defer m.Cleanup()
+
+	logrus.Debugf("setting ownership of volume %s to %d:%d", m.Name, ids.UID, ids.GID)
+	return filepath.Walk(path, func(p string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, ids.UID, ids.GID)
+	})
+}
@@ -9,11 +9,13 @@ import (
 
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/system"
 	refstore "github.com/docker/docker/reference"
@@ -49,6 +51,13 @@ type Config struct {
 	ReferenceStore refstore.Store
 	// RequireSchema2 ensures that only schema2 manifests are used.
 	RequireSchema2 bool
+	// ManifestVerifier is optional. If set, it is called with the digest
+	// of the manifest of ref. On pull, it is called before any layer is
+	// downloaded, the pull is aborted if it returns an error, and v1
+	// endpoints are not used as their images have no manifest digest. On
+	// push, the digest reference of the pushed image is only recorded if
+	// it returns nil.
+	ManifestVerifier func(ctx context.Context, repo distribution.Repository, ref reference.Named, dgst digest.Digest) error
 }
 
 // ImagePullConfig stores pull configuration.
@@ -79,6 +88,13 @@ type ImagePushConfig struct {
 	TrustKey libtrust.PrivateKey
 	// UploadManager dispatches uploads.
 	UploadManager *xfer.LayerUploadManager
+	// LayerCompression is the compression used for the layers that are
+	// uploaded. Only archive.Gzip and archive.Zstd are supported; any
+	// other value selects gzip.
+	LayerCompression archive.Compression
+	// LayerCompressionLevel is the compression level used for the layers
+	// that are uploaded. 0 selects the default level.
+	LayerCompressionLevel int
 }
 
 // ImageConfigStore handles storing and getting image configurations
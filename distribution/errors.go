@@ -106,6 +106,9 @@ func TranslatePullError(err error, ref reference.Named) error {
 		}
 	case xfer.DoNotRetry:
 		return TranslatePullError(v.Err, ref)
+	case errdefs.ErrForbidden:
+		// e.g. rejected by the image signature policy
+		return err
 	}
 
 	return errdefs.Unknown(err)
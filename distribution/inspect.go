@@ -0,0 +1,223 @@
+package distribution // import "github.com/docker/docker/distribution"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// InspectManifest resolves ref in the registry and returns its manifest. If
+// the manifest is a manifest list, every manifest it refers to is fetched as
+// well. Image configurations are fetched and verified, but no layer is
+// downloaded. Endpoints are tried in the same order as for a pull, falling
+// back from mirrors to the upstream registry.
+func InspectManifest(ctx context.Context, ref reference.Named, config *Config) (*registrytypes.DistributionManifest, error) {
+	repoInfo, err := config.RegistryService.ResolveRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// makes sure name is not `scratch`
+	if err := ValidateRepoName(repoInfo.Name); err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
+
+	endpoints, err := config.RegistryService.LookupPullEndpoints(reference.Domain(repoInfo.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	ref = reference.TagNameOnly(ref)
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if endpoint.Version == registry.APIVersion1 {
+			continue
+		}
+
+		logrus.Debugf("Trying to inspect %s from %s %s", reference.FamiliarString(ref), endpoint.URL, endpoint.Version)
+
+		repo, _, err := NewV2Repository(ctx, repoInfo, endpoint, config.MetaHeaders, config.AuthConfig, "pull")
+		if err == nil {
+			var m *registrytypes.DistributionManifest
+			m, err = inspectManifest(ctx, repo, ref)
+			if err == nil {
+				return m, nil
+			}
+		}
+		if fallbackErr, ok := err.(fallbackError); ok {
+			err = fallbackErr.err
+		}
+		if ctx.Err() != nil || !continueOnError(err, endpoint.Mirror) {
+			return nil, TranslatePullError(err, ref)
+		}
+		logrus.Infof("Attempting next endpoint for manifest inspect after error: %v", err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no endpoints found for %s", reference.FamiliarString(ref))
+	}
+	return nil, TranslatePullError(lastErr, ref)
+}
+
+func inspectManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (*registrytypes.DistributionManifest, error) {
+	manSvc, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest distribution.Manifest
+	if digested, isDigested := ref.(reference.Canonical); isDigested {
+		manifest, err = manSvc.Get(ctx, digested.Digest())
+	} else if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
+		manifest, err = manSvc.Get(ctx, "", distribution.WithTag(tagged.Tag()))
+	} else {
+		return nil, fmt.Errorf("internal error: reference has neither a tag nor a digest: %s", reference.FamiliarString(ref))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("image manifest does not exist for %s", reference.FamiliarString(ref))
+	}
+
+	mfstList, isList := manifest.(*manifestlist.DeserializedManifestList)
+	if !isList {
+		m, err := inspectImageManifest(ctx, repo, ref, manifest)
+		if err != nil {
+			return nil, err
+		}
+		return &registrytypes.DistributionManifest{
+			Descriptor: m.Descriptor,
+			Raw:        m.Raw,
+			Manifests:  []registrytypes.DistributionImageManifest{m},
+		}, nil
+	}
+
+	desc, raw, err := manifestDescriptor(ref, manifest)
+	if err != nil {
+		return nil, err
+	}
+	result := &registrytypes.DistributionManifest{
+		Descriptor: desc,
+		Raw:        raw,
+	}
+	for _, entry := range mfstList.Manifests {
+		childRef, err := reference.WithDigest(reference.TrimNamed(ref), entry.Digest)
+		if err != nil {
+			return nil, err
+		}
+		child, err := manSvc.Get(ctx, entry.Digest)
+		if err != nil {
+			return nil, err
+		}
+		m, err := inspectImageManifest(ctx, repo, childRef, child)
+		if err != nil {
+			return nil, err
+		}
+		m.Descriptor.Platform = &specs.Platform{
+			Architecture: entry.Platform.Architecture,
+			OS:           entry.Platform.OS,
+			OSVersion:    entry.Platform.OSVersion,
+			OSFeatures:   entry.Platform.OSFeatures,
+			Variant:      entry.Platform.Variant,
+		}
+		result.Manifests = append(result.Manifests, m)
+	}
+	return result, nil
+}
+
+// inspectImageManifest describes a schema1 or schema2 image manifest. The
+// image configuration is fetched for schema2 manifests; the layer sizes of
+// schema1 manifests, which don't record them, are looked up in the registry.
+func inspectImageManifest(ctx context.Context, repo distribution.Repository, ref reference.Named, manifest distribution.Manifest) (registrytypes.DistributionImageManifest, error) {
+	var m registrytypes.DistributionImageManifest
+
+	switch v := manifest.(type) {
+	case *schema1.SignedManifest:
+		verified, err := verifySchema1Manifest(v, ref)
+		if err != nil {
+			return m, err
+		}
+		blobs := repo.Blobs(ctx)
+		for i := len(verified.FSLayers) - 1; i >= 0; i-- {
+			dgst := verified.FSLayers[i].BlobSum
+			desc, err := blobs.Stat(ctx, dgst)
+			if err != nil {
+				return m, err
+			}
+			m.Layers = append(m.Layers, specs.Descriptor{
+				MediaType: schema1.MediaTypeManifestLayer,
+				Digest:    dgst,
+				Size:      desc.Size,
+			})
+		}
+	case *schema2.DeserializedManifest:
+		configJSON, err := fetchSchema2Config(ctx, repo.Blobs(ctx), v.Config.Digest)
+		if err != nil {
+			return m, err
+		}
+		m.Config = json.RawMessage(configJSON)
+		m.Size += int64(len(configJSON))
+		for _, l := range v.Layers {
+			m.Layers = append(m.Layers, specs.Descriptor{
+				MediaType: l.MediaType,
+				Digest:    l.Digest,
+				Size:      l.Size,
+				URLs:      l.URLs,
+			})
+		}
+	default:
+		return m, invalidManifestFormatError{}
+	}
+
+	desc, raw, err := manifestDescriptor(ref, manifest)
+	if err != nil {
+		return m, err
+	}
+	m.Descriptor = desc
+	m.Raw = raw
+	m.Size += desc.Size
+	for _, l := range m.Layers {
+		m.Size += l.Size
+	}
+	return m, nil
+}
+
+// manifestDescriptor returns the descriptor and payload of manifest. If ref
+// is a digested reference, the digest of the manifest is verified.
+func manifestDescriptor(ref reference.Named, manifest distribution.Manifest) (specs.Descriptor, json.RawMessage, error) {
+	mediaType, payload, err := manifest.Payload()
+	if err != nil {
+		return specs.Descriptor{}, nil, err
+	}
+	var dgst digest.Digest
+	if m, ok := manifest.(*schema1.SignedManifest); ok {
+		// schema1 manifests are addressed by the digest of their
+		// unsigned payload, verified by verifySchema1Manifest.
+		dgst = digest.FromBytes(m.Canonical)
+	} else {
+		dgst, err = schema2ManifestDigest(ref, manifest)
+		if err != nil {
+			return specs.Descriptor{}, nil, err
+		}
+	}
+	return specs.Descriptor{
+		MediaType: mediaType,
+		Digest:    dgst,
+		Size:      int64(len(payload)),
+	}, json.RawMessage(payload), nil
+}
@@ -0,0 +1,190 @@
+package distribution // import "github.com/docker/docker/distribution"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/registry"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/opencontainers/go-digest"
+)
+
+type content struct {
+	mediaType string
+	payload   []byte
+}
+
+// fakeRegistry serves the manifests and blobs of a single repository.
+type fakeRegistry struct {
+	tags      map[string]digest.Digest
+	manifests map[digest.Digest]content
+	blobs     map[digest.Digest][]byte
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/v2/library/testrepo/"
+	if r.URL.Path == "/v2/" {
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, prefix), "/", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	var c content
+	var ok bool
+	switch parts[0] {
+	case "manifests":
+		dgst, isTag := f.tags[parts[1]]
+		if !isTag {
+			dgst = digest.Digest(parts[1])
+		}
+		c, ok = f.manifests[dgst]
+	case "blobs":
+		c.mediaType = "application/octet-stream"
+		c.payload, ok = f.blobs[digest.Digest(parts[1])]
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", c.mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(c.payload)))
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(c.payload).String())
+	if r.Method != http.MethodHead {
+		w.Write(c.payload)
+	}
+}
+
+func (f *fakeRegistry) addManifest(t *testing.T, m distribution.Manifest) distribution.Descriptor {
+	mediaType, payload, err := m.Payload()
+	assert.NilError(t, err)
+	dgst := digest.FromBytes(payload)
+	f.manifests[dgst] = content{mediaType: mediaType, payload: payload}
+	return distribution.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(payload))}
+}
+
+func (f *fakeRegistry) addImage(t *testing.T, config string, layerSizes ...int64) distribution.Descriptor {
+	configJSON := []byte(config)
+	configDesc := distribution.Descriptor{
+		MediaType: schema2.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(configJSON),
+		Size:      int64(len(configJSON)),
+	}
+	f.blobs[configDesc.Digest] = configJSON
+
+	m := schema2.Manifest{Versioned: schema2.SchemaVersion, Config: configDesc}
+	for i, size := range layerSizes {
+		m.Layers = append(m.Layers, distribution.Descriptor{
+			MediaType: schema2.MediaTypeLayer,
+			Digest:    digest.FromString(config + strconv.Itoa(i)),
+			Size:      size,
+		})
+	}
+	dm, err := schema2.FromStruct(m)
+	assert.NilError(t, err)
+	return f.addManifest(t, dm)
+}
+
+func newFakeRepository(t *testing.T, ts *httptest.Server) distribution.Repository {
+	uri, err := url.Parse(ts.URL)
+	assert.NilError(t, err)
+	n, err := reference.ParseNormalizedNamed("testrepo")
+	assert.NilError(t, err)
+	repoInfo := &registry.RepositoryInfo{
+		Name:  n,
+		Index: &registrytypes.IndexInfo{Name: "testrepo"},
+	}
+	endpoint := registry.APIEndpoint{URL: uri, Version: registry.APIVersion2, TrimHostname: true}
+	repo, _, err := NewV2Repository(context.Background(), repoInfo, endpoint, nil, &types.AuthConfig{}, "pull")
+	assert.NilError(t, err)
+	return repo
+}
+
+func TestInspectManifestList(t *testing.T) {
+	f := &fakeRegistry{
+		tags:      map[string]digest.Digest{},
+		manifests: map[digest.Digest]content{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+	amd64 := f.addImage(t, `{"architecture":"amd64","os":"linux"}`, 100, 200)
+	arm64 := f.addImage(t, `{"architecture":"arm64","os":"linux"}`, 300)
+	list, err := manifestlist.FromDescriptors([]manifestlist.ManifestDescriptor{
+		{Descriptor: amd64, Platform: manifestlist.PlatformSpec{Architecture: "amd64", OS: "linux"}},
+		{Descriptor: arm64, Platform: manifestlist.PlatformSpec{Architecture: "arm64", OS: "linux", Variant: "v8"}},
+	})
+	assert.NilError(t, err)
+	listDesc := f.addManifest(t, list)
+	f.tags["latest"] = listDesc.Digest
+
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	repo := newFakeRepository(t, ts)
+
+	ref, err := reference.ParseNormalizedNamed("testrepo:latest")
+	assert.NilError(t, err)
+	m, err := inspectManifest(context.Background(), repo, ref)
+	assert.NilError(t, err)
+
+	assert.Check(t, is.Equal(listDesc.Digest, m.Descriptor.Digest))
+	assert.Check(t, is.Equal(manifestlist.MediaTypeManifestList, m.Descriptor.MediaType))
+	assert.Assert(t, is.Len(m.Manifests, 2))
+
+	amd64Config := `{"architecture":"amd64","os":"linux"}`
+	assert.Check(t, is.Equal(amd64.Digest, m.Manifests[0].Descriptor.Digest))
+	assert.Check(t, is.Equal("amd64", m.Manifests[0].Descriptor.Platform.Architecture))
+	assert.Check(t, is.Equal(amd64Config, string(m.Manifests[0].Config)))
+	assert.Check(t, is.Len(m.Manifests[0].Layers, 2))
+	assert.Check(t, is.Equal(amd64.Size+int64(len(amd64Config))+300, m.Manifests[0].Size))
+
+	assert.Check(t, is.Equal(arm64.Digest, m.Manifests[1].Descriptor.Digest))
+	assert.Check(t, is.Equal("v8", m.Manifests[1].Descriptor.Platform.Variant))
+	assert.Check(t, is.Len(m.Manifests[1].Layers, 1))
+	assert.Check(t, is.Equal(int64(300), m.Manifests[1].Layers[0].Size))
+}
+
+func TestInspectManifestDigestMismatch(t *testing.T) {
+	f := &fakeRegistry{
+		tags:      map[string]digest.Digest{},
+		manifests: map[digest.Digest]content{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+	desc := f.addImage(t, `{"architecture":"amd64","os":"linux"}`, 100)
+	// serve the manifest under a digest which doesn't match its content
+	wrong := digest.FromString("wrong")
+	f.manifests[wrong] = f.manifests[desc.Digest]
+
+	ts := httptest.NewServer(f)
+	defer ts.Close()
+	repo := newFakeRepository(t, ts)
+
+	n, err := reference.ParseNormalizedNamed("testrepo")
+	assert.NilError(t, err)
+	ref, err := reference.WithDigest(n, wrong)
+	assert.NilError(t, err)
+	_, err = inspectManifest(context.Background(), repo, ref)
+	assert.Check(t, is.ErrorContains(err, "verification failed"))
+
+	ref, err = reference.WithDigest(n, desc.Digest)
+	assert.NilError(t, err)
+	m, err := inspectManifest(context.Background(), repo, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.Digest, m.Descriptor.Digest))
+	assert.Check(t, is.Len(m.Manifests, 1))
+}
@@ -33,6 +33,9 @@ var _ V2MetadataService = &v2MetadataService{}
 type V2Metadata struct {
 	Digest           digest.Digest
 	SourceRepository string
+	// MediaType is the media type of the blob, which identifies the
+	// compression it uses. It is empty for gzip compressed blobs.
+	MediaType string `json:",omitempty"`
 	// HMAC hashes above attributes with recent authconfig digest used as a key in order to determine matching
 	// metadata entries accompanied by the same credentials without actually exposing them.
 	HMAC string
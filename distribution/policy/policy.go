@@ -0,0 +1,235 @@
+// Package policy implements a daemon-side image signature policy. A policy
+// maps registries and repositories to a requirement (reject, accept-any or
+// signed-by) that is evaluated against the manifest digest of an image before
+// it is pulled, or before it is used to create a container.
+package policy // import "github.com/docker/docker/distribution/policy"
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/errdefs"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TypeAccept accepts any image, signed or not.
+	TypeAccept = "accept-any"
+	// TypeReject rejects every image.
+	TypeReject = "reject"
+	// TypeSignedBy accepts an image only if its manifest digest carries a
+	// valid signature made by one of the configured keys.
+	TypeSignedBy = "signed-by"
+)
+
+// Config is the image signature policy as it is written in the daemon
+// configuration file.
+type Config struct {
+	// Default is the requirement used for images which don't match any
+	// of the scopes. An empty default accepts any image.
+	Default Requirement `json:"default"`
+	// Scopes maps a fully-qualified registry host ("registry.example.com"),
+	// repository namespace ("docker.io/library") or repository name
+	// ("docker.io/library/busybox") to a requirement. The most specific
+	// scope matching an image wins.
+	Scopes map[string]Requirement `json:"scopes,omitempty"`
+	// TrustDir is a local directory containing detached signatures,
+	// stored as <trust-dir>/<repository>/<algorithm>-<hex>/<any name>.
+	TrustDir string `json:"trust-dir,omitempty"`
+}
+
+// Requirement is a single policy rule.
+type Requirement struct {
+	// Type is one of "accept-any", "reject" or "signed-by".
+	Type string `json:"type"`
+	// Keys holds the paths to PEM encoded public keys accepted for a
+	// "signed-by" requirement.
+	Keys []string `json:"keys,omitempty"`
+}
+
+type rule struct {
+	typ  string
+	keys []crypto.PublicKey
+}
+
+// Policy is a compiled image signature policy. It is safe for concurrent use.
+type Policy struct {
+	def      rule
+	scopes   map[string]rule
+	trustDir string
+}
+
+// New validates the configuration and loads the keys it refers to.
+func New(config Config) (*Policy, error) {
+	def, err := newRule("default", config.Default)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		def:      def,
+		scopes:   make(map[string]rule, len(config.Scopes)),
+		trustDir: config.TrustDir,
+	}
+	for scope, req := range config.Scopes {
+		if err := validateScope(scope); err != nil {
+			return nil, err
+		}
+		r, err := newRule(scope, req)
+		if err != nil {
+			return nil, err
+		}
+		p.scopes[scope] = r
+	}
+	return p, nil
+}
+
+func validateScope(scope string) error {
+	if scope == "" || strings.HasSuffix(scope, "/") || strings.Contains(scope, "@") {
+		return errors.Errorf("invalid image policy scope %q", scope)
+	}
+	// A colon is only allowed for the port of the registry host.
+	if i := strings.Index(scope, "/"); i >= 0 && strings.Contains(scope[i:], ":") {
+		return errors.Errorf("invalid image policy scope %q: scopes must not contain a tag", scope)
+	}
+	return nil
+}
+
+func newRule(scope string, req Requirement) (rule, error) {
+	r := rule{typ: req.Type}
+	switch req.Type {
+	case "":
+		r.typ = TypeAccept
+	case TypeAccept, TypeReject:
+	case TypeSignedBy:
+		if len(req.Keys) == 0 {
+			return rule{}, errors.Errorf("image policy for %s: %s requires at least one key", scope, TypeSignedBy)
+		}
+		for _, path := range req.Keys {
+			key, err := loadPublicKey(path)
+			if err != nil {
+				return rule{}, errors.Wrapf(err, "image policy for %s", scope)
+			}
+			r.keys = append(r.keys, key)
+		}
+	default:
+		return rule{}, errors.Errorf("image policy for %s: unknown requirement type %q", scope, req.Type)
+	}
+	if req.Type != TypeSignedBy && len(req.Keys) != 0 {
+		return rule{}, errors.Errorf("image policy for %s: keys can only be set for %s", scope, TypeSignedBy)
+	}
+	return r, nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data found in %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse public key %s", path)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, errors.Errorf("unsupported public key type %T in %s", key, path)
+	}
+}
+
+// ruleFor returns the most specific rule for a repository. Scopes are
+// matched from the full repository name up to the registry host.
+func (p *Policy) ruleFor(name reference.Named) rule {
+	if name == nil {
+		return p.def
+	}
+	scope := name.Name()
+	for {
+		if r, ok := p.scopes[scope]; ok {
+			return r
+		}
+		i := strings.LastIndex(scope, "/")
+		if i < 0 {
+			return p.def
+		}
+		scope = scope[:i]
+	}
+}
+
+// Verify checks the manifest digest resolved for ref against the policy.
+// Signatures are looked up in the local trust directory first, then in repo.
+// It matches the signature of distribution.ImagePullConfig.ManifestVerifier.
+func (p *Policy) Verify(ctx context.Context, repo distribution.Repository, ref reference.Named, dgst digest.Digest) error {
+	r := p.ruleFor(ref)
+	switch r.typ {
+	case TypeAccept:
+		return nil
+	case TypeReject:
+		return errdefs.Forbidden(errors.Errorf("image policy rejects images from %s", reference.FamiliarName(ref)))
+	}
+
+	name := reference.TrimNamed(ref)
+	sigs, err := localSignatures(p.trustDir, name, dgst)
+	if err != nil {
+		return err
+	}
+	if r.verify(name, dgst, sigs) {
+		return nil
+	}
+	if repo != nil {
+		sigs, err = registrySignatures(ctx, repo, dgst)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch signatures for %s@%s", reference.FamiliarName(ref), dgst)
+		}
+		if r.verify(name, dgst, sigs) {
+			return nil
+		}
+	}
+	return errdefs.Forbidden(errors.Errorf("image policy requires a valid signature for %s@%s", reference.FamiliarName(ref), dgst))
+}
+
+// VerifyUnknownOrigin checks an image which has no registry digest, for
+// example because it was built or loaded locally, against the policy. ref
+// may be nil if the image was referenced by ID, in which case the default
+// requirement applies.
+func (p *Policy) VerifyUnknownOrigin(ref reference.Named) error {
+	r := p.ruleFor(ref)
+	name := "image"
+	if ref != nil {
+		name = reference.FamiliarName(ref)
+	}
+	switch r.typ {
+	case TypeAccept:
+		return nil
+	case TypeReject:
+		return errdefs.Forbidden(errors.Errorf("image policy rejects %s", name))
+	default:
+		return errdefs.Forbidden(errors.Errorf("image policy requires a signature for %s, but its origin is unknown and it has no registry digest to verify", name))
+	}
+}
+
+// verify returns true if any of the signatures is valid for the rule.
+func (r rule) verify(name reference.Named, dgst digest.Digest, sigs []signature) bool {
+	for _, sig := range sigs {
+		if err := sig.verify(r.keys, name, dgst); err != nil {
+			logrus.WithError(err).Debugf("ignoring signature for %s@%s", name, dgst)
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package policy // import "github.com/docker/docker/distribution/policy"
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/errdefs"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/opencontainers/go-digest"
+)
+
+func writePublicKey(t *testing.T, dir string, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NilError(t, err)
+	path := filepath.Join(dir, "key.pem")
+	err = ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+	assert.NilError(t, err)
+	return path
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, name string, dgst digest.Digest) []byte {
+	var p Payload
+	p.Critical.Type = SignatureType
+	p.Critical.Identity.DockerReference = name
+	p.Critical.Image.DockerManifestDigest = dgst
+	payload, err := json.Marshal(p)
+	assert.NilError(t, err)
+
+	sum := sha256.Sum256(payload)
+	sig, err := key.Sign(rand.Reader, sum[:], nil)
+	assert.NilError(t, err)
+
+	b, err := json.Marshal(signature{Payload: payload, Signature: sig})
+	assert.NilError(t, err)
+	return b
+}
+
+func writeSignature(t *testing.T, trustDir string, name reference.Named, dgst digest.Digest, sig []byte) {
+	dir := signatureDir(trustDir, name, dgst)
+	assert.NilError(t, os.MkdirAll(dir, 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "signature-1"), sig, 0644))
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		doc    string
+		config Config
+	}{
+		{
+			doc:    "unknown type",
+			config: Config{Default: Requirement{Type: "maybe"}},
+		},
+		{
+			doc:    "signed-by without keys",
+			config: Config{Default: Requirement{Type: TypeSignedBy}},
+		},
+		{
+			doc:    "keys for reject",
+			config: Config{Default: Requirement{Type: TypeReject, Keys: []string{"/key.pem"}}},
+		},
+		{
+			doc:    "missing key file",
+			config: Config{Default: Requirement{Type: TypeSignedBy, Keys: []string{"/does/not/exist.pem"}}},
+		},
+		{
+			doc:    "scope with tag",
+			config: Config{Scopes: map[string]Requirement{"docker.io/library/busybox:latest": {Type: TypeReject}}},
+		},
+		{
+			doc:    "scope with digest",
+			config: Config{Scopes: map[string]Requirement{"docker.io/library/busybox@sha256:abcd": {Type: TypeReject}}},
+		},
+	}
+	for _, tc := range testCases {
+		_, err := New(tc.config)
+		assert.Check(t, is.ErrorContains(err, ""), tc.doc)
+	}
+}
+
+func TestRuleFor(t *testing.T) {
+	p, err := New(Config{
+		Default: Requirement{Type: TypeReject},
+		Scopes: map[string]Requirement{
+			"docker.io":                 {Type: TypeAccept},
+			"docker.io/library/busybox": {Type: TypeReject},
+			"localhost:5000":            {Type: TypeAccept},
+		},
+	})
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		ref      string
+		expected string
+	}{
+		{ref: "busybox", expected: TypeReject},
+		{ref: "busybox:1.0", expected: TypeReject},
+		{ref: "alpine", expected: TypeAccept},
+		{ref: "someuser/app", expected: TypeAccept},
+		{ref: "localhost:5000/app", expected: TypeAccept},
+		{ref: "registry.example.com/app", expected: TypeReject},
+	}
+	for _, tc := range testCases {
+		ref, err := reference.ParseNormalizedNamed(tc.ref)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(tc.expected, p.ruleFor(ref).typ), tc.ref)
+	}
+	assert.Check(t, is.Equal(TypeReject, p.ruleFor(nil).typ))
+}
+
+func TestVerifyLocalSignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-policy")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	trustDir := filepath.Join(dir, "trust")
+	p, err := New(Config{
+		Default:  Requirement{Type: TypeSignedBy, Keys: []string{writePublicKey(t, dir, key)}},
+		TrustDir: trustDir,
+	})
+	assert.NilError(t, err)
+
+	ctx := context.Background()
+	signed := digest.FromString("signed")
+	wrongKey := digest.FromString("wrong key")
+	wrongRepo := digest.FromString("wrong repository")
+	unsigned := digest.FromString("unsigned")
+
+	ref, err := reference.ParseNormalizedNamed("registry.example.com/app:1.0")
+	assert.NilError(t, err)
+	name := reference.TrimNamed(ref)
+
+	writeSignature(t, trustDir, name, signed, sign(t, key, "registry.example.com/app:1.0", signed))
+	writeSignature(t, trustDir, name, wrongKey, sign(t, otherKey, "registry.example.com/app", wrongKey))
+	writeSignature(t, trustDir, name, wrongRepo, sign(t, key, "registry.example.com/other", wrongRepo))
+
+	assert.Check(t, p.Verify(ctx, nil, ref, signed))
+	for _, dgst := range []digest.Digest{wrongKey, wrongRepo, unsigned} {
+		err := p.Verify(ctx, nil, ref, dgst)
+		assert.Check(t, errdefs.IsForbidden(err), dgst)
+	}
+}
+
+func TestVerifyUnknownOrigin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-policy")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	p, err := New(Config{
+		Scopes: map[string]Requirement{
+			"docker.io/library":        {Type: TypeReject},
+			"registry.example.com/app": {Type: TypeSignedBy, Keys: []string{writePublicKey(t, dir, key)}},
+		},
+	})
+	assert.NilError(t, err)
+
+	for _, name := range []string{"busybox", "registry.example.com/app"} {
+		ref, err := reference.ParseNormalizedNamed(name)
+		assert.NilError(t, err)
+		assert.Check(t, errdefs.IsForbidden(p.VerifyUnknownOrigin(ref)), name)
+	}
+	ref, err := reference.ParseNormalizedNamed("someuser/app")
+	assert.NilError(t, err)
+	assert.Check(t, p.VerifyUnknownOrigin(ref))
+	assert.Check(t, p.VerifyUnknownOrigin(nil))
+}
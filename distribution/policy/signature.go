@@ -0,0 +1,194 @@
+package policy // import "github.com/docker/docker/distribution/policy"
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/docker/distribution/registry/api/v2"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// SignatureType is the type recorded in the payload of every image signature.
+const SignatureType = "docker image signature"
+
+// maxSignatureSize limits the size of a single signature document.
+const maxSignatureSize = 64 * 1024
+
+// signature is a detached signature of an image manifest digest. Payload is
+// the JSON encoded Payload, and Signature the signature of its SHA-256
+// digest: ASN.1 encoded for ECDSA keys, PKCS #1 v1.5 for RSA keys.
+type signature struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// Payload is the signed content of an image signature. It binds a manifest
+// digest to the repository it was published in.
+type Payload struct {
+	Critical struct {
+		Type     string `json:"type"`
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// verify checks the signature against keys, and that its payload refers to
+// the manifest dgst in repository name.
+func (s signature) verify(keys []crypto.PublicKey, name reference.Named, dgst digest.Digest) error {
+	sum := sha256.Sum256(s.Payload)
+	var verified bool
+	for _, key := range keys {
+		if verifyDigest(key, sum[:], s.Signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return errors.New("signature does not match any trusted key")
+	}
+
+	var p Payload
+	if err := json.Unmarshal(s.Payload, &p); err != nil {
+		return errors.Wrap(err, "invalid signature payload")
+	}
+	if p.Critical.Type != SignatureType {
+		return errors.Errorf("unexpected signature type %q", p.Critical.Type)
+	}
+	if p.Critical.Image.DockerManifestDigest != dgst {
+		return errors.Errorf("signature is for manifest %s", p.Critical.Image.DockerManifestDigest)
+	}
+	identity, err := reference.ParseNormalizedNamed(p.Critical.Identity.DockerReference)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature identity")
+	}
+	if identity.Name() != name.Name() {
+		return errors.Errorf("signature is for repository %s", identity.Name())
+	}
+	return nil
+}
+
+func verifyDigest(key crypto.PublicKey, sum, sig []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		var esig struct {
+			R, S *big.Int
+		}
+		if rest, err := asn1.Unmarshal(sig, &esig); err != nil || len(rest) != 0 {
+			return false
+		}
+		return ecdsa.Verify(k, sum, esig.R, esig.S)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum, sig) == nil
+	}
+	return false
+}
+
+func parseSignature(b []byte) (signature, error) {
+	var s signature
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, errors.Wrap(err, "invalid signature")
+	}
+	return s, nil
+}
+
+// signatureDir returns the directory in the trust dir holding the
+// signatures of dgst in repository name.
+func signatureDir(trustDir string, name reference.Named, dgst digest.Digest) string {
+	return filepath.Join(trustDir, filepath.FromSlash(name.Name()), dgst.Algorithm().String()+"-"+dgst.Hex())
+}
+
+// localSignatures reads the signatures of dgst from the trust directory.
+// Files which can't be parsed are skipped.
+func localSignatures(trustDir string, name reference.Named, dgst digest.Digest) ([]signature, error) {
+	if trustDir == "" {
+		return nil, nil
+	}
+	dir := signatureDir(trustDir, name, dgst)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sigs []signature
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() || fi.Size() > maxSignatureSize {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if s, err := parseSignature(b); err == nil {
+			sigs = append(sigs, s)
+		}
+	}
+	return sigs, nil
+}
+
+// SignatureTag returns the tag under which the signatures of the manifest
+// dgst are stored as a registry artifact. Every blob referenced by the
+// manifest with this tag is a signature document.
+func SignatureTag(dgst digest.Digest) string {
+	return dgst.Algorithm().String() + "-" + dgst.Hex() + ".sig"
+}
+
+// registrySignatures fetches the signatures of dgst stored in repo.
+func registrySignatures(ctx context.Context, repo distribution.Repository, dgst digest.Digest) ([]signature, error) {
+	manSvc, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := manSvc.Get(ctx, "", distribution.WithTag(SignatureTag(dgst)))
+	if err != nil {
+		if isManifestUnknown(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blobs := repo.Blobs(ctx)
+	var sigs []signature
+	for _, desc := range manifest.References() {
+		if desc.Size > maxSignatureSize {
+			continue
+		}
+		b, err := blobs.Get(ctx, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if s, err := parseSignature(b); err == nil {
+			sigs = append(sigs, s)
+		}
+	}
+	return sigs, nil
+}
+
+func isManifestUnknown(err error) bool {
+	switch v := err.(type) {
+	case errcode.Errors:
+		return len(v) != 0 && isManifestUnknown(v[0])
+	case errcode.Error:
+		return v.Code == v2.ErrorCodeManifestUnknown
+	case distribution.ErrManifestUnknown, distribution.ErrManifestUnknownRevision:
+		return true
+	}
+	return false
+}
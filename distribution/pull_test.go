@@ -0,0 +1,57 @@
+package distribution // import "github.com/docker/docker/distribution"
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/registry"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/opencontainers/go-digest"
+)
+
+// endpointsRegistryService is a registry.Service which only returns the
+// configured pull endpoints.
+type endpointsRegistryService struct {
+	registry.Service
+	endpoints []registry.APIEndpoint
+}
+
+func (s endpointsRegistryService) ResolveRepository(name reference.Named) (*registry.RepositoryInfo, error) {
+	return &registry.RepositoryInfo{
+		Name:  name,
+		Index: &registrytypes.IndexInfo{Name: reference.Domain(name)},
+	}, nil
+}
+
+func (s endpointsRegistryService) LookupPullEndpoints(hostname string) ([]registry.APIEndpoint, error) {
+	return s.endpoints, nil
+}
+
+func TestPullV1WithManifestVerifier(t *testing.T) {
+	uri, err := url.Parse("https://registry.example.com")
+	assert.NilError(t, err)
+	ref, err := reference.ParseNormalizedNamed("registry.example.com/app:latest")
+	assert.NilError(t, err)
+
+	imagePullConfig := &ImagePullConfig{
+		Config: Config{
+			RegistryService: endpointsRegistryService{
+				endpoints: []registry.APIEndpoint{{URL: uri, Version: registry.APIVersion1}},
+			},
+			ManifestVerifier: func(ctx context.Context, repo distribution.Repository, ref reference.Named, dgst digest.Digest) error {
+				t.Fatal("v1 images have no manifest digest to verify")
+				return nil
+			},
+		},
+	}
+
+	err = Pull(context.Background(), ref, imagePullConfig)
+	assert.Check(t, errdefs.IsForbidden(err), "%v", err)
+	assert.Check(t, is.Error(err, "image policy can't verify images pulled from v1 registry https://registry.example.com"))
+}
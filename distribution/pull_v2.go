@@ -324,7 +324,11 @@ func (ld *v2LayerDescriptor) truncateDownloadFile() error {
 
 func (ld *v2LayerDescriptor) Registered(diffID layer.DiffID) {
 	// Cache mapping from this layer's DiffID to the blobsum
-	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{Digest: ld.digest, SourceRepository: ld.repoInfo.Name.Name()})
+	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{
+		Digest:           ld.digest,
+		SourceRepository: ld.repoInfo.Name.Name(),
+		MediaType:        metadataMediaType(ld.src.MediaType),
+	})
 }
 
 func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named, os string) (tagUpdated bool, err error) {
@@ -378,6 +382,12 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named, os string
 	// the other side speaks the v2 protocol.
 	p.confirmedV2 = true
 
+	if p.config.ManifestVerifier != nil {
+		if err := p.verifyManifest(ctx, ref, manifest); err != nil {
+			return false, err
+		}
+	}
+
 	logrus.Debugf("Pulling ref from V2 registry: %s", reference.FamiliarString(ref))
 	progress.Message(p.config.ProgressOutput, tagOrDigest, "Pulling from "+reference.FamiliarName(p.repo.Named()))
 
@@ -437,6 +447,24 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named, os string
 	return true, nil
 }
 
+// verifyManifest passes the digest of the manifest resolved for ref to the
+// configured ManifestVerifier.
+func (p *v2Puller) verifyManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) error {
+	var (
+		dgst digest.Digest
+		err  error
+	)
+	if m, ok := manifest.(*schema1.SignedManifest); ok {
+		dgst = digest.FromBytes(m.Canonical)
+	} else {
+		dgst, err = schema2ManifestDigest(ref, manifest)
+		if err != nil {
+			return err
+		}
+	}
+	return p.config.ManifestVerifier(ctx, p.repo, ref, dgst)
+}
+
 func (p *v2Puller) pullSchema1(ctx context.Context, ref reference.Reference, unverifiedManifest *schema1.SignedManifest, requestedOS string) (id digest.Digest, manifestDigest digest.Digest, err error) {
 	var verifiedManifest *schema1.Manifest
 	verifiedManifest, err = verifySchema1Manifest(unverifiedManifest, ref)
@@ -781,7 +809,12 @@ func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mf
 }
 
 func (p *v2Puller) pullSchema2Config(ctx context.Context, dgst digest.Digest) (configJSON []byte, err error) {
-	blobs := p.repo.Blobs(ctx)
+	return fetchSchema2Config(ctx, p.repo.Blobs(ctx), dgst)
+}
+
+// fetchSchema2Config fetches the image configuration dgst from blobs and
+// verifies its digest.
+func fetchSchema2Config(ctx context.Context, blobs distribution.BlobStore, dgst digest.Digest) (configJSON []byte, err error) {
 	configJSON, err = blobs.Get(ctx, dgst)
 	if err != nil {
 		return nil, err
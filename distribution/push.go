@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/registry"
 	"github.com/sirupsen/logrus"
@@ -149,6 +150,9 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 // the provided Reader. The caller must close the ReadCloser after reading the
 // compressed data.
 //
+// Layers are compressed with gzip unless compression is archive.Zstd. A
+// level of 0 selects the default compression level.
+//
 // Note that this function returns a reader instead of taking a writer as an
 // argument so that it can be used with httpBlobWriter's ReadFrom method.
 // Using httpBlobWriter's Write method would send a PATCH request for every
@@ -158,18 +162,33 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 // is finished. This allows the caller to make sure the goroutine finishes
 // before it releases any resources connected with the reader that was
 // passed in.
-func compress(in io.Reader) (io.ReadCloser, chan struct{}) {
+func compress(in io.Reader, compression archive.Compression, level int) (io.ReadCloser, chan struct{}) {
 	compressionDone := make(chan struct{})
 
 	pipeReader, pipeWriter := io.Pipe()
 	// Use a bufio.Writer to avoid excessive chunking in HTTP request.
 	bufWriter := bufio.NewWriterSize(pipeWriter, compressionBufSize)
-	compressor := gzip.NewWriter(bufWriter)
 
 	go func() {
-		_, err := io.Copy(compressor, in)
+		var (
+			compressor io.WriteCloser
+			err        error
+		)
+		if compression == archive.Zstd {
+			compressor, err = archive.CompressStreamLevel(bufWriter, archive.Zstd, level)
+		} else {
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			compressor, err = gzip.NewWriterLevel(bufWriter, level)
+		}
 		if err == nil {
-			err = compressor.Close()
+			_, err = io.Copy(compressor, in)
+			// Always close the compressor, so that an external zstd
+			// process is reaped.
+			if closeErr := compressor.Close(); err == nil {
+				err = closeErr
+			}
 		}
 		if err == nil {
 			err = bufWriter.Flush()
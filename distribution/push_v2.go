@@ -20,6 +20,7 @@ import (
 	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/stringid"
@@ -151,6 +152,8 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 		endpoint:          p.endpoint,
 		repo:              p.repo,
 		pushState:         &p.pushState,
+		compression:       p.config.LayerCompression,
+		compressionLevel:  p.config.LayerCompressionLevel,
 	}
 
 	// Loop bounds condition is to avoid pushing the base layer on Windows.
@@ -181,7 +184,8 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 
 	putOptions := []distribution.ManifestServiceOption{distribution.WithTag(ref.Tag())}
 	if _, err = manSvc.Put(ctx, manifest, putOptions...); err != nil {
-		if runtime.GOOS == "windows" || p.config.TrustKey == nil || p.config.RequireSchema2 {
+		// schema1 manifests can only refer to gzip compressed layers
+		if runtime.GOOS == "windows" || p.config.TrustKey == nil || p.config.RequireSchema2 || p.config.LayerCompression == archive.Zstd {
 			logrus.Warnf("failed to upload schema2 manifest: %v", err)
 			return err
 		}
@@ -218,7 +222,11 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 	manifestDigest := digest.FromBytes(canonicalManifest)
 	progress.Messagef(p.config.ProgressOutput, "", "%s: digest: %s size: %d", ref.Tag(), manifestDigest, len(canonicalManifest))
 
-	if err := addDigestReference(p.config.ReferenceStore, ref, manifestDigest, id); err != nil {
+	// The digest reference exempts the image from the image policy at
+	// container create, like the pulled images which were verified.
+	if err := p.verifyPushedManifest(ctx, ref, manifestDigest); err != nil {
+		logrus.WithError(err).Debugf("Not adding digest reference for %s", reference.FamiliarString(ref))
+	} else if err := addDigestReference(p.config.ReferenceStore, ref, manifestDigest, id); err != nil {
 		return err
 	}
 
@@ -229,6 +237,15 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 	return nil
 }
 
+// verifyPushedManifest passes the digest of the pushed manifest of ref to the
+// configured ManifestVerifier.
+func (p *v2Pusher) verifyPushedManifest(ctx context.Context, ref reference.NamedTagged, dgst digest.Digest) error {
+	if p.config.ManifestVerifier == nil {
+		return nil
+	}
+	return p.config.ManifestVerifier(ctx, p.repo, ref, dgst)
+}
+
 func manifestFromBuilder(ctx context.Context, builder distribution.ManifestBuilder, descriptors []xfer.UploadDescriptor) (distribution.Manifest, error) {
 	// descriptors is in reverse order; iterate backwards to get references
 	// appended in the right order.
@@ -251,6 +268,8 @@ type v2PushDescriptor struct {
 	repo              distribution.Repository
 	pushState         *pushState
 	remoteDescriptor  distribution.Descriptor
+	compression       archive.Compression
+	compressionLevel  int
 	// a set of digests whose presence has been checked in a target repository
 	checkedDigests map[digest.Digest]struct{}
 }
@@ -267,6 +286,48 @@ func (pd *v2PushDescriptor) DiffID() layer.DiffID {
 	return pd.layer.DiffID()
 }
 
+// mediaType returns the media type of the blob pushed for the layer.
+func (pd *v2PushDescriptor) mediaType() string {
+	if pd.compression == archive.Zstd && pd.layer.MediaType() == schema2.MediaTypeUncompressedLayer {
+		return MediaTypeZstdLayer
+	}
+	return schema2.MediaTypeLayer
+}
+
+// newMetadata returns the metadata recorded for a blob of the layer in the
+// target repository.
+func (pd *v2PushDescriptor) newMetadata(dgst digest.Digest) metadata.V2Metadata {
+	return metadata.V2Metadata{
+		Digest:           dgst,
+		SourceRepository: pd.repoInfo.Name(),
+		MediaType:        metadataMediaType(pd.mediaType()),
+	}
+}
+
+// metadataMediaType returns the value of V2Metadata.MediaType for a blob of
+// the given media type. Gzip compressed layers are recorded without a media
+// type, as they were before it was tracked.
+func metadataMediaType(mediaType string) string {
+	if mediaType == schema2.MediaTypeLayer {
+		return ""
+	}
+	return mediaType
+}
+
+// filterMetadataByMediaType returns the metadata entries which describe blobs
+// of the given media type. Blobs compressed differently can't be reused for
+// the layer, as the manifest would declare the wrong media type.
+func filterMetadataByMediaType(v2Metadata []metadata.V2Metadata, mediaType string) []metadata.V2Metadata {
+	mediaType = metadataMediaType(mediaType)
+	filtered := make([]metadata.V2Metadata, 0, len(v2Metadata))
+	for _, meta := range v2Metadata {
+		if meta.MediaType == mediaType {
+			filtered = append(filtered, meta)
+		}
+	}
+	return filtered
+}
+
 func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.Output) (distribution.Descriptor, error) {
 	// Skip foreign layers unless this registry allows nondistributable artifacts.
 	if !pd.endpoint.AllowNondistributableArtifacts {
@@ -295,6 +356,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	// Do we have any metadata associated with this layer's DiffID?
 	v2Metadata, err := pd.v2MetadataService.GetMetadata(diffID)
 	if err == nil {
+		v2Metadata = filterMetadataByMediaType(v2Metadata, pd.mediaType())
 		// check for blob existence in the target repository
 		descriptor, exists, err := pd.layerAlreadyExists(ctx, progressOutput, diffID, true, 1, v2Metadata)
 		if exists || err != nil {
@@ -348,7 +410,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 		case distribution.ErrBlobMounted:
 			progress.Updatef(progressOutput, pd.ID(), "Mounted from %s", err.From.Name())
 
-			err.Descriptor.MediaType = schema2.MediaTypeLayer
+			err.Descriptor.MediaType = pd.mediaType()
 
 			pd.pushState.Lock()
 			pd.pushState.confirmedV2 = true
@@ -356,10 +418,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 			pd.pushState.Unlock()
 
 			// Cache mapping from this layer's DiffID to the blobsum
-			if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
-				Digest:           err.Descriptor.Digest,
-				SourceRepository: pd.repoInfo.Name(),
-			}); err != nil {
+			if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, pd.newMetadata(err.Descriptor.Digest)); err != nil {
 				return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 			}
 			return err.Descriptor, nil
@@ -447,7 +506,7 @@ func (pd *v2PushDescriptor) uploadUsingSession(
 
 	switch m := pd.layer.MediaType(); m {
 	case schema2.MediaTypeUncompressedLayer:
-		compressedReader, compressionDone := compress(reader)
+		compressedReader, compressionDone := compress(reader, pd.compression, pd.compressionLevel)
 		defer func(closer io.Closer) {
 			closer.Close()
 			<-compressionDone
@@ -477,16 +536,13 @@ func (pd *v2PushDescriptor) uploadUsingSession(
 	progress.Update(progressOutput, pd.ID(), "Pushed")
 
 	// Cache mapping from this layer's DiffID to the blobsum
-	if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
-		Digest:           pushDigest,
-		SourceRepository: pd.repoInfo.Name(),
-	}); err != nil {
+	if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, pd.newMetadata(pushDigest)); err != nil {
 		return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 	}
 
 	desc := distribution.Descriptor{
 		Digest:    pushDigest,
-		MediaType: schema2.MediaTypeLayer,
+		MediaType: pd.mediaType(),
 		Size:      nn,
 	}
 
@@ -552,14 +608,11 @@ attempts:
 		case nil:
 			if m, ok := digestToMetadata[desc.Digest]; !ok || m.SourceRepository != pd.repoInfo.Name() || !metadata.CheckV2MetadataHMAC(m, pd.hmacKey) {
 				// cache mapping from this layer's DiffID to the blobsum
-				if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
-					Digest:           desc.Digest,
-					SourceRepository: pd.repoInfo.Name(),
-				}); err != nil {
+				if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, pd.newMetadata(desc.Digest)); err != nil {
 					return distribution.Descriptor{}, false, xfer.DoNotRetry{Err: err}
 				}
 			}
-			desc.MediaType = schema2.MediaTypeLayer
+			desc.MediaType = pd.mediaType()
 			exists = true
 			break attempts
 		case distribution.ErrBlobUnknown:
@@ -14,6 +14,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	refstore "github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -738,3 +739,35 @@ func (s *progressSink) WriteProgress(p progress.Progress) error {
 	s.t.Logf("progress update: %#+v", p)
 	return nil
 }
+
+func TestPushDescriptorMediaType(t *testing.T) {
+	repoInfo, _ := reference.ParseNormalizedNamed("user/app")
+	gzipMeta := metadata.V2Metadata{Digest: digest.Digest("sha256:gzip"), SourceRepository: "docker.io/user/other"}
+	zstdMeta := metadata.V2Metadata{Digest: digest.Digest("sha256:zstd"), SourceRepository: "docker.io/user/other", MediaType: MediaTypeZstdLayer}
+	v2Metadata := []metadata.V2Metadata{gzipMeta, zstdMeta}
+
+	for _, tc := range []struct {
+		compression archive.Compression
+		mediaType   string
+		expected    metadata.V2Metadata
+	}{
+		{compression: archive.Gzip, mediaType: schema2.MediaTypeLayer, expected: gzipMeta},
+		{compression: archive.Zstd, mediaType: MediaTypeZstdLayer, expected: zstdMeta},
+	} {
+		pd := &v2PushDescriptor{
+			repoInfo:    repoInfo,
+			layer:       &storeLayer{Layer: layer.EmptyLayer},
+			compression: tc.compression,
+		}
+		if mt := pd.mediaType(); mt != tc.mediaType {
+			t.Errorf("expected media type %q, got %q", tc.mediaType, mt)
+		}
+		filtered := filterMetadataByMediaType(v2Metadata, pd.mediaType())
+		if !reflect.DeepEqual(filtered, []metadata.V2Metadata{tc.expected}) {
+			t.Errorf("unexpected mount candidates for %s: %v", tc.mediaType, filtered)
+		}
+		if meta := pd.newMetadata("sha256:new"); meta.MediaType != tc.expected.MediaType {
+			t.Errorf("expected recorded media type %q, got %q", tc.expected.MediaType, meta.MediaType)
+		}
+	}
+}
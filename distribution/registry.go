@@ -37,6 +37,10 @@ var PluginTypes = []string{
 	schema2.MediaTypePluginConfig,
 }
 
+// MediaTypeZstdLayer is the media type used in schema2 manifests for layers
+// compressed with zstd.
+const MediaTypeZstdLayer = "application/vnd.docker.image.rootfs.diff.tar.zstd"
+
 var mediaTypeClasses map[string]string
 
 func init() {
@@ -23,5 +23,8 @@ swagger generate operation -f api/swagger.yaml \
     -n ContainerUpdate \
     -n ContainerWait \
     -n ImageHistory \
+    -n VolumesClone \
     -n VolumesCreate \
-    -n VolumesList
+    -n VolumesList \
+    -n VolumesSnapshot \
+    -n VolumesUpdate
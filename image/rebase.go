@@ -0,0 +1,252 @@
+package image // import "github.com/docker/docker/image"
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/layer"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// NewRebasedImage creates a new Image from img, which was built on top of
+// oldBase, with the layers of oldBase replaced by those of newBase. The
+// layers img adds to oldBase are kept, their history entries are appended
+// to the history of newBase, and the configuration img inherited from
+// oldBase is replaced by the configuration of newBase. The fields
+// describing how img was built, such as its parent, are cleared.
+//
+// An error is returned if img is not based on oldBase, or if newBase is not
+// compatible with img.
+func NewRebasedImage(img, oldBase, newBase *Image) (*Image, error) {
+	if img.RootFS == nil || oldBase.RootFS == nil || newBase.RootFS == nil {
+		return nil, errors.New("images without a root filesystem cannot be rebased")
+	}
+	if img.OperatingSystem() != newBase.OperatingSystem() {
+		return nil, errors.Errorf("cannot rebase a %s image on a %s image", img.OperatingSystem(), newBase.OperatingSystem())
+	}
+	if img.BaseImgArch() != newBase.BaseImgArch() {
+		return nil, errors.Errorf("cannot rebase a %s image on a %s image", img.BaseImgArch(), newBase.BaseImgArch())
+	}
+
+	appDiffIDs, err := appLayers(img, oldBase)
+	if err != nil {
+		return nil, err
+	}
+	appHistory, err := appHistory(img, oldBase)
+	if err != nil {
+		return nil, err
+	}
+
+	rootFS := NewRootFS()
+	rootFS.Type = newBase.RootFS.Type
+	rootFS.DiffIDs = make([]layer.DiffID, 0, len(newBase.RootFS.DiffIDs)+len(appDiffIDs))
+	rootFS.DiffIDs = append(rootFS.DiffIDs, newBase.RootFS.DiffIDs...)
+	rootFS.DiffIDs = append(rootFS.DiffIDs, appDiffIDs...)
+
+	history := make([]History, 0, len(newBase.History)+len(appHistory))
+	history = append(history, newBase.History...)
+	history = append(history, appHistory...)
+
+	rebased := &Image{
+		V1Image:    img.V1Image,
+		RootFS:     rootFS,
+		History:    history,
+		OSVersion:  newBase.OSVersion,
+		OSFeatures: newBase.OSFeatures,
+	}
+	// The parent image and the container the image was committed from
+	// describe how img was built on top of oldBase: they don't apply to
+	// the rebased image.
+	rebased.V1Image.ID = ""
+	rebased.V1Image.Parent = ""
+	rebased.Container = ""
+	rebased.ContainerConfig = container.Config{}
+	rebased.Created = time.Now().UTC()
+	rebased.Config = rebaseConfig(img.Config, oldBase.Config, newBase.Config)
+	return rebased, nil
+}
+
+// appLayers returns the diff IDs img adds to oldBase.
+func appLayers(img, oldBase *Image) ([]layer.DiffID, error) {
+	base := oldBase.RootFS.DiffIDs
+	if len(base) > len(img.RootFS.DiffIDs) {
+		return nil, errors.New("image is not based on the old base image: the base image has more layers")
+	}
+	for i, diffID := range base {
+		if img.RootFS.DiffIDs[i] != diffID {
+			return nil, errors.Errorf("image is not based on the old base image: layer %d differs", i)
+		}
+	}
+	return img.RootFS.DiffIDs[len(base):], nil
+}
+
+// appHistory returns the history entries img adds to oldBase. Images
+// without history are accepted, as history is optional.
+func appHistory(img, oldBase *Image) ([]History, error) {
+	if len(img.History) == 0 {
+		return nil, nil
+	}
+	if len(oldBase.History) > len(img.History) {
+		return nil, errors.New("image is not based on the old base image: the base image has more history entries")
+	}
+	for i, h := range oldBase.History {
+		if !historyEqual(img.History[i], h) {
+			return nil, errors.Errorf("image is not based on the old base image: history entry %d differs", i)
+		}
+	}
+	return img.History[len(oldBase.History):], nil
+}
+
+func historyEqual(a, b History) bool {
+	return a.Created.Equal(b.Created) &&
+		a.Author == b.Author &&
+		a.CreatedBy == b.CreatedBy &&
+		a.Comment == b.Comment &&
+		a.EmptyLayer == b.EmptyLayer
+}
+
+// rebaseConfig replaces the values config inherited from oldBase by those
+// of newBase. Values set or changed on top of oldBase are kept. Environment
+// variables, labels, exposed ports and volumes are merged key by key.
+func rebaseConfig(config, oldBase, newBase *container.Config) *container.Config {
+	if config == nil {
+		return nil
+	}
+	if oldBase == nil {
+		oldBase = &container.Config{}
+	}
+	if newBase == nil {
+		newBase = &container.Config{}
+	}
+
+	c := *config
+	if reflect.DeepEqual(c.User, oldBase.User) {
+		c.User = newBase.User
+	}
+	if reflect.DeepEqual(c.Cmd, oldBase.Cmd) {
+		c.Cmd = newBase.Cmd
+	}
+	if reflect.DeepEqual(c.Entrypoint, oldBase.Entrypoint) {
+		c.Entrypoint = newBase.Entrypoint
+	}
+	if reflect.DeepEqual(c.Healthcheck, oldBase.Healthcheck) {
+		c.Healthcheck = newBase.Healthcheck
+	}
+	if reflect.DeepEqual(c.WorkingDir, oldBase.WorkingDir) {
+		c.WorkingDir = newBase.WorkingDir
+	}
+	if reflect.DeepEqual(c.StopSignal, oldBase.StopSignal) {
+		c.StopSignal = newBase.StopSignal
+	}
+	if reflect.DeepEqual(c.StopTimeout, oldBase.StopTimeout) {
+		c.StopTimeout = newBase.StopTimeout
+	}
+	if reflect.DeepEqual(c.Shell, oldBase.Shell) {
+		c.Shell = newBase.Shell
+	}
+	c.Env = rebaseEnv(c.Env, oldBase.Env, newBase.Env)
+	c.Labels = rebaseLabels(c.Labels, oldBase.Labels, newBase.Labels)
+
+	if ports := rebaseSet(portSetKeys(c.ExposedPorts), portSetKeys(oldBase.ExposedPorts), portSetKeys(newBase.ExposedPorts)); ports != nil {
+		c.ExposedPorts = make(nat.PortSet, len(ports))
+		for p := range ports {
+			c.ExposedPorts[nat.Port(p)] = struct{}{}
+		}
+	} else {
+		c.ExposedPorts = nil
+	}
+	c.Volumes = rebaseSet(c.Volumes, oldBase.Volumes, newBase.Volumes)
+	return &c
+}
+
+// rebaseEnv returns the environment of newBase, followed by the variables
+// env sets to a value different from oldBase.
+func rebaseEnv(env, oldBase, newBase []string) []string {
+	old := make(map[string]string, len(oldBase))
+	for _, kv := range oldBase {
+		k, v := splitEnv(kv)
+		old[k] = v
+	}
+
+	result := make([]string, 0, len(newBase)+len(env))
+	index := make(map[string]int, len(newBase))
+	for _, kv := range newBase {
+		k, _ := splitEnv(kv)
+		index[k] = len(result)
+		result = append(result, kv)
+	}
+	for _, kv := range env {
+		k, v := splitEnv(kv)
+		if oldValue, ok := old[k]; ok && oldValue == v {
+			continue
+		}
+		if i, ok := index[k]; ok {
+			result[i] = kv
+			continue
+		}
+		index[k] = len(result)
+		result = append(result, kv)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func splitEnv(kv string) (string, string) {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// rebaseLabels returns the labels of newBase, overridden by the labels
+// which are set to a value different from oldBase.
+func rebaseLabels(labels, oldBase, newBase map[string]string) map[string]string {
+	if len(labels) == 0 && len(newBase) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(newBase)+len(labels))
+	for k, v := range newBase {
+		result[k] = v
+	}
+	for k, v := range labels {
+		if oldValue, ok := oldBase[k]; ok && oldValue == v {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// rebaseSet returns the union of newBase and the keys set adds to oldBase.
+func rebaseSet(set, oldBase, newBase map[string]struct{}) map[string]struct{} {
+	if len(set) == 0 && len(newBase) == 0 {
+		return nil
+	}
+	result := make(map[string]struct{}, len(newBase)+len(set))
+	for k := range newBase {
+		result[k] = struct{}{}
+	}
+	for k := range set {
+		if _, ok := oldBase[k]; !ok {
+			result[k] = struct{}{}
+		}
+	}
+	return result
+}
+
+func portSetKeys(ports nat.PortSet) map[string]struct{} {
+	if ports == nil {
+		return nil
+	}
+	keys := make(map[string]struct{}, len(ports))
+	for p := range ports {
+		keys[string(p)] = struct{}{}
+	}
+	return keys
+}
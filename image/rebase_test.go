@@ -0,0 +1,103 @@
+package image // import "github.com/docker/docker/image"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/layer"
+	"github.com/docker/go-connections/nat"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func newTestImage(config *container.Config, diffIDs []layer.DiffID, history ...History) *Image {
+	return &Image{
+		V1Image: V1Image{
+			Architecture: "amd64",
+			OS:           "linux",
+			Config:       config,
+		},
+		RootFS:  &RootFS{Type: TypeLayers, DiffIDs: diffIDs},
+		History: history,
+	}
+}
+
+func TestNewRebasedImage(t *testing.T) {
+	created := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldBase := newTestImage(&container.Config{
+		Env:          []string{"PATH=/usr/bin", "BASE_VERSION=1"},
+		Cmd:          []string{"sh"},
+		Labels:       map[string]string{"base": "1", "vendor": "acme"},
+		ExposedPorts: nat.PortSet{"22/tcp": {}},
+	}, []layer.DiffID{"sha256:old"},
+		History{Created: created, CreatedBy: "ADD old.tar /"},
+	)
+	newBase := newTestImage(&container.Config{
+		Env:    []string{"PATH=/usr/local/bin:/usr/bin", "BASE_VERSION=2"},
+		Cmd:    []string{"bash"},
+		Labels: map[string]string{"base": "2"},
+	}, []layer.DiffID{"sha256:new1", "sha256:new2"},
+		History{Created: created, CreatedBy: "ADD new.tar /"},
+		History{Created: created, CreatedBy: "RUN patch"},
+	)
+	appHistory := History{Created: created, CreatedBy: "COPY app /app"}
+	img := newTestImage(&container.Config{
+		Env:          []string{"PATH=/usr/bin", "BASE_VERSION=1", "APP=1"},
+		Cmd:          []string{"sh"},
+		Entrypoint:   []string{"/app"},
+		Labels:       map[string]string{"base": "1", "vendor": "acme", "app": "1"},
+		ExposedPorts: nat.PortSet{"22/tcp": {}, "80/tcp": {}},
+	}, []layer.DiffID{"sha256:old", "sha256:app"},
+		oldBase.History[0], appHistory,
+	)
+
+	img.Parent = "sha256:oldbase"
+	img.V1Image.Parent = "oldbase"
+	img.Container = "abc"
+	img.ContainerConfig = container.Config{Cmd: []string{"/bin/sh", "-c", "#(nop) COPY app /app"}}
+
+	rebased, err := NewRebasedImage(img, oldBase, newBase)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(ID(""), rebased.Parent))
+	assert.Check(t, is.Equal("", rebased.V1Image.Parent))
+	assert.Check(t, is.Equal("", rebased.Container))
+	assert.Check(t, is.DeepEqual(container.Config{}, rebased.ContainerConfig))
+
+	assert.Check(t, is.DeepEqual([]layer.DiffID{"sha256:new1", "sha256:new2", "sha256:app"}, rebased.RootFS.DiffIDs))
+	assert.Check(t, is.Len(rebased.History, 3))
+	assert.Check(t, is.Equal("COPY app /app", rebased.History[2].CreatedBy))
+	assert.Check(t, is.Equal("RUN patch", rebased.History[1].CreatedBy))
+
+	config := rebased.Config
+	assert.Check(t, is.DeepEqual([]string{"PATH=/usr/local/bin:/usr/bin", "BASE_VERSION=2", "APP=1"}, config.Env))
+	assert.Check(t, is.DeepEqual([]string{"bash"}, []string(config.Cmd)))
+	assert.Check(t, is.DeepEqual([]string{"/app"}, []string(config.Entrypoint)))
+	assert.Check(t, is.DeepEqual(map[string]string{"base": "2", "app": "1"}, config.Labels))
+	assert.Check(t, is.DeepEqual(nat.PortSet{"80/tcp": {}}, config.ExposedPorts))
+
+	// the original image is left untouched
+	assert.Check(t, is.DeepEqual([]layer.DiffID{"sha256:old", "sha256:app"}, img.RootFS.DiffIDs))
+	assert.Check(t, is.Len(img.Config.Env, 3))
+}
+
+func TestNewRebasedImageIncompatible(t *testing.T) {
+	created := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldBase := newTestImage(nil, []layer.DiffID{"sha256:old"}, History{Created: created, CreatedBy: "ADD old.tar /"})
+	newBase := newTestImage(nil, []layer.DiffID{"sha256:new"})
+
+	otherBase := newTestImage(nil, []layer.DiffID{"sha256:other", "sha256:app"})
+	_, err := NewRebasedImage(otherBase, oldBase, newBase)
+	assert.Check(t, is.ErrorContains(err, "not based on the old base image"))
+
+	otherHistory := newTestImage(nil, []layer.DiffID{"sha256:old", "sha256:app"},
+		History{Created: created, CreatedBy: "ADD other.tar /"}, History{Created: created})
+	_, err = NewRebasedImage(otherHistory, oldBase, newBase)
+	assert.Check(t, is.ErrorContains(err, "history entry 0 differs"))
+
+	img := newTestImage(nil, []layer.DiffID{"sha256:old", "sha256:app"})
+	windowsBase := newTestImage(nil, []layer.DiffID{"sha256:new"})
+	windowsBase.OS = "windows"
+	_, err = NewRebasedImage(img, oldBase, windowsBase)
+	assert.Check(t, is.ErrorContains(err, "cannot rebase a linux image on a windows image"))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/layer"
 	"github.com/gotestyourself/gotestyourself/assert"
@@ -172,6 +173,25 @@ func TestGetAndSetLastUpdated(t *testing.T) {
 	assert.Check(t, cmp.Equal(updated.IsZero(), false))
 }
 
+func TestGetAndSetLastUsed(t *testing.T) {
+	store, cleanup := defaultImageStore(t)
+	defer cleanup()
+
+	before := time.Now()
+	id, err := store.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	assert.NilError(t, err)
+
+	created, err := store.GetLastUsed(id)
+	assert.NilError(t, err)
+	assert.Check(t, !created.Before(before))
+
+	assert.Check(t, store.SetLastUsed(id))
+
+	used, err := store.GetLastUsed(id)
+	assert.NilError(t, err)
+	assert.Check(t, !used.Before(created))
+}
+
 func TestStoreLen(t *testing.T) {
 	store, cleanup := defaultImageStore(t)
 	defer cleanup()
@@ -408,7 +408,15 @@ func (s *saveSession) saveLayer(id layer.ChainID, legacyImg image.V1Image, creat
 		}
 		defer arch.Close()
 
-		if _, err := io.Copy(tarFile, arch); err != nil {
+		compressed, err := archive.CompressStream(tarFile, s.compression)
+		if err != nil {
+			return distribution.Descriptor{}, err
+		}
+		if _, err := io.Copy(compressed, arch); err != nil {
+			compressed.Close()
+			return distribution.Descriptor{}, err
+		}
+		if err := compressed.Close(); err != nil {
 			return distribution.Descriptor{}, err
 		}
 
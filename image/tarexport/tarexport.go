@@ -4,6 +4,7 @@ import (
 	"github.com/docker/distribution"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	refstore "github.com/docker/docker/reference"
 )
 
@@ -28,6 +29,7 @@ type tarexporter struct {
 	lss            map[string]layer.Store
 	rs             refstore.Store
 	loggerImgEvent LogImageEvent
+	compression    archive.Compression
 }
 
 // LogImageEvent defines interface for event generation related to image tar(load and save) operations
@@ -45,3 +47,16 @@ func NewTarExporter(is image.Store, lss map[string]layer.Store, rs refstore.Stor
 		loggerImgEvent: loggerImgEvent,
 	}
 }
+
+// NewCompressedTarExporter returns new Exporter for tar packages, which
+// compresses the layers of the images it saves with compression. The layers
+// keep their layer.tar name, their compression is detected when loading.
+func NewCompressedTarExporter(is image.Store, lss map[string]layer.Store, rs refstore.Store, loggerImgEvent LogImageEvent, compression archive.Compression) image.Exporter {
+	return &tarexporter{
+		is:             is,
+		lss:            lss,
+		rs:             rs,
+		loggerImgEvent: loggerImgEvent,
+		compression:    compression,
+	}
+}
@@ -0,0 +1,120 @@
+package container // import "github.com/docker/docker/integration/container"
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/integration/internal/container"
+	"github.com/docker/docker/internal/test/request"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/poll"
+	"github.com/gotestyourself/gotestyourself/skip"
+)
+
+// TestCheckpointExportImport exports a checkpoint of a container and creates
+// a new container from it.
+func TestCheckpointExportImport(t *testing.T) {
+	skip.If(t, testEnv.DaemonInfo.OSType != "linux")
+	skip.If(t, !testEnv.DaemonInfo.ExperimentalBuild)
+	skip.If(t, testEnv.IsRemoteDaemon())
+	_, err := exec.LookPath("criu")
+	skip.If(t, err != nil, "criu is not installed")
+
+	defer setupTest(t)()
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+
+	cID := container.Run(t, ctx, client,
+		container.WithCmd("sh", "-c", "echo exported > /exported && while true; do sleep 1; done"),
+		container.WithVolume("/anonymous"),
+		func(c *container.TestContainerConfig) {
+			c.HostConfig.Mounts = []mounttypes.Mount{{Type: mounttypes.TypeVolume, Source: "checkpoint-data", Target: "/data"}}
+		},
+	)
+	poll.WaitOn(t, container.IsInState(ctx, client, cID, "running"), poll.WithDelay(100*time.Millisecond))
+
+	exported, err := client.ContainerInspect(ctx, cID)
+	assert.NilError(t, err)
+
+	err = client.CheckpointCreate(ctx, cID, types.CheckpointCreateOptions{CheckpointID: "cp"})
+	assert.NilError(t, err)
+	bundle, err := client.CheckpointExport(ctx, cID, types.CheckpointExportOptions{CheckpointID: "cp"})
+	assert.NilError(t, err)
+	defer bundle.Close()
+
+	err = client.ContainerRemove(ctx, cID, types.ContainerRemoveOptions{Force: true})
+	assert.NilError(t, err)
+
+	created, err := client.CheckpointImport(ctx, bundle, types.CheckpointImportOptions{Name: "imported", Start: true})
+	assert.NilError(t, err)
+	poll.WaitOn(t, container.IsInState(ctx, client, created.ID, "running"), poll.WithDelay(100*time.Millisecond))
+
+	imported, err := client.ContainerInspect(ctx, created.ID)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(exported.Config.Cmd, imported.Config.Cmd))
+	assert.Check(t, is.Equal(exported.Image, imported.Image))
+
+	volumes := make(map[string]string)
+	for _, m := range imported.Mounts {
+		volumes[m.Destination] = m.Name
+	}
+	for _, m := range exported.Mounts {
+		assert.Check(t, is.Equal(m.Name, volumes[m.Destination]), "mount %s", m.Destination)
+	}
+
+	changes, err := client.ContainerDiff(ctx, created.ID)
+	assert.NilError(t, err)
+	var found bool
+	for _, c := range changes {
+		found = found || c.Path == "/exported"
+	}
+	assert.Check(t, found, "changes of the exported container are missing: %v", changes)
+}
+
+// TestCheckpointImportHostAccess checks that the settings giving access to
+// the host are only taken from the host configuration passed by the client.
+func TestCheckpointImportHostAccess(t *testing.T) {
+	skip.If(t, testEnv.DaemonInfo.OSType != "linux")
+	skip.If(t, !testEnv.DaemonInfo.ExperimentalBuild)
+	skip.If(t, testEnv.IsRemoteDaemon())
+	_, err := exec.LookPath("criu")
+	skip.If(t, err != nil, "criu is not installed")
+
+	defer setupTest(t)()
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+
+	cID := container.Run(t, ctx, client,
+		container.WithCmd("sh", "-c", "while true; do sleep 1; done"),
+		container.WithBind("/etc", "/host-etc"),
+	)
+	poll.WaitOn(t, container.IsInState(ctx, client, cID, "running"), poll.WithDelay(100*time.Millisecond))
+
+	err = client.CheckpointCreate(ctx, cID, types.CheckpointCreateOptions{CheckpointID: "cp", Exit: true})
+	assert.NilError(t, err)
+
+	bundle, err := client.CheckpointExport(ctx, cID, types.CheckpointExportOptions{CheckpointID: "cp"})
+	assert.NilError(t, err)
+	_, err = client.CheckpointImport(ctx, bundle, types.CheckpointImportOptions{Name: "imported-bind"})
+	bundle.Close()
+	assert.Check(t, is.ErrorContains(err, "bind /etc:/host-etc"))
+
+	bundle, err = client.CheckpointExport(ctx, cID, types.CheckpointExportOptions{CheckpointID: "cp"})
+	assert.NilError(t, err)
+	defer bundle.Close()
+	created, err := client.CheckpointImport(ctx, bundle, types.CheckpointImportOptions{
+		Name:       "imported-bind",
+		HostConfig: &containertypes.HostConfig{Binds: []string{"/etc:/host-etc:ro"}},
+	})
+	assert.NilError(t, err)
+
+	imported, err := client.ContainerInspect(ctx, created.ID)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"/etc:/host-etc:ro"}, imported.HostConfig.Binds))
+}
@@ -10,6 +10,7 @@ import (
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/integration/internal/container"
 	"github.com/docker/docker/internal/test/request"
+	"github.com/docker/go-connections/nat"
 	"github.com/gotestyourself/gotestyourself/assert"
 	is "github.com/gotestyourself/gotestyourself/assert/cmp"
 	"github.com/gotestyourself/gotestyourself/poll"
@@ -105,3 +106,50 @@ func TestUpdateCPUQuota(t *testing.T) {
 		assert.Check(t, is.Equal(strconv.FormatInt(test.update, 10), strings.TrimSpace(res.Stdout())))
 	}
 }
+
+func TestUpdatePortBindings(t *testing.T) {
+	skip.If(t, testEnv.IsRemoteDaemon(), "cannot allocate host ports on a remote daemon")
+
+	defer setupTest(t)()
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+
+	bindings := func(hostPort string) nat.PortMap {
+		return nat.PortMap{"80/tcp": []nat.PortBinding{{HostPort: hostPort}}}
+	}
+
+	// The other container holds the host port used for the conflict.
+	container.Run(t, ctx, client, container.WithExposedPorts("80/tcp"), func(c *container.TestContainerConfig) {
+		c.HostConfig.PortBindings = bindings("28081")
+	})
+	cID := container.Run(t, ctx, client, container.WithExposedPorts("80/tcp"), func(c *container.TestContainerConfig) {
+		c.HostConfig.PortBindings = bindings("28080")
+	})
+	poll.WaitOn(t, container.IsInState(ctx, client, cID, "running"), poll.WithDelay(100*time.Millisecond))
+
+	_, err := client.ContainerUpdate(ctx, cID, containertypes.UpdateConfig{
+		PortBindings: bindings("28082"),
+	})
+	assert.NilError(t, err)
+
+	inspect, err := client.ContainerInspect(ctx, cID)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(bindings("28082"), inspect.HostConfig.PortBindings))
+	assert.Assert(t, is.Len(inspect.NetworkSettings.Ports["80/tcp"], 1))
+	assert.Check(t, is.Equal("28082", inspect.NetworkSettings.Ports["80/tcp"][0].HostPort))
+	// The container is still connected to its network after the update.
+	assert.Check(t, is.Len(inspect.NetworkSettings.Networks, 1))
+	assert.Check(t, inspect.NetworkSettings.Networks["bridge"].IPAddress != "")
+
+	_, err = client.ContainerUpdate(ctx, cID, containertypes.UpdateConfig{
+		PortBindings: bindings("28081"),
+	})
+	assert.Check(t, is.ErrorContains(err, "port is already allocated"))
+
+	inspect, err = client.ContainerInspect(ctx, cID)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(bindings("28082"), inspect.HostConfig.PortBindings))
+	assert.Assert(t, is.Len(inspect.NetworkSettings.Ports["80/tcp"], 1))
+	assert.Check(t, is.Equal("28082", inspect.NetworkSettings.Ports["80/tcp"][0].HostPort))
+	assert.Check(t, inspect.NetworkSettings.Networks["bridge"].IPAddress != "")
+}
@@ -48,6 +48,7 @@ type StoreOptions struct {
 	IDMappings                *idtools.IDMappings
 	PluginGetter              plugingetter.PluginGetter
 	ExperimentalEnabled       bool
+	Rootless                  bool
 	OS                        string
 }
 
@@ -59,6 +60,7 @@ func NewStoreFromOptions(options StoreOptions) (Store, error) {
 		UIDMaps:             options.IDMappings.UIDs(),
 		GIDMaps:             options.IDMappings.GIDs(),
 		ExperimentalEnabled: options.ExperimentalEnabled,
+		Rootless:            options.Rootless,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing graphdriver: %v", err)
@@ -95,6 +95,8 @@ const (
 	Gzip
 	// Xz is xz compression algorithm.
 	Xz
+	// Zstd is zstd compression algorithm.
+	Zstd
 )
 
 const (
@@ -138,6 +140,7 @@ func DetectCompression(source []byte) Compression {
 		Bzip2: {0x42, 0x5A, 0x68},
 		Gzip:  {0x1F, 0x8B, 0x08},
 		Xz:    {0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00},
+		Zstd:  {0x28, 0xB5, 0x2F, 0xFD},
 	} {
 		if len(source) < len(m) {
 			logrus.Debug("Len too short")
@@ -156,6 +159,21 @@ func xzDecompress(ctx context.Context, archive io.Reader) (io.ReadCloser, error)
 	return cmdStream(exec.CommandContext(ctx, args[0], args[1:]...), archive)
 }
 
+func zstdDecompress(ctx context.Context, archive io.Reader) (io.ReadCloser, error) {
+	args := []string{"zstd", "-d", "-c", "-q"}
+
+	return cmdStream(exec.CommandContext(ctx, args[0], args[1:]...), archive)
+}
+
+func zstdCompress(dest io.Writer, level int) (io.WriteCloser, error) {
+	args := []string{"zstd", "-c", "-q"}
+	if level != 0 {
+		args = append(args, "-"+strconv.Itoa(level))
+	}
+
+	return cmdWriteStream(exec.Command(args[0], args[1:]...), dest)
+}
+
 func gzDecompress(ctx context.Context, buf io.Reader) (io.ReadCloser, error) {
 	if unpigzPath == "" {
 		return gzip.NewReader(buf)
@@ -224,6 +242,16 @@ func DecompressStream(archive io.Reader) (io.ReadCloser, error) {
 		}
 		readBufWrapper := p.NewReadCloserWrapper(buf, xzReader)
 		return wrapReadCloser(readBufWrapper, cancel), nil
+	case Zstd:
+		ctx, cancel := context.WithCancel(context.Background())
+
+		zstdReader, err := zstdDecompress(ctx, buf)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		readBufWrapper := p.NewReadCloserWrapper(buf, zstdReader)
+		return wrapReadCloser(readBufWrapper, cancel), nil
 	default:
 		return nil, fmt.Errorf("Unsupported compression format %s", (&compression).Extension())
 	}
@@ -231,6 +259,17 @@ func DecompressStream(archive io.Reader) (io.ReadCloser, error) {
 
 // CompressStream compresses the dest with specified compression algorithm.
 func CompressStream(dest io.Writer, compression Compression) (io.WriteCloser, error) {
+	return CompressStreamLevel(dest, compression, 0)
+}
+
+// CompressStreamLevel compresses the dest with specified compression algorithm
+// and level. A level of 0 selects the default level of the algorithm.
+func CompressStreamLevel(dest io.Writer, compression Compression, level int) (io.WriteCloser, error) {
+	if compression == Zstd {
+		// zstd buffers its output itself
+		return zstdCompress(dest, level)
+	}
+
 	p := pools.BufioWriter32KPool
 	buf := p.Get(dest)
 	switch compression {
@@ -238,7 +277,13 @@ func CompressStream(dest io.Writer, compression Compression) (io.WriteCloser, er
 		writeBufWrapper := p.NewWriteCloserWrapper(buf, buf)
 		return writeBufWrapper, nil
 	case Gzip:
-		gzWriter := gzip.NewWriter(dest)
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		gzWriter, err := gzip.NewWriterLevel(dest, level)
+		if err != nil {
+			return nil, err
+		}
 		writeBufWrapper := p.NewWriteCloserWrapper(buf, gzWriter)
 		return writeBufWrapper, nil
 	case Bzip2, Xz:
@@ -348,6 +393,8 @@ func (compression *Compression) Extension() string {
 		return "tar.gz"
 	case Xz:
 		return "tar.xz"
+	case Zstd:
+		return "tar.zst"
 	}
 	return ""
 }
@@ -1236,6 +1283,31 @@ func cmdStream(cmd *exec.Cmd, input io.Reader) (io.ReadCloser, error) {
 	return pipeR, nil
 }
 
+// cmdWriteStream executes a command, and returns its stdin as a stream.
+// The command's stdout is written to output. Closing the returned stream
+// waits for the command to exit.
+func cmdWriteStream(cmd *exec.Cmd, output io.Writer) (io.WriteCloser, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = output
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return ioutils.NewWriteCloserWrapper(stdin, func() error {
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("%s: %s", err, errBuf.String())
+		}
+		return nil
+	}), nil
+}
+
 // NewTempArchive reads the content of src into a temporary file, and returns the contents
 // of that file as an archive. The archive can only be read once - as soon as reading completes,
 // the file will be deleted.
@@ -134,6 +134,46 @@ func TestDecompressStreamXz(t *testing.T) {
 	testDecompressStream(t, "xz", "xz -f")
 }
 
+func TestDecompressStreamZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd binary not found")
+	}
+	testDecompressStream(t, "zst", "zstd -f -q")
+}
+
+func TestCompressStreamZstd(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd binary not found")
+	}
+	var compressed bytes.Buffer
+	w, err := CompressStreamLevel(&compressed, Zstd, 19)
+	if err != nil {
+		t.Fatalf("Failed to create zstd stream: %v", err)
+	}
+	if _, err := w.Write([]byte("hello zstd")); err != nil {
+		t.Fatalf("Failed to write to zstd stream: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close zstd stream: %v", err)
+	}
+	if c := DetectCompression(compressed.Bytes()); c != Zstd {
+		t.Fatalf("Expected zstd compression, got %s", c.Extension())
+	}
+
+	r, err := DecompressStream(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to decompress zstd stream: %v", err)
+	}
+	defer r.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read the decompressed stream: %v", err)
+	}
+	if string(out) != "hello zstd" {
+		t.Fatalf("Unexpected decompressed content %q", out)
+	}
+}
+
 func TestCompressStreamXzUnsupported(t *testing.T) {
 	dest, err := os.Create(tmp + "dest")
 	if err != nil {
@@ -210,6 +250,14 @@ func TestExtensionXz(t *testing.T) {
 	}
 }
 
+func TestExtensionZstd(t *testing.T) {
+	compression := Zstd
+	output := compression.Extension()
+	if output != "tar.zst" {
+		t.Fatalf("The extension of a zstd archive should be 'tar.zst'")
+	}
+}
+
 func TestCmdStreamLargeStderr(t *testing.T) {
 	cmd := exec.Command("sh", "-c", "dd if=/dev/zero bs=1k count=1000 of=/dev/stderr; echo hello")
 	out, err := cmdStream(cmd, nil)
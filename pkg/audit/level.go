@@ -0,0 +1,27 @@
+// Package audit defines the levels of detail of the audit log of the API
+// requests, and identifies the processes making requests over unix sockets.
+package audit // import "github.com/docker/docker/pkg/audit"
+
+import "fmt"
+
+const (
+	// LevelMetadata records the metadata of the requests: who made them,
+	// the method, path and query, the response status and duration. The
+	// main fields of the bodies of the requests creating containers, execs
+	// and services are recorded as well.
+	LevelMetadata = "metadata"
+	// LevelRequest records the headers and JSON bodies of the requests in
+	// addition to their metadata.
+	LevelRequest = "request"
+)
+
+// ValidateLevel validates the level of an audit log. The empty level
+// selects LevelMetadata.
+func ValidateLevel(level string) error {
+	switch level {
+	case "", LevelMetadata, LevelRequest:
+		return nil
+	default:
+		return fmt.Errorf("invalid audit log level %q: must be %s or %s", level, LevelMetadata, LevelRequest)
+	}
+}
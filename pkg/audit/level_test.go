@@ -0,0 +1,15 @@
+package audit // import "github.com/docker/docker/pkg/audit"
+
+import (
+	"testing"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestValidateLevel(t *testing.T) {
+	assert.Check(t, ValidateLevel(""))
+	assert.Check(t, ValidateLevel(LevelMetadata))
+	assert.Check(t, ValidateLevel(LevelRequest))
+	assert.Check(t, is.ErrorContains(ValidateLevel("all"), "invalid audit log level"))
+}
@@ -0,0 +1,72 @@
+package audit // import "github.com/docker/docker/pkg/audit"
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Peer identifies the process at the other end of a unix socket connection.
+type Peer struct {
+	UID int `json:"uid"`
+	GID int `json:"gid"`
+	PID int `json:"pid"`
+}
+
+// PeerListener wraps the listener l so that the requests received on its
+// unix socket connections carry the credentials of the peer processes,
+// returned by PeerFromRequest. The other connections are left unchanged.
+func PeerListener(l net.Listener) net.Listener {
+	return &peerListener{Listener: l}
+}
+
+type peerListener struct {
+	net.Listener
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return c, err
+	}
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return c, nil
+	}
+	peer, err := peerCredentials(uc)
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to get the credentials of the peer of a unix socket connection")
+		return c, nil
+	}
+	if peer == nil {
+		return c, nil
+	}
+	return &peerConn{UnixConn: uc, addr: &peerAddr{Addr: uc.LocalAddr(), peer: peer}}, nil
+}
+
+// peerConn is a unix socket connection whose local address carries the
+// credentials of the peer. The HTTP server stores the local address of the
+// connection in the context of its requests.
+type peerConn struct {
+	*net.UnixConn
+	addr *peerAddr
+}
+
+func (c *peerConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+type peerAddr struct {
+	net.Addr
+	peer *Peer
+}
+
+// PeerFromRequest returns the credentials of the process which sent the
+// request r over a unix socket accepted by a PeerListener, or nil.
+func PeerFromRequest(r *http.Request) *Peer {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(*peerAddr); ok {
+		return addr.peer
+	}
+	return nil
+}
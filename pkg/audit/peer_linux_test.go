@@ -0,0 +1,59 @@
+package audit // import "github.com/docker/docker/pkg/audit"
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/fs"
+)
+
+func TestPeerListener(t *testing.T) {
+	dir := fs.NewDir(t, "audit-peer")
+	defer dir.Remove()
+
+	sock := filepath.Join(dir.Path(), "docker.sock")
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+
+	peers := make(chan *Peer, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peers <- PeerFromRequest(r)
+	}))
+	srv.Listener = PeerListener(l)
+	srv.Start()
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://docker/_ping")
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	peer := <-peers
+	assert.Assert(t, peer != nil)
+	assert.Check(t, is.DeepEqual(&Peer{UID: os.Getuid(), GID: os.Getgid(), PID: os.Getpid()}, peer))
+}
+
+func TestPeerFromRequestTCP(t *testing.T) {
+	peers := make(chan *Peer, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peers <- PeerFromRequest(r)
+	}))
+	srv.Listener = PeerListener(srv.Listener)
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Check(t, is.Nil(<-peers))
+}
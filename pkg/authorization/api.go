@@ -73,6 +73,10 @@ type Request struct {
 
 	// ResponseHeaders stores the response headers sent to the docker daemon
 	ResponseHeaders map[string]string `json:"ResponseHeaders,omitempty"`
+
+	// bodyOmitted is set if the request has a body which is not in
+	// RequestBody, because it is too large, chunked or not JSON.
+	bodyOmitted bool
 }
 
 // Response represents authZ plugin response
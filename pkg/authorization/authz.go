@@ -75,6 +75,7 @@ func (ctx *Ctx) AuthZRequest(w http.ResponseWriter, r *http.Request) error {
 		RequestURI:      ctx.requestURI,
 		RequestBody:     body,
 		RequestHeaders:  headers(r.Header),
+		bodyOmitted:     body == nil && r.ContentLength != 0,
 	}
 
 	if r.TLS != nil {
@@ -14,6 +14,7 @@ import (
 type Middleware struct {
 	mu      sync.Mutex
 	plugins []Plugin
+	policy  *Policy
 }
 
 // NewMiddleware creates a new Middleware
@@ -28,6 +29,9 @@ func NewMiddleware(names []string, pg plugingetter.PluginGetter) *Middleware {
 func (m *Middleware) getAuthzPlugins() []Plugin {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.policy != nil {
+		return append([]Plugin{m.policy}, m.plugins...)
+	}
 	return m.plugins
 }
 
@@ -38,6 +42,14 @@ func (m *Middleware) SetPlugins(names []string) {
 	m.mu.Unlock()
 }
 
+// SetPolicy sets the built-in policy, evaluated before the authorization
+// plugins. A nil policy disables it.
+func (m *Middleware) SetPolicy(p *Policy) {
+	m.mu.Lock()
+	m.policy = p
+	m.mu.Unlock()
+}
+
 // RemovePlugin removes a single plugin from this authz middleware chain
 func (m *Middleware) RemovePlugin(name string) {
 	m.mu.Lock()
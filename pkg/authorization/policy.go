@@ -0,0 +1,458 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyName is the name the built-in authorization policy is reported
+// under when it denies a request.
+const PolicyName = "authorization-policy"
+
+const (
+	// PolicyAllow is the action of the rules allowing the requests they match.
+	PolicyAllow = "allow"
+	// PolicyDeny is the action of the rules denying the requests they match.
+	PolicyDeny = "deny"
+)
+
+// versionPrefix matches the API version prefix of the request paths.
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// PolicyConfig is the configuration of the built-in authorization policy,
+// as found under the "authorization-policy" key of the daemon configuration.
+type PolicyConfig struct {
+	// DefaultAction is the action applied to the requests no rule matches,
+	// either "allow" (the default) or "deny".
+	DefaultAction string `json:"default-action,omitempty"`
+
+	// Rules are evaluated in order; the first rule matching a request
+	// decides whether it is allowed.
+	Rules []PolicyRule `json:"rules,omitempty"`
+}
+
+// PolicyRule is a rule of the built-in authorization policy. A rule matches
+// a request if each of its conditions that is set matches the request.
+type PolicyRule struct {
+	// Action is either "allow" or "deny".
+	Action string `json:"action"`
+
+	// Reason is returned to the client when the rule denies a request.
+	Reason string `json:"reason,omitempty"`
+
+	// Users are the common names of the TLS client certificates of the
+	// requests. The empty string matches unauthenticated requests.
+	Users []string `json:"users,omitempty"`
+
+	// Methods are the HTTP methods of the requests.
+	Methods []string `json:"methods,omitempty"`
+
+	// Routes are patterns of the request paths without the API version
+	// prefix, such as "/containers/*/exec", using the syntax of path.Match.
+	Routes []string `json:"routes,omitempty"`
+
+	// The following conditions match the fields of the requests creating
+	// containers, of the requests importing checkpoints, of the requests
+	// starting containers with a host configuration (API < 1.24), of the
+	// requests creating exec instances for Privileged, and of the requests
+	// creating volumes for BindSources. A rule setting any of them only
+	// matches those requests.
+
+	// Privileged matches whether the container or exec is privileged.
+	Privileged *bool `json:"privileged,omitempty"`
+
+	// BindSources are host paths; they match the requests bind mounting
+	// one of them, a path below one of them, or a directory containing one
+	// of them.
+	BindSources []string `json:"bind-sources,omitempty"`
+
+	// CapAdd matches the requests adding any of the capabilities.
+	CapAdd []string `json:"cap-add,omitempty"`
+
+	// NetworkModes matches the network mode of the containers.
+	NetworkModes []string `json:"network-modes,omitempty"`
+}
+
+// hasFieldConditions returns whether the rule matches decoded fields of
+// the request body.
+func (r *PolicyRule) hasFieldConditions() bool {
+	return r.Privileged != nil || len(r.BindSources) > 0 || len(r.CapAdd) > 0 || len(r.NetworkModes) > 0
+}
+
+// Policy is the built-in authorization policy. It implements Plugin so that
+// the Middleware evaluates it before the authorization plugins.
+type Policy struct {
+	config PolicyConfig
+	// inspectBody is set if a rule matches decoded fields of the requests.
+	inspectBody bool
+}
+
+// NewPolicy validates the configuration of a policy and returns the policy.
+func NewPolicy(config PolicyConfig) (*Policy, error) {
+	switch config.DefaultAction {
+	case "", PolicyAllow, PolicyDeny:
+	default:
+		return nil, fmt.Errorf("invalid authorization policy default action %q: must be %s or %s", config.DefaultAction, PolicyAllow, PolicyDeny)
+	}
+	p := &Policy{config: config}
+	for i, r := range config.Rules {
+		if r.Action != PolicyAllow && r.Action != PolicyDeny {
+			return nil, fmt.Errorf("invalid action %q for authorization policy rule %d: must be %s or %s", r.Action, i, PolicyAllow, PolicyDeny)
+		}
+		for _, route := range r.Routes {
+			if !strings.HasPrefix(route, "/") {
+				return nil, fmt.Errorf("invalid route %q for authorization policy rule %d: must be an absolute path", route, i)
+			}
+			if _, err := path.Match(route, "/"); err != nil {
+				return nil, fmt.Errorf("invalid route %q for authorization policy rule %d: %v", route, i, err)
+			}
+		}
+		for _, src := range r.BindSources {
+			if !path.IsAbs(src) {
+				return nil, fmt.Errorf("invalid bind source %q for authorization policy rule %d: must be an absolute path", src, i)
+			}
+		}
+		if r.hasFieldConditions() {
+			p.inspectBody = true
+		}
+	}
+	return p, nil
+}
+
+// Name returns the name of the policy.
+func (p *Policy) Name() string {
+	return PolicyName
+}
+
+// AuthZRequest evaluates the rules of the policy against the request.
+func (p *Policy) AuthZRequest(req *Request) (*Response, error) {
+	route := requestRoute(req.RequestURI)
+	var fields *requestFields
+	if decode := fieldsDecoder(req.RequestMethod, route); p.inspectBody && decode != nil {
+		var err error
+		if fields, err = decode(req); err != nil {
+			// Never let a request through because it could not be
+			// inspected, as it could be crafted to bypass the rules.
+			return &Response{Allow: false, Msg: fmt.Sprintf("the request could not be inspected: %v", err)}, nil
+		}
+	}
+
+	for i, r := range p.config.Rules {
+		if !r.matches(req, route, fields) {
+			continue
+		}
+		logrus.Debugf("Authorization policy rule %d matched %s %s: %s", i, req.RequestMethod, route, r.Action)
+		if r.Action == PolicyDeny {
+			msg := r.Reason
+			if msg == "" {
+				msg = fmt.Sprintf("denied by rule %d", i)
+			}
+			return &Response{Allow: false, Msg: msg}, nil
+		}
+		return &Response{Allow: true, Msg: r.Reason}, nil
+	}
+
+	if p.config.DefaultAction == PolicyDeny {
+		return &Response{Allow: false, Msg: "no rule allows the request"}, nil
+	}
+	return &Response{Allow: true}, nil
+}
+
+// AuthZResponse allows all the responses; the policy only applies to the
+// requests.
+func (p *Policy) AuthZResponse(req *Request) (*Response, error) {
+	return &Response{Allow: true}, nil
+}
+
+func (r *PolicyRule) matches(req *Request, route string, fields *requestFields) bool {
+	if len(r.Users) > 0 && !containsString(r.Users, req.User) {
+		return false
+	}
+	if len(r.Methods) > 0 && !containsFold(r.Methods, req.RequestMethod) {
+		return false
+	}
+	if len(r.Routes) > 0 && !matchesRoute(r.Routes, route) {
+		return false
+	}
+	if !r.hasFieldConditions() {
+		return true
+	}
+	if fields == nil {
+		return false
+	}
+	if r.Privileged != nil && *r.Privileged != fields.privileged {
+		return false
+	}
+	if len(r.BindSources) > 0 && !matchesBindSource(r.BindSources, fields.bindSources) {
+		return false
+	}
+	if len(r.CapAdd) > 0 && !matchesCapAdd(r.CapAdd, fields.capAdd) {
+		return false
+	}
+	if len(r.NetworkModes) > 0 && !containsString(r.NetworkModes, fields.networkMode) {
+		return false
+	}
+	return true
+}
+
+// requestRoute returns the path of the request URI without the API version
+// prefix.
+func requestRoute(uri string) string {
+	p := uri
+	if u, err := url.ParseRequestURI(uri); err == nil {
+		p = u.Path
+	}
+	p = path.Clean("/" + p)
+	if route := versionPrefix.ReplaceAllString(p, ""); route != "" {
+		return route
+	}
+	return "/"
+}
+
+// requestVersion returns the API version of the request path, or an empty
+// string if the path has no version prefix.
+func requestVersion(uri string) string {
+	p := uri
+	if u, err := url.ParseRequestURI(uri); err == nil {
+		p = u.Path
+	}
+	return strings.TrimPrefix(versionPrefix.FindString(path.Clean("/"+p)), "/v")
+}
+
+// fieldsDecoder returns the function decoding the fields matched by the
+// rules from the request, or nil if the request has no such fields.
+func fieldsDecoder(method, route string) func(*Request) (*requestFields, error) {
+	if !strings.EqualFold(method, "POST") {
+		return nil
+	}
+	switch route {
+	case "/containers/create":
+		return decodeContainerFields
+	case "/containers/checkpoints/import":
+		return decodeCheckpointImportFields
+	case "/volumes/create":
+		return decodeVolumeFields
+	}
+	if ok, _ := path.Match("/containers/*/exec", route); ok {
+		return decodeContainerFields
+	}
+	if ok, _ := path.Match("/containers/*/start", route); ok {
+		return decodeStartFields
+	}
+	return nil
+}
+
+// requestFields are the fields of a request matched by the rules.
+type requestFields struct {
+	privileged  bool
+	bindSources []string
+	capAdd      []string
+	networkMode string
+}
+
+// policyHostConfig holds the fields of the host configuration matched by
+// the rules.
+type policyHostConfig struct {
+	Privileged  bool
+	Binds       []string
+	CapAdd      []string
+	NetworkMode string
+	Mounts      []struct {
+		Type          string
+		Source        string
+		VolumeOptions struct {
+			DriverConfig struct {
+				Name    string
+				Options map[string]string
+			}
+		}
+	}
+}
+
+// decodeContainerFields decodes the fields matched by the rules from the
+// body of a request creating a container or an exec instance.
+func decodeContainerFields(req *Request) (*requestFields, error) {
+	if len(req.RequestBody) == 0 {
+		return nil, fmt.Errorf("missing or too large JSON body")
+	}
+	var v struct {
+		// Privileged is set by exec instances
+		Privileged bool
+		HostConfig policyHostConfig
+	}
+	if err := json.Unmarshal(req.RequestBody, &v); err != nil {
+		return nil, err
+	}
+	f := hostConfigFields(&v.HostConfig)
+	f.privileged = f.privileged || v.Privileged
+	return f, nil
+}
+
+// decodeStartFields decodes the fields matched by the rules from the body of
+// a request starting a container. Before API 1.24, the body may hold a host
+// configuration which is applied to the container when it starts; later
+// versions refuse start requests with a body.
+func decodeStartFields(req *Request) (*requestFields, error) {
+	if v := requestVersion(req.RequestURI); v == "" || versions.GreaterThanOrEqualTo(v, "1.24") {
+		return nil, nil
+	}
+	if len(req.RequestBody) == 0 {
+		if req.bodyOmitted {
+			return nil, fmt.Errorf("too large or non-JSON body")
+		}
+		return nil, nil
+	}
+	var hc policyHostConfig
+	if err := json.Unmarshal(req.RequestBody, &hc); err != nil {
+		return nil, err
+	}
+	return hostConfigFields(&hc), nil
+}
+
+// decodeCheckpointImportFields decodes the fields matched by the rules from
+// the host configuration passed in the query of a request importing a
+// checkpoint. The host configuration of the checkpoint bundle can't be
+// inspected, so the request is refused if the client doesn't pass one.
+func decodeCheckpointImportFields(req *Request) (*requestFields, error) {
+	u, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil {
+		return nil, err
+	}
+	hostConfig := u.Query().Get("hostConfig")
+	if hostConfig == "" {
+		return nil, fmt.Errorf("the host configuration must be passed explicitly")
+	}
+	var hc policyHostConfig
+	if err := json.Unmarshal([]byte(hostConfig), &hc); err != nil {
+		return nil, err
+	}
+	return hostConfigFields(&hc), nil
+}
+
+// decodeVolumeFields decodes the fields matched by the rules from the body
+// of a request creating a volume: the device mounted by the local driver
+// is a bind source.
+func decodeVolumeFields(req *Request) (*requestFields, error) {
+	if len(req.RequestBody) == 0 {
+		return nil, fmt.Errorf("missing or too large JSON body")
+	}
+	var v struct {
+		Driver     string
+		DriverOpts map[string]string
+	}
+	if err := json.Unmarshal(req.RequestBody, &v); err != nil {
+		return nil, err
+	}
+	f := &requestFields{}
+	if src := localVolumeDevice(v.Driver, v.DriverOpts); src != "" {
+		f.bindSources = append(f.bindSources, src)
+	}
+	return f, nil
+}
+
+// hostConfigFields returns the fields of the host configuration matched by
+// the rules.
+func hostConfigFields(hc *policyHostConfig) *requestFields {
+	f := &requestFields{
+		privileged:  hc.Privileged,
+		capAdd:      hc.CapAdd,
+		networkMode: hc.NetworkMode,
+	}
+	if f.networkMode == "" {
+		f.networkMode = "default"
+	}
+	for _, b := range hc.Binds {
+		// Named volumes are not bind mounts
+		if src := strings.SplitN(b, ":", 2)[0]; path.IsAbs(src) {
+			f.bindSources = append(f.bindSources, src)
+		}
+	}
+	for _, m := range hc.Mounts {
+		switch m.Type {
+		case "bind":
+			f.bindSources = append(f.bindSources, m.Source)
+		case "volume":
+			if src := localVolumeDevice(m.VolumeOptions.DriverConfig.Name, m.VolumeOptions.DriverConfig.Options); src != "" {
+				f.bindSources = append(f.bindSources, src)
+			}
+		}
+	}
+	return f
+}
+
+// localVolumeDevice returns the host path mounted by a volume of the local
+// driver created with the options, if any. Remote devices, such as NFS
+// exports, are not host paths.
+func localVolumeDevice(driver string, opts map[string]string) string {
+	if driver != "" && driver != "local" {
+		return ""
+	}
+	if device := opts["device"]; path.IsAbs(device) {
+		return device
+	}
+	return ""
+}
+
+func matchesRoute(patterns []string, route string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, route); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesBindSource returns whether one of the sources is one of the paths,
+// below one of them or above one of them.
+func matchesBindSource(paths, sources []string) bool {
+	for _, src := range sources {
+		src = path.Clean("/" + src)
+		for _, p := range paths {
+			p = path.Clean(p)
+			if src == p || p == "/" || src == "/" || strings.HasPrefix(src, p+"/") || strings.HasPrefix(p, src+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesCapAdd returns whether one of the added capabilities is one of
+// caps. Adding "ALL" matches any capability.
+func matchesCapAdd(caps, added []string) bool {
+	for _, a := range added {
+		a = strings.TrimPrefix(strings.ToUpper(a), "CAP_")
+		for _, c := range caps {
+			c = strings.TrimPrefix(strings.ToUpper(c), "CAP_")
+			if a == c || a == "ALL" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"testing"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestNewPolicyInvalid(t *testing.T) {
+	cases := []struct {
+		config PolicyConfig
+		err    string
+	}{
+		{PolicyConfig{DefaultAction: "maybe"}, "invalid authorization policy default action"},
+		{PolicyConfig{Rules: []PolicyRule{{Action: "block"}}}, "invalid action"},
+		{PolicyConfig{Rules: []PolicyRule{{Action: PolicyDeny, Routes: []string{"containers/create"}}}}, "must be an absolute path"},
+		{PolicyConfig{Rules: []PolicyRule{{Action: PolicyDeny, Routes: []string{"/containers/["}}}}, "syntax error"},
+		{PolicyConfig{Rules: []PolicyRule{{Action: PolicyDeny, BindSources: []string{"etc"}}}}, "invalid bind source"},
+	}
+	for _, c := range cases {
+		_, err := NewPolicy(c.config)
+		assert.Check(t, is.ErrorContains(err, c.err))
+	}
+}
+
+func TestPolicyAuthZRequest(t *testing.T) {
+	privileged := true
+	p, err := NewPolicy(PolicyConfig{
+		Rules: []PolicyRule{
+			{Action: PolicyAllow, Users: []string{"admin"}},
+			{Action: PolicyDeny, Reason: "privileged containers are not allowed", Privileged: &privileged},
+			{Action: PolicyDeny, Reason: "the docker socket cannot be mounted", BindSources: []string{"/var/run/docker.sock", "/etc"}},
+			{Action: PolicyDeny, Reason: "SYS_ADMIN cannot be added", CapAdd: []string{"SYS_ADMIN"}},
+			{Action: PolicyDeny, Reason: "host network is not allowed", NetworkModes: []string{"host"}},
+			{Action: PolicyDeny, Reason: "read-only", Methods: []string{"DELETE"}, Routes: []string{"/containers/*"}},
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, PolicyName, p.Name())
+
+	create := func(user, body string) *Request {
+		return &Request{
+			User:          user,
+			RequestMethod: "POST",
+			RequestURI:    "/v1.37/containers/create?name=foo",
+			RequestBody:   []byte(body),
+		}
+	}
+	cases := []struct {
+		req    *Request
+		allow  bool
+		reason string
+	}{
+		{req: create("", `{"Image":"busybox"}`), allow: true},
+		{req: create("", `{"HostConfig":{"Privileged":true}}`), reason: "privileged containers are not allowed"},
+		{req: create("admin", `{"HostConfig":{"Privileged":true}}`), allow: true},
+		{req: create("", `{"HostConfig":{"Binds":["/var/run/docker.sock:/var/run/docker.sock"]}}`), reason: "the docker socket cannot be mounted"},
+		{req: create("", `{"HostConfig":{"Mounts":[{"Type":"bind","Source":"/etc/ssl/"}]}}`), reason: "the docker socket cannot be mounted"},
+		{req: create("", `{"HostConfig":{"Binds":["etc:/etc", "/etcetera:/data"]}}`), allow: true},
+		{req: create("", `{"HostConfig":{"Binds":["/var/run:/host-run"]}}`), reason: "the docker socket cannot be mounted"},
+		{req: create("", `{"HostConfig":{"CapAdd":["ALL"]}}`), reason: "SYS_ADMIN cannot be added"},
+		{req: create("", `{"HostConfig":{"CapAdd":["NET_ADMIN"]}}`), allow: true},
+		{req: create("", `{"HostConfig":{"NetworkMode":"host"}}`), reason: "host network is not allowed"},
+		{req: create("", ``), reason: "the request could not be inspected"},
+		{req: create("", `{`), reason: "the request could not be inspected"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/containers/abc/exec", RequestBody: []byte(`{"Privileged":true}`)}, reason: "privileged containers are not allowed"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/containers/create", RequestBody: []byte(`{"HostConfig":{"Mounts":[{"Type":"volume","VolumeOptions":{"DriverConfig":{"Options":{"type":"none","o":"bind","device":"/"}}}}]}}`)}, reason: "the docker socket cannot be mounted"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/volumes/create", RequestBody: []byte(`{"Name":"root","DriverOpts":{"type":"none","o":"bind","device":"/etc"}}`)}, reason: "the docker socket cannot be mounted"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/volumes/create", RequestBody: []byte(`{"Driver":"local","DriverOpts":{"type":"nfs","o":"addr=10.0.0.1","device":":/etc"}}`)}, allow: true},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/volumes/create", RequestBody: []byte(`{"Driver":"other","DriverOpts":{"device":"/etc"}}`)}, allow: true},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/containers/checkpoints/import?name=foo"}, reason: "the host configuration must be passed explicitly"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/containers/checkpoints/import?hostConfig=%7B%22Privileged%22%3Atrue%7D"}, reason: "privileged containers are not allowed"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.37/containers/checkpoints/import?hostConfig=%7B%7D"}, allow: true},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.23/containers/abc/start", RequestBody: []byte(`{"Privileged":true,"Binds":["/:/host"]}`)}, reason: "privileged containers are not allowed"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.23/containers/abc/start", RequestBody: []byte(`{"Binds":["/:/host"]}`)}, reason: "the docker socket cannot be mounted"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.23/containers/abc/start", RequestBody: []byte(`{"NetworkMode":"host"}`)}, reason: "host network is not allowed"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.23/containers/abc/start", bodyOmitted: true}, reason: "the request could not be inspected"},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.23/containers/abc/start"}, allow: true},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/v1.24/containers/abc/start", RequestBody: []byte(`{"Privileged":true}`)}, allow: true},
+		{req: &Request{RequestMethod: "POST", RequestURI: "/containers/abc/start?checkpoint=c1"}, allow: true},
+		{req: &Request{RequestMethod: "delete", RequestURI: "/v1.37/containers/abc?force=1"}, reason: "read-only"},
+		{req: &Request{RequestMethod: "DELETE", RequestURI: "/v1.37/images/abc"}, allow: true},
+		{req: &Request{RequestMethod: "GET", RequestURI: "/containers/json"}, allow: true},
+	}
+	for _, c := range cases {
+		res, err := p.AuthZRequest(c.req)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(c.allow, res.Allow), "%s %s %s", c.req.RequestMethod, c.req.RequestURI, c.req.RequestBody)
+		if c.reason != "" {
+			assert.Check(t, is.Contains(res.Msg, c.reason))
+		}
+	}
+}
+
+func TestPolicyDefaultDeny(t *testing.T) {
+	p, err := NewPolicy(PolicyConfig{
+		DefaultAction: PolicyDeny,
+		Rules: []PolicyRule{
+			{Action: PolicyAllow, Methods: []string{"GET", "HEAD"}},
+		},
+	})
+	assert.NilError(t, err)
+
+	res, err := p.AuthZRequest(&Request{RequestMethod: "GET", RequestURI: "/v1.37/containers/json"})
+	assert.NilError(t, err)
+	assert.Check(t, res.Allow)
+
+	res, err = p.AuthZRequest(&Request{RequestMethod: "POST", RequestURI: "/v1.37/containers/abc/stop"})
+	assert.NilError(t, err)
+	assert.Check(t, !res.Allow)
+	assert.Check(t, is.Equal("no rule allows the request", res.Msg))
+}
+
+func TestMiddlewarePolicy(t *testing.T) {
+	m := NewMiddleware([]string{"testPlugin"}, nil)
+	p, err := NewPolicy(PolicyConfig{})
+	assert.NilError(t, err)
+
+	m.SetPolicy(p)
+	authPlugins := m.getAuthzPlugins()
+	assert.Assert(t, is.Len(authPlugins, 2))
+	assert.Check(t, is.Equal(PolicyName, authPlugins[0].Name()))
+	assert.Check(t, is.Equal("testPlugin", authPlugins[1].Name()))
+
+	m.SetPolicy(nil)
+	assert.Check(t, is.Len(m.getAuthzPlugins(), 1))
+}
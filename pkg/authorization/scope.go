@@ -0,0 +1,163 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// readOnlyRoutes are the routes allowed by the read-only scopes, for the GET
+// and HEAD requests. They inspect or list objects, or stream events, logs or
+// stats. The other GET routes are left out as they expose credentials, such
+// as the swarm join tokens and unlock key, copy the content of containers,
+// images or volumes, or write to containers, such as the attach websocket.
+var readOnlyRoutes = []string{
+	"/_ping",
+	"/version",
+	"/info",
+	"/events",
+	"/system/df",
+	"/containers/json",
+	"/containers/stats",
+	"/containers/*/json",
+	"/containers/*/top",
+	"/containers/*/logs",
+	"/containers/*/changes",
+	"/containers/*/stats",
+	"/containers/*/stats/history",
+	"/exec/*/json",
+	"/images/json",
+	"/images/**/json",
+	"/images/**/history",
+	"/networks",
+	"/networks/*",
+	"/volumes",
+	"/volumes/*",
+	"/plugins",
+	"/plugins/**/json",
+	"/services",
+	"/services/*",
+	"/services/*/logs",
+	"/tasks",
+	"/tasks/*",
+	"/tasks/*/logs",
+	"/nodes",
+	"/nodes/*",
+	"/secrets",
+	"/secrets/*",
+}
+
+// Scope restricts the routes served on a listener of the API.
+type Scope struct {
+	// ReadOnly restricts the listener to the GET and HEAD requests which
+	// inspect or list objects, or stream events, logs or stats.
+	ReadOnly bool `json:"read-only,omitempty"`
+
+	// Routes is an allowlist of the routes served on the listener, as
+	// patterns of the request paths without the API version prefix,
+	// optionally prefixed with a method, such as "GET /containers/json" or
+	// "/containers/*/json". The patterns use the syntax of path.Match, and
+	// a "**" element matches one or more elements of the path, as in
+	// "/images/**/json".
+	Routes []string `json:"routes,omitempty"`
+}
+
+// Validate returns an error if a route of the scope is invalid.
+func (s *Scope) Validate() error {
+	for _, r := range s.Routes {
+		_, pattern := splitScopeRoute(r)
+		if !strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("invalid route %q: must be an absolute path, optionally prefixed with a method", r)
+		}
+		if _, err := path.Match(pattern, "/"); err != nil {
+			return fmt.Errorf("invalid route %q: %v", r, err)
+		}
+	}
+	return nil
+}
+
+// Allows returns whether the scope allows the requests with the method to
+// the path, which may include the API version prefix.
+func (s *Scope) Allows(method, p string) bool {
+	route := requestRoute(p)
+	if s.ReadOnly {
+		if method != "GET" && method != "HEAD" {
+			return false
+		}
+		if !matchesScopeRoute(readOnlyRoutes, route) {
+			return false
+		}
+	}
+	if len(s.Routes) == 0 {
+		return true
+	}
+	for _, r := range s.Routes {
+		m, pattern := splitScopeRoute(r)
+		if m != "" && !strings.EqualFold(m, method) {
+			continue
+		}
+		if matchScopeRoute(pattern, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a description of the scope.
+func (s *Scope) String() string {
+	var parts []string
+	if s.ReadOnly {
+		parts = append(parts, "read-only")
+	}
+	parts = append(parts, s.Routes...)
+	if len(parts) == 0 {
+		return "all routes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// splitScopeRoute splits a route of a scope into its optional method and
+// its path pattern.
+func splitScopeRoute(r string) (method, pattern string) {
+	r = strings.TrimSpace(r)
+	if i := strings.IndexAny(r, " \t"); i > 0 && !strings.HasPrefix(r, "/") {
+		return r[:i], strings.TrimSpace(r[i:])
+	}
+	return "", r
+}
+
+func matchesScopeRoute(patterns []string, route string) bool {
+	for _, p := range patterns {
+		if matchScopeRoute(p, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchScopeRoute matches the route against the pattern, element by element.
+// A "**" element of the pattern matches one or more elements of the route.
+func matchScopeRoute(pattern, route string) bool {
+	return matchElements(strings.Split(pattern, "/"), strings.Split(route, "/"))
+}
+
+func matchElements(pattern, route []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 1; i <= len(route); i++ {
+				if matchElements(pattern[1:], route[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(route) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], route[0]); !ok {
+			return false
+		}
+		pattern, route = pattern[1:], route[1:]
+	}
+	return len(route) == 0
+}
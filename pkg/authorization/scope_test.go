@@ -0,0 +1,69 @@
+package authorization // import "github.com/docker/docker/pkg/authorization"
+
+import (
+	"testing"
+
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestScopeValidate(t *testing.T) {
+	assert.Check(t, (&Scope{Routes: []string{"/events", "GET /containers/*/json", "/images/**/json"}}).Validate())
+	assert.Check(t, is.ErrorContains((&Scope{Routes: []string{"containers/json"}}).Validate(), "must be an absolute path"))
+	assert.Check(t, is.ErrorContains((&Scope{Routes: []string{"GET /containers/["}}).Validate(), "syntax error"))
+}
+
+func TestScopeAllows(t *testing.T) {
+	readOnly := &Scope{ReadOnly: true}
+	allowlist := &Scope{Routes: []string{"GET /containers/json", "/events", "GET /images/**/json"}}
+	both := &Scope{ReadOnly: true, Routes: []string{"/containers/*/json"}}
+
+	cases := []struct {
+		scope  *Scope
+		method string
+		path   string
+		allow  bool
+	}{
+		{readOnly, "GET", "/v1.37/containers/json", true},
+		{readOnly, "GET", "/containers/abc/json", true},
+		{readOnly, "GET", "/containers/abc/logs", true},
+		{readOnly, "GET", "/v1.37/containers/abc/stats", true},
+		{readOnly, "GET", "/events", true},
+		{readOnly, "GET", "/info", true},
+		{readOnly, "GET", "/_ping", true},
+		{readOnly, "HEAD", "/_ping", true},
+		{readOnly, "GET", "/images/library/busybox:latest/json", true},
+		{readOnly, "GET", "/networks/abc", true},
+		{readOnly, "POST", "/v1.37/containers/create", false},
+		{readOnly, "DELETE", "/images/abc", false},
+		{readOnly, "POST", "/containers/abc/json", false},
+		{readOnly, "GET", "/v1.37/containers/abc/attach/ws", false},
+		{readOnly, "GET", "/v1.37/swarm", false},
+		{readOnly, "GET", "/swarm/unlockkey", false},
+		{readOnly, "GET", "/containers/abc/export", false},
+		{readOnly, "GET", "/containers/abc/archive", false},
+		{readOnly, "HEAD", "/containers/abc/archive", false},
+		{readOnly, "GET", "/images/get", false},
+		{readOnly, "GET", "/images/busybox/get", false},
+		{readOnly, "GET", "/volumes/data/archive", false},
+		{readOnly, "GET", "/containers/abc/checkpoints/cp/export", false},
+		{readOnly, "GET", "/configs/abc", false},
+		{readOnly, "GET", "/debug/pprof/profile", false},
+		{readOnly, "GET", "/distribution/busybox/json", false},
+		{allowlist, "GET", "/v1.37/containers/json", true},
+		{allowlist, "POST", "/v1.37/containers/json", false},
+		{allowlist, "GET", "/events", true},
+		{allowlist, "POST", "/events", true},
+		{allowlist, "GET", "/images/library/busybox/json", true},
+		{allowlist, "GET", "/images/json", false},
+		{allowlist, "GET", "/v1.37/containers/abc/json", false},
+		{allowlist, "GET", "/v1.37/../info", false},
+		{both, "GET", "/containers/abc/json", true},
+		{both, "POST", "/containers/abc/json", false},
+		{both, "GET", "/containers/abc/logs", false},
+		{&Scope{}, "POST", "/containers/create", true},
+	}
+	for _, c := range cases {
+		assert.Check(t, is.Equal(c.allow, c.scope.Allows(c.method, c.path)), "%s %s %s", c.scope, c.method, c.path)
+	}
+}
@@ -30,6 +30,42 @@ func Untar(tarArchive io.Reader, dest string, options *archive.TarOptions) error
 	return untarHandler(tarArchive, dest, options, true)
 }
 
+// Tar creates an archive of the directory at srcPath while chrooted to it,
+// so that the paths of the archived files can't be resolved outside of the
+// directory. The archive is compressed by the calling process, as the
+// compression tools are not available in the chroot.
+func Tar(srcPath string, options *archive.TarOptions) (io.ReadCloser, error) {
+	if options == nil {
+		options = &archive.TarOptions{}
+	}
+	packOptions := *options
+	packOptions.Compression = archive.Uncompressed
+	rdr, err := invokePack(srcPath, &packOptions)
+	if err != nil {
+		return nil, err
+	}
+	if options.Compression == archive.Uncompressed {
+		return rdr, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer rdr.Close()
+		compressed, err := archive.CompressStream(pw, options.Compression)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(compressed, rdr); err != nil {
+			compressed.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(compressed.Close())
+	}()
+	return pr, nil
+}
+
 // UntarUncompressed reads a stream of bytes from `archive`, parses it as a tar archive,
 // and unpacks it into the directory at `dest`.
 // The archive must be an uncompressed stream.
@@ -71,6 +71,57 @@ func TestChrootTarUntar(t *testing.T) {
 	}
 }
 
+func TestChrootTar(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+	tmpdir, err := ioutil.TempDir("", "docker-TestChrootTar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	src := filepath.Join(tmpdir, "src")
+	if err := system.MkdirAll(filepath.Join(src, "dir"), 0700, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "dir", "toto"), []byte("hello toto"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the link is archived as is, and not resolved on the host
+	if err := os.Symlink("/etc", filepath.Join(src, "etc")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, compression := range []archive.Compression{archive.Uncompressed, archive.Gzip} {
+		stream, err := Tar(src, &archive.TarOptions{Compression: compression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(tmpdir, "dest-"+compression.Extension())
+		if err := system.MkdirAll(dest, 0700, ""); err != nil {
+			t.Fatal(err)
+		}
+		err = Untar(stream, dest, nil)
+		stream.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := compareDirectories(src, dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ioutil.ReadAll(mustTar(t, filepath.Join(tmpdir, "missing"))); err == nil {
+		t.Fatal("expected error archiving missing directory")
+	}
+}
+
+func mustTar(t *testing.T, src string) io.ReadCloser {
+	stream, err := Tar(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
 // gh#10426: Verify the fix for having a huge excludes list (like on `docker load` with large # of
 // local images)
 func TestChrootUntarWithHugeExcludesList(t *testing.T) {
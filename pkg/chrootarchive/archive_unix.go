@@ -86,3 +86,72 @@ func invokeUnpack(decompressedArchive io.Reader, dest string, options *archive.T
 	}
 	return nil
 }
+
+// pack is the entry-point for docker-tar on re-exec. It writes an uncompressed
+// archive of the directory it is chrooted to on its stdout.
+func pack() {
+	runtime.LockOSThread()
+	flag.Parse()
+
+	var options *archive.TarOptions
+
+	//read the options from the pipe "ExtraFiles"
+	if err := json.NewDecoder(os.NewFile(3, "options")).Decode(&options); err != nil {
+		fatal(err)
+	}
+
+	// The archive is written by other goroutines than the one locked to
+	// this thread, which would not see the mount namespace of a pivot_root.
+	// The files are only read: chroot is enough.
+	if err := realChroot(flag.Arg(0)); err != nil {
+		fatal(err)
+	}
+
+	rdr, err := archive.TarWithOptions("/", options)
+	if err != nil {
+		fatal(err)
+	}
+	defer rdr.Close()
+
+	if _, err := io.Copy(os.Stdout, rdr); err != nil {
+		fatal(err)
+	}
+	os.Exit(0)
+}
+
+func invokePack(srcPath string, options *archive.TarOptions) (io.ReadCloser, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("Tar pipe failure: %v", err)
+	}
+	defer r.Close()
+
+	cmd := reexec.Command("docker-tar", srcPath)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+	errBuf := bytes.NewBuffer(nil)
+	cmd.Stderr = errBuf
+	tarR, tarW := io.Pipe()
+	cmd.Stdout = tarW
+
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("Tar error on re-exec cmd: %v", err)
+	}
+	//write the options to the pipe for the tar exec to read
+	if err := json.NewEncoder(w).Encode(options); err != nil {
+		w.Close()
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("Tar json encode to pipe failed: %v", err)
+	}
+	w.Close()
+
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			tarW.CloseWithError(fmt.Errorf("Error creating tar file(%v): %s", err, errBuf))
+			return
+		}
+		tarW.Close()
+	}()
+	return tarR, nil
+}
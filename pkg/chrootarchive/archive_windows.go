@@ -20,3 +20,9 @@ func invokeUnpack(decompressedArchive io.ReadCloser,
 	// do the unpack. We call inline instead within the daemon process.
 	return archive.Unpack(decompressedArchive, longpath.AddPrefix(dest), options)
 }
+
+func invokePack(srcPath string, options *archive.TarOptions) (io.ReadCloser, error) {
+	// Windows does not support chroot, the archive is created inline within
+	// the daemon process.
+	return archive.TarWithOptions(longpath.AddPrefix(srcPath), options)
+}
@@ -0,0 +1,44 @@
+package homedir // import "github.com/docker/docker/pkg/homedir"
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetDataHome(t *testing.T) {
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+
+	os.Setenv("XDG_DATA_HOME", "")
+	os.Setenv("HOME", "/home/user")
+	dataHome, err := GetDataHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataHome != "/home/user/.local/share" {
+		t.Fatalf("expected /home/user/.local/share, got %s", dataHome)
+	}
+
+	os.Setenv("XDG_DATA_HOME", "/data")
+	if dataHome, _ = GetDataHome(); dataHome != "/data" {
+		t.Fatalf("expected /data, got %s", dataHome)
+	}
+}
+
+func TestGetRuntimeDir(t *testing.T) {
+	defer os.Setenv("XDG_RUNTIME_DIR", os.Getenv("XDG_RUNTIME_DIR"))
+
+	os.Setenv("XDG_RUNTIME_DIR", "")
+	if _, err := GetRuntimeDir(); err == nil {
+		t.Fatal("expected an error without XDG_RUNTIME_DIR")
+	}
+
+	os.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	runtimeDir, err := GetRuntimeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtimeDir != "/run/user/1000" {
+		t.Fatalf("expected /run/user/1000, got %s", runtimeDir)
+	}
+}
@@ -0,0 +1,8 @@
+package rootless // import "github.com/docker/docker/pkg/rootless"
+
+import "os"
+
+// RunningWithRootlessKit returns true if running under RootlessKit namespaces.
+func RunningWithRootlessKit() bool {
+	return os.Getenv("ROOTLESSKIT_STATE_DIR") != ""
+}
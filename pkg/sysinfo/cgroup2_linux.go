@@ -0,0 +1,119 @@
+package sysinfo // import "github.com/docker/docker/pkg/sysinfo"
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// unifiedMountpoint is the mount point of the cgroup v2 hierarchy.
+	unifiedMountpoint = "/sys/fs/cgroup"
+	// cgroup2SuperMagic is the filesystem type of the cgroup v2 hierarchy.
+	cgroup2SuperMagic = 0x63677270
+)
+
+// isCgroup2UnifiedMode returns whether the host uses the cgroup v2 unified
+// hierarchy only.
+func isCgroup2UnifiedMode() bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(unifiedMountpoint, &st); err != nil {
+		return false
+	}
+	return st.Type == cgroup2SuperMagic
+}
+
+// newV2 fills the cgroup information of sysInfo from the cgroup v2 hierarchy,
+// using the controllers available to the cgroup of the current process.
+func newV2(quiet bool, sysInfo *SysInfo) {
+	sysInfo.CgroupUnified = true
+	// Access to devices is controlled with eBPF programs on cgroup v2,
+	// there is no devices controller to check.
+	sysInfo.CgroupDevicesEnabled = true
+
+	dir := unifiedMountpoint
+	if cg, err := cgroups.ParseCgroupFile("/proc/self/cgroup"); err != nil {
+		logrus.Warnf("Failed to parse cgroup information: %v", err)
+	} else if p, ok := cg[""]; ok {
+		dir = path.Join(unifiedMountpoint, p)
+	}
+	checkCgroup2(dir, quiet, sysInfo)
+}
+
+// checkCgroup2 reads the controllers available in the cgroup v2 directory dir
+// and sets the corresponding features in sysInfo.
+func checkCgroup2(dir string, quiet bool, sysInfo *SysInfo) {
+	controllers := make(map[string]bool)
+	content, err := ioutil.ReadFile(path.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		if !quiet {
+			logrus.Warnf("Failed to read cgroup controllers: %v", err)
+		}
+		return
+	}
+	for _, c := range strings.Fields(string(content)) {
+		controllers[c] = true
+	}
+
+	if controllers["memory"] {
+		sysInfo.cgroupMemInfo = cgroupMemInfo{
+			MemoryLimit:       true,
+			SwapLimit:         cgroupEnabled(dir, "memory.swap.max"),
+			MemoryReservation: true,
+		}
+		if !quiet && !sysInfo.SwapLimit {
+			logrus.Warn("Your kernel does not support swap memory limit")
+		}
+	} else if !quiet {
+		logrus.Warn("Unable to find memory controller in cgroup v2")
+	}
+
+	if controllers["cpu"] {
+		sysInfo.cgroupCPUInfo = cgroupCPUInfo{
+			CPUShares:    true,
+			CPUCfsPeriod: true,
+			CPUCfsQuota:  true,
+		}
+	} else if !quiet {
+		logrus.Warn("Unable to find cpu controller in cgroup v2")
+	}
+
+	if controllers["io"] {
+		sysInfo.cgroupBlkioInfo = cgroupBlkioInfo{
+			BlkioWeight:          true,
+			BlkioWeightDevice:    true,
+			BlkioReadBpsDevice:   true,
+			BlkioWriteBpsDevice:  true,
+			BlkioReadIOpsDevice:  true,
+			BlkioWriteIOpsDevice: true,
+		}
+	} else if !quiet {
+		logrus.Warn("Unable to find io controller in cgroup v2")
+	}
+
+	if controllers["cpuset"] {
+		cpus, err := ioutil.ReadFile(path.Join(dir, "cpuset.cpus.effective"))
+		if err == nil {
+			mems, err := ioutil.ReadFile(path.Join(dir, "cpuset.mems.effective"))
+			if err == nil {
+				sysInfo.cgroupCpusetInfo = cgroupCpusetInfo{
+					Cpuset: true,
+					Cpus:   strings.TrimSpace(string(cpus)),
+					Mems:   strings.TrimSpace(string(mems)),
+				}
+			}
+		}
+	} else if !quiet {
+		logrus.Warn("Unable to find cpuset controller in cgroup v2")
+	}
+
+	if controllers["pids"] {
+		sysInfo.cgroupPids = cgroupPids{PidsLimit: true}
+	} else if !quiet {
+		logrus.Warn("Unable to find pids controller in cgroup v2")
+	}
+}
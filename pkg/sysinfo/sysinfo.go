@@ -27,6 +27,9 @@ type SysInfo struct {
 
 	// Whether the cgroup has the mountpoint of "devices" or not
 	CgroupDevicesEnabled bool
+
+	// Whether the cgroup v2 unified hierarchy is used or not
+	CgroupUnified bool
 }
 
 type cgroupMemInfo struct {
@@ -102,6 +105,18 @@ type cgroupPids struct {
 	PidsLimit bool
 }
 
+// ClearCgroupControllers marks all the cgroup controllers as unsupported.
+// It is used when the cgroups cannot be managed, as for a rootless daemon
+// that the cgroup hierarchy has not been delegated to.
+func (s *SysInfo) ClearCgroupControllers() {
+	s.cgroupMemInfo = cgroupMemInfo{}
+	s.cgroupCPUInfo = cgroupCPUInfo{}
+	s.cgroupBlkioInfo = cgroupBlkioInfo{}
+	s.cgroupCpusetInfo = cgroupCpusetInfo{}
+	s.cgroupPids = cgroupPids{}
+	s.CgroupDevicesEnabled = false
+}
+
 // IsCpusetCpusAvailable returns `true` if the provided string set is contained
 // in cgroup's cpuset.cpus set, `false` otherwise.
 // If error is not nil a parsing error occurred.
@@ -102,3 +102,47 @@ func TestNumCPU(t *testing.T) {
 		t.Fatal("CPU returned must be greater than zero")
 	}
 }
+
+func TestCheckCgroup2(t *testing.T) {
+	cgroupDir, err := ioutil.TempDir("", "cgroup2-test")
+	assert.NilError(t, err)
+	defer os.RemoveAll(cgroupDir)
+
+	files := map[string]string{
+		"cgroup.controllers":    "cpuset cpu io memory pids\n",
+		"cpuset.cpus.effective": "0-3\n",
+		"cpuset.mems.effective": "0\n",
+		"memory.swap.max":       "max\n",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(cgroupDir, name), []byte(content), 0644)
+		assert.NilError(t, err)
+	}
+
+	sysInfo := &SysInfo{}
+	checkCgroup2(cgroupDir, true, sysInfo)
+	assert.Assert(t, sysInfo.MemoryLimit)
+	assert.Assert(t, sysInfo.SwapLimit)
+	assert.Assert(t, sysInfo.MemoryReservation)
+	assert.Assert(t, !sysInfo.KernelMemory)
+	assert.Assert(t, !sysInfo.OomKillDisable)
+	assert.Assert(t, sysInfo.CPUShares)
+	assert.Assert(t, sysInfo.CPUCfsQuota)
+	assert.Assert(t, !sysInfo.CPURealtimePeriod)
+	assert.Assert(t, sysInfo.BlkioWeight)
+	assert.Assert(t, sysInfo.BlkioReadBpsDevice)
+	assert.Assert(t, sysInfo.Cpuset)
+	assert.Equal(t, sysInfo.Cpus, "0-3")
+	assert.Equal(t, sysInfo.Mems, "0")
+	assert.Assert(t, sysInfo.PidsLimit)
+
+	err = ioutil.WriteFile(filepath.Join(cgroupDir, "cgroup.controllers"), []byte("pids\n"), 0644)
+	assert.NilError(t, err)
+	sysInfo = &SysInfo{}
+	checkCgroup2(cgroupDir, true, sysInfo)
+	assert.Assert(t, !sysInfo.MemoryLimit)
+	assert.Assert(t, !sysInfo.CPUShares)
+	assert.Assert(t, !sysInfo.BlkioWeight)
+	assert.Assert(t, !sysInfo.Cpuset)
+	assert.Assert(t, sysInfo.PidsLimit)
+}
@@ -24,3 +24,21 @@ func TestIsCpusetListAvailable(t *testing.T) {
 		}
 	}
 }
+
+func TestClearCgroupControllers(t *testing.T) {
+	s := &SysInfo{AppArmor: true, CgroupUnified: true, CgroupDevicesEnabled: true}
+	s.MemoryLimit = true
+	s.CPUShares = true
+	s.BlkioWeight = true
+	s.Cpuset = true
+	s.Cpus = "0-3"
+	s.PidsLimit = true
+
+	s.ClearCgroupControllers()
+	if s.MemoryLimit || s.CPUShares || s.BlkioWeight || s.Cpuset || s.Cpus != "" || s.PidsLimit || s.CgroupDevicesEnabled {
+		t.Fatalf("expected all cgroup controllers to be cleared, got %+v", s)
+	}
+	if !s.AppArmor || !s.CgroupUnified {
+		t.Fatalf("expected the other features to be kept, got %+v", s)
+	}
+}
@@ -0,0 +1,71 @@
+package csi // import "github.com/docker/docker/volume/csi"
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// shortTimeout is the timeout of the calls which don't provision or
+	// attach storage.
+	shortTimeout = 30 * time.Second
+	// longTimeout is the timeout of the calls which provision, attach or
+	// mount storage.
+	longTimeout = 2 * time.Minute
+)
+
+// dial connects to the plugin listening on the unix socket address. The
+// connection is established in the background, so that the daemon can be
+// started before the plugin.
+func dial(address string) (*grpc.ClientConn, error) {
+	return grpc.Dial(address,
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}),
+	)
+}
+
+// call invokes f with a context expiring after timeout, and converts the
+// gRPC status of a failed call to an errdefs error.
+func call(timeout time.Duration, method string, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return convertError(method, f(ctx))
+}
+
+func convertError(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return errdefs.System(errors.Wrap(err, method))
+	}
+	err = errors.Errorf("%s: %s", method, s.Message())
+	switch s.Code() {
+	case codes.NotFound:
+		return errdefs.NotFound(err)
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return errdefs.Conflict(err)
+	case codes.InvalidArgument, codes.OutOfRange:
+		return errdefs.InvalidParameter(err)
+	case codes.Unimplemented:
+		return errdefs.NotImplemented(err)
+	case codes.Unavailable, codes.ResourceExhausted:
+		return errdefs.Unavailable(err)
+	case codes.DeadlineExceeded:
+		return errdefs.Deadline(err)
+	case codes.Canceled:
+		return errdefs.Cancelled(err)
+	default:
+		return errdefs.System(err)
+	}
+}
@@ -0,0 +1,449 @@
+// Package csi provides a volume driver for plugins implementing the
+// Container Storage Interface (CSI).
+package csi // import "github.com/docker/docker/volume/csi"
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	spec "github.com/container-storage-interface/spec/lib/go/csi/v0"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/volume"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const stateFileName = "volume.json"
+
+// accessModes maps the values of the "access-mode" option to CSI access
+// modes.
+var accessModes = map[string]spec.VolumeCapability_AccessMode_Mode{
+	"single-node-writer":       spec.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+	"single-node-reader-only":  spec.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+	"multi-node-reader-only":   spec.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+	"multi-node-single-writer": spec.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
+	"multi-node-multi-writer":  spec.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+}
+
+// Driver is a volume.Driver backed by a CSI plugin. Volumes are provisioned
+// by the controller service of the plugin, and made available on this node
+// by its node service, which must be served on the same socket.
+//
+// CSI plugins identify volumes by an ID they generate; the driver keeps the
+// ID of every volume and whether it is published on this node, along with
+// the paths the volume is staged and published at, in a directory per
+// volume under its root.
+type Driver struct {
+	name       string
+	root       string
+	conn       *grpc.ClientConn
+	identity   spec.IdentityClient
+	controller spec.ControllerClient
+	node       spec.NodeClient
+
+	m       sync.Mutex
+	volumes map[string]*csiVolume
+	info    *pluginInfo
+}
+
+// pluginInfo holds the capabilities of the plugin and the information of
+// this node, which are discovered on first use.
+type pluginInfo struct {
+	nodeID   string
+	topology *spec.Topology
+	// accessibility is set when volumes may not be accessible from all
+	// the nodes.
+	accessibility bool
+	// publish is set when volumes must be attached to the node by the
+	// controller service before they are staged.
+	publish bool
+	// stage is set when volumes must be staged on the node before they
+	// are published.
+	stage bool
+}
+
+// volumeState is the state of a volume stored on disk.
+type volumeState struct {
+	ID         string
+	Capacity   int64             `json:",omitempty"`
+	Attributes map[string]string `json:",omitempty"`
+	Options    map[string]string `json:",omitempty"`
+	CreatedAt  time.Time
+	// Published is set while the volume is published on this node, and
+	// ReadOnly if it is published read-only. The volume is still published
+	// after a restart of the daemon, for the containers restored with
+	// live-restore, or until it is unpublished after a crash.
+	Published bool `json:",omitempty"`
+	ReadOnly  bool `json:",omitempty"`
+}
+
+// New returns a driver with the given name for the CSI plugin listening on
+// the unix socket address. The state of the volumes is stored in root. The
+// plugin doesn't need to be running yet: it is contacted on first use.
+func New(name, address, root string) (*Driver, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	conn, err := dial(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to CSI plugin %s", name)
+	}
+
+	d := &Driver{
+		name:       name,
+		root:       root,
+		conn:       conn,
+		identity:   spec.NewIdentityClient(conn),
+		controller: spec.NewControllerClient(conn),
+		node:       spec.NewNodeClient(conn),
+		volumes:    make(map[string]*csiVolume),
+	}
+
+	dirs, err := ioutil.ReadDir(root)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		name := dir.Name()
+		b, err := ioutil.ReadFile(filepath.Join(root, name, stateFileName))
+		if err != nil {
+			logrus.WithError(err).WithField("volume", name).Warn("Ignoring CSI volume without state")
+			continue
+		}
+		var state volumeState
+		if err := json.Unmarshal(b, &state); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "error while unmarshaling state of volume %s", name)
+		}
+		d.volumes[name] = d.newVolume(name, state)
+	}
+	return d, nil
+}
+
+// Close closes the connection to the plugin.
+func (d *Driver) Close() error {
+	return d.conn.Close()
+}
+
+// Name returns the name of the driver.
+func (d *Driver) Name() string {
+	return d.name
+}
+
+// Scope returns the local scope: volumes are only known to the node which
+// created them, as the driver keeps their IDs locally.
+func (d *Driver) Scope() string {
+	return volume.LocalScope
+}
+
+// Create provisions a volume with the CreateVolume call of the controller
+// service. The "size", "fstype", "mount-flags" and "access-mode" options
+// are handled by the driver; other options are passed to the plugin as
+// parameters. When the plugin has topology constraints, the volume is
+// required to be accessible from this node.
+func (d *Driver) Create(name string, opts map[string]string) (volume.Volume, error) {
+	capability, capacity, params, err := parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if v, exists := d.volumes[name]; exists {
+		return v, nil
+	}
+	info, err := d.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &spec.CreateVolumeRequest{
+		Name:               name,
+		CapacityRange:      capacity,
+		VolumeCapabilities: []*spec.VolumeCapability{capability},
+		Parameters:         params,
+	}
+	if info.accessibility && info.topology != nil {
+		req.AccessibilityRequirements = &spec.TopologyRequirement{
+			Requisite: []*spec.Topology{info.topology},
+			Preferred: []*spec.Topology{info.topology},
+		}
+	}
+	var resp *spec.CreateVolumeResponse
+	if err := call(longTimeout, "CreateVolume", func(ctx context.Context) (err error) {
+		resp, err = d.controller.CreateVolume(ctx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if resp.Volume == nil || resp.Volume.Id == "" {
+		return nil, errdefs.System(errors.Errorf("CSI plugin %s returned no volume ID for volume %s", d.name, name))
+	}
+
+	v, err := d.storeVolume(name, resp.Volume, opts, info)
+	if err != nil {
+		if err := d.deleteVolume(resp.Volume.Id); err != nil {
+			logrus.WithError(err).WithField("volume", name).Warn("Error deleting CSI volume after failed creation")
+		}
+		return nil, err
+	}
+	d.volumes[name] = v
+	return v, nil
+}
+
+// storeVolume checks that the volume created by the plugin can be used on
+// this node, and stores its state.
+func (d *Driver) storeVolume(name string, vol *spec.Volume, opts map[string]string, info *pluginInfo) (*csiVolume, error) {
+	if info.accessibility && info.topology != nil && !accessible(vol.AccessibleTopology, info.topology) {
+		return nil, errdefs.System(errors.Errorf("volume %s created by CSI plugin %s is not accessible from this node", name, d.name))
+	}
+
+	state := volumeState{
+		ID:         vol.Id,
+		Capacity:   vol.CapacityBytes,
+		Attributes: vol.Attributes,
+		Options:    opts,
+		CreatedAt:  time.Now().UTC(),
+	}
+	dir := filepath.Join(d.root, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := d.saveState(name, state); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return d.newVolume(name, state), nil
+}
+
+// saveState stores the state of the volume name on disk.
+func (d *Driver) saveState(name string, state volumeState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := ioutils.AtomicWriteFile(filepath.Join(d.root, name, stateFileName), b, 0600); err != nil {
+		return errdefs.System(errors.Wrap(err, "error while persisting volume state"))
+	}
+	return nil
+}
+
+// deleteVolume deletes the volume with the given ID with the DeleteVolume
+// call of the controller service.
+func (d *Driver) deleteVolume(id string) error {
+	return call(longTimeout, "DeleteVolume", func(ctx context.Context) error {
+		_, err := d.controller.DeleteVolume(ctx, &spec.DeleteVolumeRequest{VolumeId: id})
+		return err
+	})
+}
+
+// Remove deletes the volume with the DeleteVolume call of the controller
+// service. A volume which is mounted can't be removed; a volume which is
+// still published on this node without being mounted, after a crash of the
+// daemon, is unpublished first.
+func (d *Driver) Remove(v volume.Volume) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	cv, exists := d.volumes[v.Name()]
+	if !exists {
+		return errdefs.NotFound(errors.Errorf("volume %s not found", v.Name()))
+	}
+	if err := cv.release(); err != nil {
+		return err
+	}
+
+	if err := d.deleteVolume(cv.state.ID); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(d.root, v.Name())); err != nil {
+		return errdefs.System(errors.Wrapf(err, "error removing state of volume %s", v.Name()))
+	}
+	delete(d.volumes, v.Name())
+	return nil
+}
+
+// List returns the volumes created by the driver.
+func (d *Driver) List() ([]volume.Volume, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	ls := make([]volume.Volume, 0, len(d.volumes))
+	for _, v := range d.volumes {
+		ls = append(ls, v)
+	}
+	return ls, nil
+}
+
+// Get returns the volume with the given name.
+func (d *Driver) Get(name string) (volume.Volume, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	v, exists := d.volumes[name]
+	if !exists {
+		return nil, errdefs.NotFound(errors.Errorf("volume %s not found", name))
+	}
+	return v, nil
+}
+
+// getInfo returns the capabilities of the plugin, discovering them if
+// needed.
+func (d *Driver) getInfo() (*pluginInfo, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.discover()
+}
+
+// discover queries the capabilities of the plugin and the information of
+// this node. They are only queried until the plugin answers successfully.
+// d.m must be held.
+func (d *Driver) discover() (*pluginInfo, error) {
+	if d.info != nil {
+		return d.info, nil
+	}
+
+	var pluginCaps *spec.GetPluginCapabilitiesResponse
+	if err := call(shortTimeout, "GetPluginCapabilities", func(ctx context.Context) (err error) {
+		pluginCaps, err = d.identity.GetPluginCapabilities(ctx, &spec.GetPluginCapabilitiesRequest{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	info := &pluginInfo{}
+	var controller bool
+	for _, c := range pluginCaps.Capabilities {
+		switch c.GetService().GetType() {
+		case spec.PluginCapability_Service_CONTROLLER_SERVICE:
+			controller = true
+		case spec.PluginCapability_Service_ACCESSIBILITY_CONSTRAINTS:
+			info.accessibility = true
+		}
+	}
+	if !controller {
+		return nil, errdefs.NotImplemented(errors.Errorf("CSI plugin %s doesn't provide a controller service", d.name))
+	}
+
+	var controllerCaps *spec.ControllerGetCapabilitiesResponse
+	if err := call(shortTimeout, "ControllerGetCapabilities", func(ctx context.Context) (err error) {
+		controllerCaps, err = d.controller.ControllerGetCapabilities(ctx, &spec.ControllerGetCapabilitiesRequest{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	var createDelete bool
+	for _, c := range controllerCaps.Capabilities {
+		switch c.GetRpc().GetType() {
+		case spec.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME:
+			createDelete = true
+		case spec.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME:
+			info.publish = true
+		}
+	}
+	if !createDelete {
+		return nil, errdefs.NotImplemented(errors.Errorf("CSI plugin %s doesn't support creating volumes", d.name))
+	}
+
+	var nodeCaps *spec.NodeGetCapabilitiesResponse
+	if err := call(shortTimeout, "NodeGetCapabilities", func(ctx context.Context) (err error) {
+		nodeCaps, err = d.node.NodeGetCapabilities(ctx, &spec.NodeGetCapabilitiesRequest{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	for _, c := range nodeCaps.Capabilities {
+		if c.GetRpc().GetType() == spec.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME {
+			info.stage = true
+		}
+	}
+
+	var nodeInfo *spec.NodeGetInfoResponse
+	if err := call(shortTimeout, "NodeGetInfo", func(ctx context.Context) (err error) {
+		nodeInfo, err = d.node.NodeGetInfo(ctx, &spec.NodeGetInfoRequest{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	info.nodeID = nodeInfo.NodeId
+	info.topology = nodeInfo.AccessibleTopology
+
+	logrus.WithField("driver", d.name).Debugf("Discovered CSI plugin capabilities: node=%s publish=%t stage=%t accessibility=%t", info.nodeID, info.publish, info.stage, info.accessibility)
+	d.info = info
+	return info, nil
+}
+
+// parseOptions returns the capability, the capacity and the plugin
+// parameters for the given volume options.
+func parseOptions(opts map[string]string) (*spec.VolumeCapability, *spec.CapacityRange, map[string]string, error) {
+	mount := &spec.VolumeCapability_MountVolume{}
+	capability := &spec.VolumeCapability{
+		AccessType: &spec.VolumeCapability_Mount{Mount: mount},
+		AccessMode: &spec.VolumeCapability_AccessMode{Mode: spec.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+	var capacity *spec.CapacityRange
+	params := make(map[string]string)
+	for k, v := range opts {
+		switch k {
+		case "size":
+			size, err := units.RAMInBytes(v)
+			if err != nil || size <= 0 {
+				return nil, nil, nil, errdefs.InvalidParameter(errors.Errorf("invalid size for volume: %s", v))
+			}
+			capacity = &spec.CapacityRange{RequiredBytes: size}
+		case "fstype":
+			mount.FsType = v
+		case "mount-flags":
+			if v != "" {
+				mount.MountFlags = strings.Split(v, ",")
+			}
+		case "access-mode":
+			mode, ok := accessModes[v]
+			if !ok {
+				return nil, nil, nil, errdefs.InvalidParameter(errors.Errorf("invalid access mode for volume: %s", v))
+			}
+			capability.AccessMode.Mode = mode
+		default:
+			params[k] = v
+		}
+	}
+	return capability, capacity, params, nil
+}
+
+// accessible returns whether a volume accessible from the given topologies
+// is accessible from the node. A volume without topology is accessible from
+// all the nodes.
+func accessible(topologies []*spec.Topology, node *spec.Topology) bool {
+	if len(topologies) == 0 {
+		return true
+	}
+	for _, t := range topologies {
+		if t == nil {
+			continue
+		}
+		match := true
+		for k, v := range t.Segments {
+			if node.Segments[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,469 @@
+package csi // import "github.com/docker/docker/volume/csi"
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	spec "github.com/container-storage-interface/spec/lib/go/csi/v0"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakePlugin is a CSI plugin keeping its volumes in memory, and recording
+// the calls it receives.
+type fakePlugin struct {
+	noController bool
+	publish      bool
+	stage        bool
+	// topology is the topology of the node; volumes are created in
+	// volumeTopology.
+	topology       map[string]string
+	volumeTopology map[string]string
+	// attachedReadOnly and publishedReadOnly are set when the last volume
+	// was attached and published read-only.
+	attachedReadOnly  bool
+	publishedReadOnly bool
+
+	mu      sync.Mutex
+	calls   []string
+	volumes map[string]*spec.CreateVolumeRequest
+}
+
+func (p *fakePlugin) record(call string) {
+	p.mu.Lock()
+	p.calls = append(p.calls, call)
+	p.mu.Unlock()
+}
+
+func (p *fakePlugin) getCalls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := p.calls
+	p.calls = nil
+	return calls
+}
+
+func (p *fakePlugin) GetPluginInfo(ctx context.Context, req *spec.GetPluginInfoRequest) (*spec.GetPluginInfoResponse, error) {
+	p.record("GetPluginInfo")
+	return &spec.GetPluginInfoResponse{Name: "fake", VendorVersion: "1.0.0"}, nil
+}
+
+func (p *fakePlugin) GetPluginCapabilities(ctx context.Context, req *spec.GetPluginCapabilitiesRequest) (*spec.GetPluginCapabilitiesResponse, error) {
+	p.record("GetPluginCapabilities")
+	resp := &spec.GetPluginCapabilitiesResponse{}
+	if !p.noController {
+		resp.Capabilities = append(resp.Capabilities, pluginCapability(spec.PluginCapability_Service_CONTROLLER_SERVICE))
+	}
+	if p.topology != nil {
+		resp.Capabilities = append(resp.Capabilities, pluginCapability(spec.PluginCapability_Service_ACCESSIBILITY_CONSTRAINTS))
+	}
+	return resp, nil
+}
+
+func pluginCapability(t spec.PluginCapability_Service_Type) *spec.PluginCapability {
+	return &spec.PluginCapability{Type: &spec.PluginCapability_Service_{Service: &spec.PluginCapability_Service{Type: t}}}
+}
+
+func (p *fakePlugin) Probe(ctx context.Context, req *spec.ProbeRequest) (*spec.ProbeResponse, error) {
+	p.record("Probe")
+	return &spec.ProbeResponse{}, nil
+}
+
+func (p *fakePlugin) ControllerGetCapabilities(ctx context.Context, req *spec.ControllerGetCapabilitiesRequest) (*spec.ControllerGetCapabilitiesResponse, error) {
+	p.record("ControllerGetCapabilities")
+	resp := &spec.ControllerGetCapabilitiesResponse{
+		Capabilities: []*spec.ControllerServiceCapability{controllerCapability(spec.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME)},
+	}
+	if p.publish {
+		resp.Capabilities = append(resp.Capabilities, controllerCapability(spec.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME))
+	}
+	return resp, nil
+}
+
+func controllerCapability(t spec.ControllerServiceCapability_RPC_Type) *spec.ControllerServiceCapability {
+	return &spec.ControllerServiceCapability{Type: &spec.ControllerServiceCapability_Rpc{Rpc: &spec.ControllerServiceCapability_RPC{Type: t}}}
+}
+
+func (p *fakePlugin) CreateVolume(ctx context.Context, req *spec.CreateVolumeRequest) (*spec.CreateVolumeResponse, error) {
+	p.record("CreateVolume")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := "id-" + req.Name
+	p.volumes[id] = req
+	vol := &spec.Volume{Id: id, Attributes: map[string]string{"pool": "default"}}
+	if req.CapacityRange != nil {
+		vol.CapacityBytes = req.CapacityRange.RequiredBytes
+	}
+	if p.volumeTopology != nil {
+		vol.AccessibleTopology = []*spec.Topology{{Segments: p.volumeTopology}}
+	}
+	return &spec.CreateVolumeResponse{Volume: vol}, nil
+}
+
+func (p *fakePlugin) DeleteVolume(ctx context.Context, req *spec.DeleteVolumeRequest) (*spec.DeleteVolumeResponse, error) {
+	p.record("DeleteVolume")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.volumes[req.VolumeId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", req.VolumeId)
+	}
+	delete(p.volumes, req.VolumeId)
+	return &spec.DeleteVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) ControllerPublishVolume(ctx context.Context, req *spec.ControllerPublishVolumeRequest) (*spec.ControllerPublishVolumeResponse, error) {
+	p.record("ControllerPublishVolume")
+	p.attachedReadOnly = req.Readonly
+	if req.NodeId != "node1" {
+		return nil, status.Errorf(codes.NotFound, "node %s not found", req.NodeId)
+	}
+	return &spec.ControllerPublishVolumeResponse{PublishInfo: map[string]string{"device": "/dev/fake"}}, nil
+}
+
+func (p *fakePlugin) ControllerUnpublishVolume(ctx context.Context, req *spec.ControllerUnpublishVolumeRequest) (*spec.ControllerUnpublishVolumeResponse, error) {
+	p.record("ControllerUnpublishVolume")
+	return &spec.ControllerUnpublishVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) ValidateVolumeCapabilities(ctx context.Context, req *spec.ValidateVolumeCapabilitiesRequest) (*spec.ValidateVolumeCapabilitiesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ValidateVolumeCapabilities is not implemented")
+}
+
+func (p *fakePlugin) ListVolumes(ctx context.Context, req *spec.ListVolumesRequest) (*spec.ListVolumesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ListVolumes is not implemented")
+}
+
+func (p *fakePlugin) GetCapacity(ctx context.Context, req *spec.GetCapacityRequest) (*spec.GetCapacityResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "GetCapacity is not implemented")
+}
+
+func (p *fakePlugin) CreateSnapshot(ctx context.Context, req *spec.CreateSnapshotRequest) (*spec.CreateSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "CreateSnapshot is not implemented")
+}
+
+func (p *fakePlugin) DeleteSnapshot(ctx context.Context, req *spec.DeleteSnapshotRequest) (*spec.DeleteSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "DeleteSnapshot is not implemented")
+}
+
+func (p *fakePlugin) ListSnapshots(ctx context.Context, req *spec.ListSnapshotsRequest) (*spec.ListSnapshotsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ListSnapshots is not implemented")
+}
+
+func (p *fakePlugin) NodeGetCapabilities(ctx context.Context, req *spec.NodeGetCapabilitiesRequest) (*spec.NodeGetCapabilitiesResponse, error) {
+	p.record("NodeGetCapabilities")
+	resp := &spec.NodeGetCapabilitiesResponse{}
+	if p.stage {
+		resp.Capabilities = append(resp.Capabilities, &spec.NodeServiceCapability{
+			Type: &spec.NodeServiceCapability_Rpc{Rpc: &spec.NodeServiceCapability_RPC{Type: spec.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME}},
+		})
+	}
+	return resp, nil
+}
+
+func (p *fakePlugin) NodeGetInfo(ctx context.Context, req *spec.NodeGetInfoRequest) (*spec.NodeGetInfoResponse, error) {
+	p.record("NodeGetInfo")
+	resp := &spec.NodeGetInfoResponse{NodeId: "node1"}
+	if p.topology != nil {
+		resp.AccessibleTopology = &spec.Topology{Segments: p.topology}
+	}
+	return resp, nil
+}
+
+func (p *fakePlugin) NodeStageVolume(ctx context.Context, req *spec.NodeStageVolumeRequest) (*spec.NodeStageVolumeResponse, error) {
+	p.record("NodeStageVolume")
+	if p.publish && req.PublishInfo["device"] != "/dev/fake" {
+		return nil, status.Error(codes.InvalidArgument, "missing publish context")
+	}
+	return &spec.NodeStageVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) NodeUnstageVolume(ctx context.Context, req *spec.NodeUnstageVolumeRequest) (*spec.NodeUnstageVolumeResponse, error) {
+	p.record("NodeUnstageVolume")
+	return &spec.NodeUnstageVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) NodePublishVolume(ctx context.Context, req *spec.NodePublishVolumeRequest) (*spec.NodePublishVolumeResponse, error) {
+	p.record("NodePublishVolume")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.publishedReadOnly = req.Readonly
+	if p.stage && req.StagingTargetPath == "" {
+		return nil, status.Error(codes.FailedPrecondition, "volume is not staged")
+	}
+	if _, ok := p.volumes[req.VolumeId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", req.VolumeId)
+	}
+	return &spec.NodePublishVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) NodeUnpublishVolume(ctx context.Context, req *spec.NodeUnpublishVolumeRequest) (*spec.NodeUnpublishVolumeResponse, error) {
+	p.record("NodeUnpublishVolume")
+	return &spec.NodeUnpublishVolumeResponse{}, nil
+}
+
+func (p *fakePlugin) NodeGetId(ctx context.Context, req *spec.NodeGetIdRequest) (*spec.NodeGetIdResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "NodeGetId is deprecated")
+}
+
+// serve starts a gRPC server for the plugin on a unix socket in dir, and
+// returns the address of the socket.
+func (p *fakePlugin) serve(t *testing.T, dir string) (string, func()) {
+	p.volumes = make(map[string]*spec.CreateVolumeRequest)
+	address := filepath.Join(dir, "csi.sock")
+	l, err := net.Listen("unix", address)
+	assert.NilError(t, err)
+
+	server := grpc.NewServer()
+	spec.RegisterIdentityServer(server, p)
+	spec.RegisterControllerServer(server, p)
+	spec.RegisterNodeServer(server, p)
+	go server.Serve(l)
+	return address, server.Stop
+}
+
+func setupDriver(t *testing.T, p *fakePlugin) (*Driver, func()) {
+	dir, err := ioutil.TempDir("", "csi-driver-test")
+	assert.NilError(t, err)
+	address, stop := p.serve(t, dir)
+
+	d, err := New("fake", address, filepath.Join(dir, "volumes"))
+	assert.NilError(t, err)
+	return d, func() {
+		d.Close()
+		stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCreateMountRemove(t *testing.T) {
+	p := &fakePlugin{publish: true, stage: true}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	v, err := d.Create("data", map[string]string{"size": "1G", "fstype": "xfs", "pool": "fast"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{
+		"GetPluginCapabilities", "ControllerGetCapabilities", "NodeGetCapabilities", "NodeGetInfo", "CreateVolume",
+	}, p.getCalls()))
+
+	req := p.volumes["id-data"]
+	assert.Assert(t, req != nil)
+	assert.Check(t, is.Equal(int64(1<<30), req.CapacityRange.RequiredBytes))
+	assert.Check(t, is.DeepEqual(map[string]string{"pool": "fast"}, req.Parameters))
+	assert.Check(t, is.Equal("xfs", req.VolumeCapabilities[0].GetMount().FsType))
+	assert.Check(t, is.Equal(spec.VolumeCapability_AccessMode_SINGLE_NODE_WRITER, req.VolumeCapabilities[0].AccessMode.Mode))
+	assert.Check(t, is.Equal("id-data", v.Status()["VolumeID"]))
+
+	path, err := v.Mount("1")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(v.Path(), path))
+	_, err = v.Mount("2")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"ControllerPublishVolume", "NodeStageVolume", "NodePublishVolume"}, p.getCalls()))
+
+	err = d.Remove(v)
+	assert.Check(t, errdefs.IsConflict(err), "expected a conflict error, got %v", err)
+
+	assert.NilError(t, v.Unmount("1"))
+	assert.Check(t, is.Len(p.getCalls(), 0))
+	assert.NilError(t, v.Unmount("2"))
+	assert.Check(t, is.DeepEqual([]string{"NodeUnpublishVolume", "NodeUnstageVolume", "ControllerUnpublishVolume"}, p.getCalls()))
+
+	assert.NilError(t, d.Remove(v))
+	assert.Check(t, is.DeepEqual([]string{"DeleteVolume"}, p.getCalls()))
+	assert.Check(t, is.Len(p.volumes, 0))
+	_, err = d.Get("data")
+	assert.Check(t, errdefs.IsNotFound(err), "expected a not found error, got %v", err)
+}
+
+func TestMountWithoutStage(t *testing.T) {
+	p := &fakePlugin{}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	v, err := d.Create("data", nil)
+	assert.NilError(t, err)
+	p.getCalls()
+
+	_, err = v.Mount("1")
+	assert.NilError(t, err)
+	assert.NilError(t, v.Unmount("1"))
+	assert.Check(t, is.DeepEqual([]string{"NodePublishVolume", "NodeUnpublishVolume"}, p.getCalls()))
+}
+
+func TestMountFailure(t *testing.T) {
+	p := &fakePlugin{publish: true, stage: true}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	v, err := d.Create("data", nil)
+	assert.NilError(t, err)
+	p.getCalls()
+
+	// the plugin doesn't know the volume anymore: publishing it fails, and
+	// the volume is unstaged and detached again
+	delete(p.volumes, "id-data")
+	_, err = v.Mount("1")
+	assert.Check(t, errdefs.IsNotFound(err), "expected a not found error, got %v", err)
+	assert.Check(t, is.DeepEqual([]string{
+		"ControllerPublishVolume", "NodeStageVolume", "NodePublishVolume", "NodeUnstageVolume", "ControllerUnpublishVolume",
+	}, p.getCalls()))
+
+	// volumes already deleted from the plugin can be removed
+	assert.NilError(t, d.Remove(v))
+}
+
+func TestCreateTopology(t *testing.T) {
+	p := &fakePlugin{
+		topology:       map[string]string{"region": "eu", "zone": "eu-1"},
+		volumeTopology: map[string]string{"zone": "eu-1"},
+	}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	_, err := d.Create("data", nil)
+	assert.NilError(t, err)
+	req := p.volumes["id-data"]
+	assert.Assert(t, req != nil && req.AccessibilityRequirements != nil)
+	assert.Check(t, is.DeepEqual(p.topology, req.AccessibilityRequirements.Requisite[0].Segments))
+
+	p.volumeTopology = map[string]string{"zone": "us-1"}
+	_, err = d.Create("other", nil)
+	assert.Check(t, is.ErrorContains(err, "not accessible from this node"))
+	_, ok := p.volumes["id-other"]
+	assert.Check(t, !ok, "volume which isn't accessible must be deleted")
+	_, err = d.Get("other")
+	assert.Check(t, errdefs.IsNotFound(err), "expected a not found error, got %v", err)
+}
+
+func TestCreateWithoutController(t *testing.T) {
+	p := &fakePlugin{noController: true}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	_, err := d.Create("data", nil)
+	assert.Check(t, errdefs.IsNotImplemented(err), "expected a not implemented error, got %v", err)
+}
+
+func TestRestore(t *testing.T) {
+	p := &fakePlugin{}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	_, err := d.Create("data", map[string]string{"access-mode": "multi-node-reader-only"})
+	assert.NilError(t, err)
+
+	d2, err := New("fake", "unused.sock", d.root)
+	assert.NilError(t, err)
+	defer d2.Close()
+	v, err := d2.Get("data")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("id-data", v.Status()["VolumeID"]))
+	assert.Check(t, is.DeepEqual(map[string]string{"access-mode": "multi-node-reader-only"}, v.(*csiVolume).state.Options))
+}
+
+func TestParseOptions(t *testing.T) {
+	capability, capacity, params, err := parseOptions(map[string]string{
+		"size":        "10M",
+		"mount-flags": "noatime,nodiratime",
+		"access-mode": "multi-node-multi-writer",
+		"type":        "ssd",
+	})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(int64(10<<20), capacity.RequiredBytes))
+	assert.Check(t, is.DeepEqual([]string{"noatime", "nodiratime"}, capability.GetMount().MountFlags))
+	assert.Check(t, is.Equal(spec.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER, capability.AccessMode.Mode))
+	assert.Check(t, is.DeepEqual(map[string]string{"type": "ssd"}, params))
+
+	for _, opts := range []map[string]string{
+		{"size": "invalid"},
+		{"size": "0"},
+		{"access-mode": "everyone"},
+	} {
+		_, _, _, err := parseOptions(opts)
+		assert.Check(t, errdefs.IsInvalidParameter(err), "expected an invalid parameter error for %v, got %v", opts, err)
+	}
+}
+
+func TestRestoreMounts(t *testing.T) {
+	p := &fakePlugin{stage: true}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	for _, name := range []string{"crashed", "live"} {
+		v, err := d.Create(name, nil)
+		assert.NilError(t, err)
+		_, err = v.Mount("1")
+		assert.NilError(t, err)
+	}
+
+	d2, err := New("fake", filepath.Join(filepath.Dir(d.root), "csi.sock"), d.root)
+	assert.NilError(t, err)
+	defer d2.Close()
+	p.getCalls()
+
+	// the mounts are not restored: a volume which is still published after
+	// a crash is unpublished when it's removed
+	v, err := d2.Get("crashed")
+	assert.NilError(t, err)
+	assert.NilError(t, d2.Remove(v))
+	assert.Check(t, is.DeepEqual([]string{
+		"GetPluginCapabilities", "ControllerGetCapabilities", "NodeGetCapabilities", "NodeGetInfo",
+		"NodeUnpublishVolume", "NodeUnstageVolume", "DeleteVolume",
+	}, p.getCalls()))
+
+	// the mounts of containers restored with live-restore are kept
+	v, err = d2.Get("live")
+	assert.NilError(t, err)
+	assert.NilError(t, v.(volume.LiveRestorer).LiveRestoreVolume("1"))
+	err = d2.Remove(v)
+	assert.Check(t, errdefs.IsConflict(err), "expected a conflict error, got %v", err)
+	_, err = v.Mount("2")
+	assert.NilError(t, err)
+	assert.NilError(t, v.Unmount("1"))
+	assert.Check(t, is.Len(p.getCalls(), 0))
+	assert.NilError(t, v.Unmount("2"))
+	assert.Check(t, is.DeepEqual([]string{"NodeUnpublishVolume", "NodeUnstageVolume"}, p.getCalls()))
+	assert.NilError(t, d2.Remove(v))
+}
+
+func TestMountReadOnly(t *testing.T) {
+	p := &fakePlugin{publish: true}
+	d, cleanup := setupDriver(t, p)
+	defer cleanup()
+
+	v, err := d.Create("data", nil)
+	assert.NilError(t, err)
+	rom := v.(volume.ReadOnlyMounter)
+
+	_, err = rom.MountReadOnly("1")
+	assert.NilError(t, err)
+	assert.Check(t, p.attachedReadOnly)
+	assert.Check(t, p.publishedReadOnly)
+
+	// the volume is published read-only until it is unmounted
+	_, err = v.Mount("2")
+	assert.Check(t, errdefs.IsConflict(err), "expected a conflict error, got %v", err)
+	_, err = rom.MountReadOnly("3")
+	assert.NilError(t, err)
+	assert.NilError(t, v.Unmount("1"))
+	assert.NilError(t, v.Unmount("3"))
+
+	_, err = v.Mount("2")
+	assert.NilError(t, err)
+	assert.Check(t, !p.attachedReadOnly)
+	assert.Check(t, !p.publishedReadOnly)
+	_, err = rom.MountReadOnly("3")
+	assert.NilError(t, err)
+	assert.Check(t, !p.publishedReadOnly)
+}
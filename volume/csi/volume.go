@@ -0,0 +1,319 @@
+package csi // import "github.com/docker/docker/volume/csi"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	spec "github.com/container-storage-interface/spec/lib/go/csi/v0"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// csiVolume is a volume provisioned by a CSI plugin.
+type csiVolume struct {
+	driver *Driver
+	name   string
+	state  volumeState
+	// stagingPath is the path the volume is staged at, if the plugin
+	// stages volumes.
+	stagingPath string
+	// targetPath is the path the volume is published at.
+	targetPath string
+
+	// m protects mounts and the publication state in state.
+	m sync.Mutex
+	// mounts is the number of active mounts of the volume by mount ID.
+	mounts map[string]int
+}
+
+func (d *Driver) newVolume(name string, state volumeState) *csiVolume {
+	dir := filepath.Join(d.root, name)
+	return &csiVolume{
+		driver:      d,
+		name:        name,
+		state:       state,
+		stagingPath: filepath.Join(dir, "staging"),
+		targetPath:  filepath.Join(dir, "mount"),
+		mounts:      make(map[string]int),
+	}
+}
+
+// Name returns the name of the volume.
+func (v *csiVolume) Name() string {
+	return v.name
+}
+
+// DriverName returns the name of the driver of the volume.
+func (v *csiVolume) DriverName() string {
+	return v.driver.Name()
+}
+
+// Path returns the path the volume is published at.
+func (v *csiVolume) Path() string {
+	return v.targetPath
+}
+
+// CreatedAt returns the time the volume was created.
+func (v *csiVolume) CreatedAt() (time.Time, error) {
+	return v.state.CreatedAt, nil
+}
+
+// Status returns the ID and the attributes of the volume in the plugin.
+func (v *csiVolume) Status() map[string]interface{} {
+	status := map[string]interface{}{
+		"VolumeID": v.state.ID,
+	}
+	if v.state.Capacity > 0 {
+		status["CapacityBytes"] = v.state.Capacity
+	}
+	if len(v.state.Attributes) > 0 {
+		status["VolumeAttributes"] = v.state.Attributes
+	}
+	return status
+}
+
+// saveState stores the publication state of the volume on disk. v.m must
+// be held.
+func (v *csiVolume) saveState() {
+	if err := v.driver.saveState(v.name, v.state); err != nil {
+		logrus.WithError(err).WithField("volume", v.name).Warn("Error storing the state of CSI volume")
+	}
+}
+
+// release unpublishes the volume if it is still published on this node,
+// so that it can be deleted. It fails if the volume is mounted. d.m must be
+// held.
+func (v *csiVolume) release() error {
+	v.m.Lock()
+	defer v.m.Unlock()
+	if len(v.mounts) > 0 {
+		return errdefs.Conflict(errors.Errorf("volume %s is mounted", v.name))
+	}
+	if !v.state.Published {
+		return nil
+	}
+	info, err := v.driver.discover()
+	if err != nil {
+		return err
+	}
+	return v.unpublish(info)
+}
+
+// Mount makes the volume available on this node on first use: the volume
+// is attached to the node with the ControllerPublishVolume call if the
+// plugin requires it, staged with NodeStageVolume if the plugin supports
+// it, and published with NodePublishVolume. Steps which succeeded are
+// undone if a later step fails. The mount id is recorded, so that the
+// volume stays published until it is unmounted.
+func (v *csiVolume) Mount(id string) (string, error) {
+	return v.mount(id, false)
+}
+
+// MountReadOnly mounts the volume like Mount, but publishes it read-only if
+// it isn't mounted yet. A volume published read-only can't be mounted
+// read-write until all its mounts are unmounted.
+func (v *csiVolume) MountReadOnly(id string) (string, error) {
+	return v.mount(id, true)
+}
+
+// LiveRestoreVolume records the mount id of a container restored with
+// live-restore: the volume is still published for it.
+func (v *csiVolume) LiveRestoreVolume(id string) error {
+	v.m.Lock()
+	defer v.m.Unlock()
+	v.mounts[id]++
+	return nil
+}
+
+func (v *csiVolume) mount(id string, readOnly bool) (string, error) {
+	info, err := v.driver.getInfo()
+	if err != nil {
+		return "", err
+	}
+
+	v.m.Lock()
+	defer v.m.Unlock()
+	if len(v.mounts) > 0 {
+		if v.state.ReadOnly && !readOnly {
+			return "", errdefs.Conflict(errors.Errorf("volume %s is mounted read-only, it can't be mounted read-write", v.name))
+		}
+		v.mounts[id]++
+		return v.targetPath, nil
+	}
+	// The volume may still be published after a crash of the daemon, but
+	// not anymore on the node (e.g. after a reboot). Publishing a volume
+	// again with the same arguments succeeds, so it's only unpublished if
+	// the access differs.
+	if v.state.Published && v.state.ReadOnly != readOnly {
+		if err := v.unpublish(info); err != nil {
+			return "", err
+		}
+	}
+
+	capability, _, _, err := parseOptions(v.state.Options)
+	if err != nil {
+		return "", err
+	}
+	node := v.driver.node
+
+	var publishInfo map[string]string
+	if info.publish {
+		var resp *spec.ControllerPublishVolumeResponse
+		err := call(longTimeout, "ControllerPublishVolume", func(ctx context.Context) (err error) {
+			resp, err = v.driver.controller.ControllerPublishVolume(ctx, &spec.ControllerPublishVolumeRequest{
+				VolumeId:         v.state.ID,
+				NodeId:           info.nodeID,
+				VolumeCapability: capability,
+				Readonly:         readOnly,
+				VolumeAttributes: v.state.Attributes,
+			})
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		publishInfo = resp.PublishInfo
+	}
+
+	var stagingPath string
+	if info.stage {
+		stagingPath = v.stagingPath
+		err := os.MkdirAll(stagingPath, 0700)
+		if err == nil {
+			err = call(longTimeout, "NodeStageVolume", func(ctx context.Context) error {
+				_, err := node.NodeStageVolume(ctx, &spec.NodeStageVolumeRequest{
+					VolumeId:          v.state.ID,
+					PublishInfo:       publishInfo,
+					StagingTargetPath: stagingPath,
+					VolumeCapability:  capability,
+					VolumeAttributes:  v.state.Attributes,
+				})
+				return err
+			})
+		}
+		if err != nil {
+			v.detach(info)
+			return "", err
+		}
+	}
+
+	err = os.MkdirAll(v.targetPath, 0755)
+	if err == nil {
+		err = call(longTimeout, "NodePublishVolume", func(ctx context.Context) error {
+			_, err := node.NodePublishVolume(ctx, &spec.NodePublishVolumeRequest{
+				VolumeId:          v.state.ID,
+				PublishInfo:       publishInfo,
+				StagingTargetPath: stagingPath,
+				TargetPath:        v.targetPath,
+				VolumeCapability:  capability,
+				Readonly:          readOnly,
+				VolumeAttributes:  v.state.Attributes,
+			})
+			return err
+		})
+	}
+	if err != nil {
+		if info.stage {
+			v.unstage()
+		}
+		v.detach(info)
+		return "", err
+	}
+
+	v.mounts[id] = 1
+	v.state.Published = true
+	v.state.ReadOnly = readOnly
+	v.saveState()
+	return v.targetPath, nil
+}
+
+// Unmount unpublishes, unstages and detaches the volume from this node
+// when it is no longer in use. Unmounting an id which isn't mounted is a
+// no-op.
+func (v *csiVolume) Unmount(id string) error {
+	info, err := v.driver.getInfo()
+	if err != nil {
+		return err
+	}
+
+	v.m.Lock()
+	defer v.m.Unlock()
+	if v.mounts[id] == 0 {
+		return nil
+	}
+	if v.mounts[id]--; v.mounts[id] == 0 {
+		delete(v.mounts, id)
+	}
+	if len(v.mounts) > 0 {
+		return nil
+	}
+	if err := v.unpublish(info); err != nil {
+		if v.state.Published {
+			// keep the mount, so that unmounting it can be retried
+			v.mounts[id] = 1
+		}
+		return err
+	}
+	return nil
+}
+
+// unpublish unpublishes, unstages and detaches the volume from this node.
+// v.m must be held.
+func (v *csiVolume) unpublish(info *pluginInfo) error {
+	err := call(longTimeout, "NodeUnpublishVolume", func(ctx context.Context) error {
+		_, err := v.driver.node.NodeUnpublishVolume(ctx, &spec.NodeUnpublishVolumeRequest{
+			VolumeId:   v.state.ID,
+			TargetPath: v.targetPath,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	v.state.Published = false
+	v.state.ReadOnly = false
+	v.saveState()
+	if info.stage {
+		if err := v.unstage(); err != nil {
+			return err
+		}
+	}
+	return v.detach(info)
+}
+
+func (v *csiVolume) unstage() error {
+	err := call(longTimeout, "NodeUnstageVolume", func(ctx context.Context) error {
+		_, err := v.driver.node.NodeUnstageVolume(ctx, &spec.NodeUnstageVolumeRequest{
+			VolumeId:          v.state.ID,
+			StagingTargetPath: v.stagingPath,
+		})
+		return err
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("volume", v.name).Warn("Error unstaging CSI volume")
+		return errors.Wrapf(err, "error unstaging volume %s", v.name)
+	}
+	return nil
+}
+
+func (v *csiVolume) detach(info *pluginInfo) error {
+	if !info.publish {
+		return nil
+	}
+	err := call(longTimeout, "ControllerUnpublishVolume", func(ctx context.Context) error {
+		_, err := v.driver.controller.ControllerUnpublishVolume(ctx, &spec.ControllerUnpublishVolumeRequest{
+			VolumeId: v.state.ID,
+			NodeId:   info.nodeID,
+		})
+		return err
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("volume", v.name).Warn("Error detaching CSI volume from node")
+		return errors.Wrapf(err, "error detaching volume %s", v.name)
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package drivers // import "github.com/docker/docker/volume/drivers"
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/volume"
 	"github.com/sirupsen/logrus"
 )
@@ -81,6 +83,49 @@ func (a *volumeDriverAdapter) Get(name string) (volume.Volume, error) {
 	}, nil
 }
 
+// Clone creates a volume holding a copy of src, if the plugin advertises
+// the Clone capability.
+func (a *volumeDriverAdapter) Clone(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	if !a.getCapabilities().Clone {
+		return nil, errdefs.NotImplemented(fmt.Errorf("volume driver %s does not support cloning volumes", a.name))
+	}
+	if err := a.proxy.Clone(name, src.Name(), opts); err != nil {
+		return nil, err
+	}
+	return &volumeAdapter{
+		proxy:      a.proxy,
+		name:       name,
+		driverName: a.name,
+		scopePath:  a.scopePath,
+	}, nil
+}
+
+// Snapshot creates a volume holding a snapshot of src, if the plugin
+// advertises the Snapshot capability.
+func (a *volumeDriverAdapter) Snapshot(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	if !a.getCapabilities().Snapshot {
+		return nil, errdefs.NotImplemented(fmt.Errorf("volume driver %s does not support volume snapshots", a.name))
+	}
+	if err := a.proxy.Snapshot(name, src.Name(), opts); err != nil {
+		return nil, err
+	}
+	return &volumeAdapter{
+		proxy:      a.proxy,
+		name:       name,
+		driverName: a.name,
+		scopePath:  a.scopePath,
+	}, nil
+}
+
+// Update changes options of v, if the plugin advertises the Update
+// capability.
+func (a *volumeDriverAdapter) Update(v volume.Volume, opts map[string]string) error {
+	if !a.getCapabilities().Update {
+		return errdefs.NotImplemented(fmt.Errorf("volume driver %s does not support updating volumes", a.name))
+	}
+	return a.proxy.Update(v.Name(), opts)
+}
+
 func (a *volumeDriverAdapter) Scope() string {
 	cap := a.getCapabilities()
 	return cap.Scope
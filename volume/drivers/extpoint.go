@@ -44,6 +44,12 @@ type volumeDriver interface {
 	Get(name string) (volume *proxyVolume, err error)
 	// Capabilities gets the list of capabilities of the driver
 	Capabilities() (capabilities volume.Capability, err error)
+	// Clone creates a volume with the given name holding a copy of the source volume
+	Clone(name, source string, opts map[string]string) (err error)
+	// Snapshot creates a volume with the given name holding a snapshot of the source volume
+	Snapshot(name, source string, opts map[string]string) (err error)
+	// Update changes options of the volume with the given name
+	Update(name string, opts map[string]string) (err error)
 }
 
 // Store is an in-memory store for volume drivers
@@ -253,3 +253,94 @@ func (pp *volumeDriverProxy) Capabilities() (capabilities volume.Capability, err
 
 	return
 }
+
+type volumeDriverProxyCloneRequest struct {
+	Name   string
+	Source string
+	Opts   map[string]string
+}
+
+type volumeDriverProxyCloneResponse struct {
+	Err string
+}
+
+func (pp *volumeDriverProxy) Clone(name string, source string, opts map[string]string) (err error) {
+	var (
+		req volumeDriverProxyCloneRequest
+		ret volumeDriverProxyCloneResponse
+	)
+
+	req.Name = name
+	req.Source = source
+	req.Opts = opts
+
+	if err = pp.CallWithOptions("VolumeDriver.Clone", req, &ret, plugins.WithRequestTimeout(longTimeout)); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
+
+type volumeDriverProxySnapshotRequest struct {
+	Name   string
+	Source string
+	Opts   map[string]string
+}
+
+type volumeDriverProxySnapshotResponse struct {
+	Err string
+}
+
+func (pp *volumeDriverProxy) Snapshot(name string, source string, opts map[string]string) (err error) {
+	var (
+		req volumeDriverProxySnapshotRequest
+		ret volumeDriverProxySnapshotResponse
+	)
+
+	req.Name = name
+	req.Source = source
+	req.Opts = opts
+
+	if err = pp.CallWithOptions("VolumeDriver.Snapshot", req, &ret, plugins.WithRequestTimeout(longTimeout)); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
+
+type volumeDriverProxyUpdateRequest struct {
+	Name string
+	Opts map[string]string
+}
+
+type volumeDriverProxyUpdateResponse struct {
+	Err string
+}
+
+func (pp *volumeDriverProxy) Update(name string, opts map[string]string) (err error) {
+	var (
+		req volumeDriverProxyUpdateRequest
+		ret volumeDriverProxyUpdateResponse
+	)
+
+	req.Name = name
+	req.Opts = opts
+
+	if err = pp.CallWithOptions("VolumeDriver.Update", req, &ret, plugins.WithRequestTimeout(longTimeout)); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
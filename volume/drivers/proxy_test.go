@@ -8,7 +8,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/volume"
 	"github.com/docker/go-connections/tlsconfig"
 )
 
@@ -57,6 +59,21 @@ func TestVolumeRequestError(t *testing.T) {
 		http.Error(w, "error", 500)
 	})
 
+	mux.HandleFunc("/VolumeDriver.Clone", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Err": "Cannot clone volume"}`)
+	})
+
+	mux.HandleFunc("/VolumeDriver.Snapshot", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Err": "Cannot snapshot volume"}`)
+	})
+
+	mux.HandleFunc("/VolumeDriver.Update", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Err": "Cannot update volume"}`)
+	})
+
 	u, _ := url.Parse(server.URL)
 	client, err := plugins.NewClient("tcp://"+u.Host, &tlsconfig.Options{InsecureSkipVerify: true})
 	if err != nil {
@@ -129,4 +146,71 @@ func TestVolumeRequestError(t *testing.T) {
 	if err == nil {
 		t.Fatal(err)
 	}
+
+	err = driver.Clone("volume", "source", nil)
+	if err == nil {
+		t.Fatal("Expected error, was nil")
+	}
+	if !strings.Contains(err.Error(), "Cannot clone volume") {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+
+	err = driver.Snapshot("volume", "source", nil)
+	if err == nil {
+		t.Fatal("Expected error, was nil")
+	}
+	if !strings.Contains(err.Error(), "Cannot snapshot volume") {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+
+	err = driver.Update("volume", map[string]string{"size": "10G"})
+	if err == nil {
+		t.Fatal("Expected error, was nil")
+	}
+	if !strings.Contains(err.Error(), "Cannot update volume") {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+}
+
+func TestVolumeDriverCloneCapability(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var cloned bool
+	mux.HandleFunc("/VolumeDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{"Capabilities": {"Scope": "local", "Clone": true}}`)
+	})
+	mux.HandleFunc("/VolumeDriver.Clone", func(w http.ResponseWriter, r *http.Request) {
+		cloned = true
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, `{}`)
+	})
+
+	u, _ := url.Parse(server.URL)
+	client, err := plugins.NewClient("tcp://"+u.Host, &tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewVolumeDriver("fake", func(s string) string { return s }, client)
+	src := &volumeAdapter{name: "source", driverName: "fake"}
+
+	v, err := d.(volume.Cloner).Clone(src, "clone", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cloned || v.Name() != "clone" {
+		t.Fatalf("expected volume to be cloned by the plugin, got %q", v.Name())
+	}
+
+	_, err = d.(volume.Snapshotter).Snapshot(src, "snapshot", nil)
+	if !errdefs.IsNotImplemented(err) {
+		t.Fatalf("expected not implemented error, got: %v", err)
+	}
+
+	err = d.(volume.Updater).Update(src, map[string]string{"size": "10G"})
+	if !errdefs.IsNotImplemented(err) {
+		t.Fatalf("expected not implemented error, got: %v", err)
+	}
 }
@@ -0,0 +1,67 @@
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Clone creates a new volume with the provided name and options, and copies
+// the content of src into it. Files are cloned with reflinks where the
+// filesystem supports them, and copied otherwise.
+func (r *Root) Clone(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	srcVol, ok := src.(*localVolume)
+	if !ok {
+		return nil, errdefs.System(errors.Errorf("unknown volume type %T", src))
+	}
+	if _, err := r.Get(name); err == nil {
+		return nil, errdefs.Conflict(errors.Errorf("volume %s already exists", name))
+	}
+
+	v, err := r.Create(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := copyVolume(srcVol, v.(*localVolume)); err != nil {
+		if rmErr := r.Remove(v); rmErr != nil {
+			logrus.WithError(rmErr).WithField("volume", name).Warn("Error removing partially copied volume")
+		}
+		return nil, errdefs.System(errors.Wrapf(err, "error copying volume %s to %s", src.Name(), name))
+	}
+	return v, nil
+}
+
+// Snapshot creates a new volume with the provided name and options holding
+// a copy of src. The local driver has no native snapshots: the copy only
+// reflects a single point in time if src is not written to while it is
+// copied, so volumes which are mounted can't be snapshotted.
+func (r *Root) Snapshot(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	srcVol, ok := src.(*localVolume)
+	if !ok {
+		return nil, errdefs.System(errors.Errorf("unknown volume type %T", src))
+	}
+	if srcVol.inUse() {
+		return nil, errdefs.Conflict(errors.Errorf("volume %s is in use: the local driver can only snapshot volumes which are not mounted", src.Name()))
+	}
+	return r.Clone(src, name, opts)
+}
+
+// copyVolume mounts src and dst if they have mount options, and copies the
+// content of src to dst.
+func copyVolume(src, dst *localVolume) error {
+	id := "copy-" + dst.name
+	srcPath, err := src.Mount(id)
+	if err != nil {
+		return err
+	}
+	defer src.Unmount(id)
+
+	dstPath, err := dst.Mount(id)
+	if err != nil {
+		return err
+	}
+	defer dst.Unmount(id)
+
+	return copyDir(srcPath, dstPath)
+}
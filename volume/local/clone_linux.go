@@ -0,0 +1,10 @@
+package local // import "github.com/docker/docker/volume/local"
+
+import "github.com/docker/docker/daemon/graphdriver/copy"
+
+// copyDir copies the content of src to dst, preserving ownership,
+// permissions, timestamps and extended attributes. Files are cloned with
+// reflinks when both directories are on a filesystem supporting them.
+func copyDir(src, dst string) error {
+	return copy.DirCopy(src, dst, copy.Content, true)
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package local // import "github.com/docker/docker/volume/local"
+
+import "github.com/docker/docker/pkg/archive"
+
+// copyDir copies the content of src to dst.
+func copyDir(src, dst string) error {
+	return archive.NewDefaultArchiver().CopyWithTar(src, dst)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/volume"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // VolumeDataPathName is the name of the directory where the volume data is stored.
@@ -41,6 +42,9 @@ var (
 type activeMount struct {
 	count   uint64
 	mounted bool
+	// users counts the mounts of the volume, including those of the
+	// volumes which don't need to be mounted.
+	users uint64
 }
 
 // New instantiates a new Root instance with the provided scope. Scope
@@ -59,6 +63,7 @@ func New(scope string, rootIDs idtools.IDPair) (*Root, error) {
 		volumes: make(map[string]*localVolume),
 		rootIDs: rootIDs,
 	}
+	setupQuota(r)
 
 	dirs, err := ioutil.ReadDir(rootDirectory)
 	if err != nil {
@@ -72,9 +77,10 @@ func New(scope string, rootIDs idtools.IDPair) (*Root, error) {
 
 		name := filepath.Base(d.Name())
 		v := &localVolume{
-			driverName: r.Name(),
-			name:       name,
-			path:       r.DataPath(name),
+			driverName:  r.Name(),
+			name:        name,
+			path:        r.DataPath(name),
+			volumeQuota: r.volumeQuota,
 		}
 		r.volumes[name] = v
 		optsFilePath := filepath.Join(rootDirectory, name, "opts.json")
@@ -90,7 +96,9 @@ func New(scope string, rootIDs idtools.IDPair) (*Root, error) {
 			}
 
 			// unmount anything that may still be mounted (for example, from an unclean shutdown)
-			mount.Unmount(v.path)
+			if v.needsMount() {
+				mount.Unmount(v.path)
+			}
 		}
 	}
 
@@ -106,6 +114,7 @@ type Root struct {
 	path    string
 	volumes map[string]*localVolume
 	rootIDs idtools.IDPair
+	volumeQuota
 }
 
 // List lists all the volumes
@@ -146,7 +155,19 @@ func (r *Root) Create(name string, opts map[string]string) (volume.Volume, error
 	}
 
 	path := r.DataPath(name)
-	if err := idtools.MkdirAllAndChown(path, 0755, r.rootIDs); err != nil {
+	v = &localVolume{
+		driverName:  r.Name(),
+		name:        name,
+		path:        path,
+		volumeQuota: r.volumeQuota,
+	}
+	if err := setOpts(v, opts); err != nil {
+		return nil, err
+	}
+
+	// The quota must be set on the volume directory before the data path
+	// is created, so that the data path inherits its project ID.
+	if err := idtools.MkdirAllAndChown(filepath.Dir(path), 0755, r.rootIDs); err != nil {
 		return nil, errors.Wrapf(errdefs.System(err), "error while creating volume path '%s'", path)
 	}
 
@@ -157,30 +178,58 @@ func (r *Root) Create(name string, opts map[string]string) (volume.Volume, error
 		}
 	}()
 
-	v = &localVolume{
-		driverName: r.Name(),
-		name:       name,
-		path:       path,
-	}
-
-	if len(opts) != 0 {
-		if err = setOpts(v, opts); err != nil {
+	if size := v.quotaSize(); size > 0 {
+		if err = r.setQuota(filepath.Dir(path), size); err != nil {
 			return nil, err
 		}
-		var b []byte
-		b, err = json.Marshal(v.opts)
-		if err != nil {
-			return nil, err
-		}
-		if err = ioutil.WriteFile(filepath.Join(filepath.Dir(path), "opts.json"), b, 600); err != nil {
-			return nil, errdefs.System(errors.Wrap(err, "error while persisting volume options"))
-		}
+	}
+
+	if err = idtools.MkdirAllAndChown(path, 0755, r.rootIDs); err != nil {
+		return nil, errors.Wrapf(errdefs.System(err), "error while creating volume path '%s'", path)
+	}
+	if err = v.setOwnership(); err != nil {
+		return nil, errdefs.System(err)
+	}
+
+	if err = v.saveOpts(); err != nil {
+		return nil, err
 	}
 
 	r.volumes[name] = v
 	return v, nil
 }
 
+// Update changes options of an existing volume. Only the size of volumes
+// created with the size option can be changed.
+func (r *Root) Update(v volume.Volume, opts map[string]string) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	lv, exists := r.volumes[v.Name()]
+	if !exists {
+		return ErrNotFound
+	}
+	if err := lv.update(opts); err != nil {
+		return err
+	}
+	return lv.saveOpts()
+}
+
+// saveOpts persists the options of the volume, if any.
+func (v *localVolume) saveOpts() error {
+	if v.opts == nil {
+		return nil
+	}
+	b, err := json.Marshal(v.opts)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(filepath.Dir(v.path), "opts.json"), b, 600); err != nil {
+		return errdefs.System(errors.Wrap(err, "error while persisting volume options"))
+	}
+	return nil
+}
+
 // Remove removes the specified volume and all underlying data. If the
 // given volume does not belong to this driver and an error is
 // returned. The volume is reference counted, if all references are
@@ -218,6 +267,10 @@ func (r *Root) Remove(v volume.Volume) error {
 		return err
 	}
 
+	if err := r.clearQuota(filepath.Dir(lv.path)); err != nil {
+		logrus.WithError(err).WithField("volume", lv.name).Warn("failed to clear volume quota")
+	}
+
 	delete(r.volumes, lv.name)
 	return removePath(filepath.Dir(lv.path))
 }
@@ -280,6 +333,7 @@ type localVolume struct {
 	opts *optsConfig
 	// active refcounts the active mounts
 	active activeMount
+	volumeQuota
 }
 
 // Name returns the name of the given Volume.
@@ -307,7 +361,8 @@ func (v *localVolume) CachedPath() string {
 func (v *localVolume) Mount(id string) (string, error) {
 	v.m.Lock()
 	defer v.m.Unlock()
-	if v.opts != nil {
+	v.active.users++
+	if v.needsMount() {
 		if !v.active.mounted {
 			if err := v.mount(); err != nil {
 				return "", errdefs.System(err)
@@ -329,9 +384,12 @@ func (v *localVolume) Unmount(id string) error {
 	// Essentially docker doesn't care if this fails, it will send an error, but
 	// ultimately there's nothing that can be done. If we don't decrement the count
 	// this volume can never be removed until a daemon restart occurs.
-	if v.opts != nil {
+	if v.needsMount() {
 		v.active.count--
 	}
+	if v.active.users > 0 {
+		v.active.users--
+	}
 
 	if v.active.count > 0 {
 		return nil
@@ -340,8 +398,24 @@ func (v *localVolume) Unmount(id string) error {
 	return v.unmount()
 }
 
+// LiveRestoreVolume records the mount id of a container which is still
+// running after a restart of the daemon.
+func (v *localVolume) LiveRestoreVolume(id string) error {
+	v.m.Lock()
+	v.active.users++
+	v.m.Unlock()
+	return nil
+}
+
+// inUse returns whether the volume is mounted.
+func (v *localVolume) inUse() bool {
+	v.m.Lock()
+	defer v.m.Unlock()
+	return v.active.users > 0
+}
+
 func (v *localVolume) unmount() error {
-	if v.opts != nil {
+	if v.needsMount() {
 		if err := mount.Unmount(v.path); err != nil {
 			if mounted, mErr := mount.Mounted(v.path); mounted || mErr != nil {
 				return errdefs.System(errors.Wrapf(err, "error while unmounting volume path '%s'", v.path))
@@ -361,8 +435,18 @@ func validateOpts(opts map[string]string) error {
 	return nil
 }
 
+// Status returns the size limit and disk usage of volumes created with the
+// size option, in bytes.
 func (v *localVolume) Status() map[string]interface{} {
-	return nil
+	if v.quotaSize() == 0 {
+		return nil
+	}
+	status, err := v.quotaStatus(filepath.Dir(v.path))
+	if err != nil {
+		logrus.WithError(err).WithField("volume", v.name).Warn("failed to get volume quota")
+		return nil
+	}
+	return status
 }
 
 // getAddress finds out address/hostname from options
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/gotestyourself/gotestyourself/skip"
@@ -276,6 +277,168 @@ func TestCreateWithOpts(t *testing.T) {
 	}
 }
 
+func TestCreateWithSize(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows")
+	rootDir, err := ioutil.TempDir("", "local-volume-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	r, err := New(rootDir, idtools.IDPair{UID: os.Getuid(), GID: os.Getegid()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []map[string]string{
+		{"size": "invalid"},
+		{"size": "0"},
+		{"size": "10m", "device": "tmpfs", "type": "tmpfs"},
+	} {
+		if _, err := r.Create("test", opts); !errdefs.IsInvalidParameter(err) {
+			t.Fatalf("expected invalid parameter error for %v, got: %v", opts, err)
+		}
+	}
+
+	plain, err := r.Create("plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(plain, map[string]string{"size": "10m"}); !errdefs.IsInvalidParameter(err) {
+		t.Fatalf("expected invalid parameter error when changing the size of a volume without size, got: %v", err)
+	}
+
+	vol, err := r.Create("test", map[string]string{"size": "10m"})
+	if r.quotaCtl == nil {
+		if !errdefs.IsNotImplemented(err) {
+			t.Fatalf("expected not implemented error without quota support, got: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(rootDir, "volumes", "test")); !os.IsNotExist(err) {
+			t.Fatalf("expected volume directory to be removed, got: %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := vol.Status()
+	if status["QuotaLimit"] != uint64(10*1024*1024) {
+		t.Fatalf("expected quota limit of 10m, got: %v", status)
+	}
+	if _, ok := status["QuotaUsage"]; !ok {
+		t.Fatalf("expected quota usage in status, got: %v", status)
+	}
+
+	for _, opts := range []map[string]string{
+		{"size": "invalid"},
+		{"type": "tmpfs"},
+	} {
+		if err := r.Update(vol, opts); !errdefs.IsInvalidParameter(err) {
+			t.Fatalf("expected invalid parameter error for %v, got: %v", opts, err)
+		}
+	}
+	if err := r.Update(vol, map[string]string{"size": "20m"}); err != nil {
+		t.Fatal(err)
+	}
+	if status := vol.Status(); status["QuotaLimit"] != uint64(20*1024*1024) {
+		t.Fatalf("expected quota limit of 20m, got: %v", status)
+	}
+}
+
+func TestClone(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "local-volume-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	r, err := New(rootDir, idtools.IDPair{UID: os.Getuid(), GID: os.Getegid()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := r.Create("source", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(src.Path(), "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src.Path(), "dir", "file"), []byte("content"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := r.Clone(src, "clone", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(clone.Path(), "dir", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "content" {
+		t.Fatalf("expected cloned file content, got %q", b)
+	}
+
+	// the clone is independent of the source volume
+	if err := ioutil.WriteFile(filepath.Join(src.Path(), "dir", "file"), []byte("changed"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	b, err = ioutil.ReadFile(filepath.Join(clone.Path(), "dir", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "content" {
+		t.Fatalf("expected clone to be unchanged, got %q", b)
+	}
+
+	if _, err := r.Snapshot(src, "clone", nil); !errdefs.IsConflict(err) {
+		t.Fatalf("expected conflict error for existing volume, got: %v", err)
+	}
+}
+
+func TestSnapshotInUse(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "local-volume-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	r, err := New(rootDir, idtools.IDPair{UID: os.Getuid(), GID: os.Getegid()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := r.Create("source", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Mount("container"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Snapshot(src, "snap", nil); !errdefs.IsConflict(err) {
+		t.Fatalf("expected conflict error for mounted volume, got: %v", err)
+	}
+	if _, err := r.Get("snap"); err == nil {
+		t.Fatal("expected no snapshot of mounted volume")
+	}
+
+	if err := src.Unmount("container"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Snapshot(src, "snap", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// containers still running after a restart of the daemon use the volume
+	if err := src.(*localVolume).LiveRestoreVolume("container"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Snapshot(src, "other", nil); !errdefs.IsConflict(err) {
+		t.Fatalf("expected conflict error for live-restored volume, got: %v", err)
+	}
+}
+
 func TestRelaodNoOpts(t *testing.T) {
 	rootDir, err := ioutil.TempDir("", "volume-test-reload-no-opts")
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/docker/docker/pkg/mount"
+	"github.com/docker/go-units"
 )
 
 var (
@@ -26,6 +28,10 @@ var (
 		"type":   true, // specify the filesystem type for mount, e.g. nfs
 		"o":      true, // generic mount options
 		"device": true, // device to mount from
+		"size":   true, // quota on the size of the volume, e.g. 10G
+		"uid":    true, // owner of the volume directory
+		"gid":    true, // group of the volume directory
+		"mode":   true, // permissions of the volume directory, in octal
 	}
 )
 
@@ -33,10 +39,14 @@ type optsConfig struct {
 	MountType   string
 	MountOpts   string
 	MountDevice string
+	Size        uint64  `json:",omitempty"`
+	UID         *int    `json:",omitempty"`
+	GID         *int    `json:",omitempty"`
+	Mode        *uint32 `json:",omitempty"`
 }
 
 func (o *optsConfig) String() string {
-	return fmt.Sprintf("type='%s' device='%s' o='%s'", o.MountType, o.MountDevice, o.MountOpts)
+	return fmt.Sprintf("type='%s' device='%s' o='%s' size='%d'", o.MountType, o.MountDevice, o.MountOpts, o.Size)
 }
 
 // scopedPath verifies that the path where the volume is located
@@ -68,9 +78,114 @@ func setOpts(v *localVolume, opts map[string]string) error {
 		MountOpts:   opts["o"],
 		MountDevice: opts["device"],
 	}
+	if size, ok := opts["size"]; ok {
+		if v.needsMount() {
+			return validationError("size option cannot be combined with mount options")
+		}
+		n, err := units.RAMInBytes(size)
+		if err != nil || n <= 0 {
+			return validationError(fmt.Sprintf("invalid size: %q", size))
+		}
+		v.opts.Size = uint64(n)
+	}
+	for _, opt := range []string{"uid", "gid", "mode"} {
+		if _, ok := opts[opt]; ok && v.needsMount() {
+			return validationError(fmt.Sprintf("%s option cannot be combined with mount options", opt))
+		}
+	}
+	if uid, ok := opts["uid"]; ok {
+		n, err := strconv.Atoi(uid)
+		if err != nil || n < 0 {
+			return validationError(fmt.Sprintf("invalid uid: %q", uid))
+		}
+		v.opts.UID = &n
+	}
+	if gid, ok := opts["gid"]; ok {
+		n, err := strconv.Atoi(gid)
+		if err != nil || n < 0 {
+			return validationError(fmt.Sprintf("invalid gid: %q", gid))
+		}
+		v.opts.GID = &n
+	}
+	if mode, ok := opts["mode"]; ok {
+		n, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || n > 07777 {
+			return validationError(fmt.Sprintf("invalid mode: %q", mode))
+		}
+		m := uint32(n)
+		v.opts.Mode = &m
+	}
 	return nil
 }
 
+// update changes the size limit of a volume created with the size option,
+// which is the only option of local volumes that can be changed.
+func (v *localVolume) update(opts map[string]string) error {
+	for opt := range opts {
+		if opt != "size" {
+			return validationError(fmt.Sprintf("option %q of local volumes cannot be changed", opt))
+		}
+	}
+	if v.quotaSize() == 0 {
+		return validationError("size can only be changed for volumes created with the size option")
+	}
+	size := opts["size"]
+	n, err := units.RAMInBytes(size)
+	if err != nil || n <= 0 {
+		return validationError(fmt.Sprintf("invalid size: %q", size))
+	}
+	if err := v.setQuota(filepath.Dir(v.path), uint64(n)); err != nil {
+		return err
+	}
+	v.opts.Size = uint64(n)
+	return nil
+}
+
+// setOwnership applies the uid, gid and mode options to the data path of
+// the volume. The IDs are host IDs: they are not remapped when user
+// namespaces are enabled.
+func (v *localVolume) setOwnership() error {
+	if v.opts == nil {
+		return nil
+	}
+	if v.opts.UID != nil || v.opts.GID != nil {
+		uid, gid := -1, -1
+		if v.opts.UID != nil {
+			uid = *v.opts.UID
+		}
+		if v.opts.GID != nil {
+			gid = *v.opts.GID
+		}
+		if err := os.Lchown(v.path, uid, gid); err != nil {
+			return errors.Wrapf(err, "error setting ownership of volume %s", v.name)
+		}
+	}
+	if v.opts.Mode != nil {
+		if err := syscall.Chmod(v.path, *v.opts.Mode); err != nil {
+			return errors.Wrapf(err, "error setting mode of volume %s", v.name)
+		}
+	}
+	return nil
+}
+
+// needsMount returns true if the volume has options to mount a filesystem
+// on its data path.
+func (v *localVolume) needsMount() bool {
+	if v.opts == nil {
+		return false
+	}
+	return v.opts.MountType != "" || v.opts.MountOpts != "" || v.opts.MountDevice != ""
+}
+
+// quotaSize returns the size limit of the volume in bytes, or 0 if the size
+// of the volume is not limited.
+func (v *localVolume) quotaSize() uint64 {
+	if v.opts == nil {
+		return 0
+	}
+	return v.opts.Size
+}
+
 func (v *localVolume) mount() error {
 	if v.opts.MountDevice == "" {
 		return fmt.Errorf("missing device in volume options")
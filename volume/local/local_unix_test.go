@@ -0,0 +1,70 @@
+// +build linux freebsd
+
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/gotestyourself/gotestyourself/skip"
+)
+
+func TestCreateWithOwnership(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+	rootDir, err := ioutil.TempDir("", "local-volume-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	r, err := New(rootDir, idtools.IDPair{UID: 0, GID: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []map[string]string{
+		{"uid": "invalid"},
+		{"uid": "-1"},
+		{"gid": "invalid"},
+		{"mode": "999"},
+		{"mode": "17777"},
+		{"uid": "1000", "device": "tmpfs", "type": "tmpfs"},
+	} {
+		if _, err := r.Create("test", opts); !errdefs.IsInvalidParameter(err) {
+			t.Fatalf("expected invalid parameter error for %v, got: %v", opts, err)
+		}
+	}
+
+	vol, err := r.Create("test", map[string]string{"uid": "1000", "gid": "1001", "mode": "2770"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(vol.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+	if st.Uid != 1000 || st.Gid != 1001 {
+		t.Fatalf("expected volume to be owned by 1000:1001, got %d:%d", st.Uid, st.Gid)
+	}
+	if mode := st.Mode & 07777; mode != 02770 {
+		t.Fatalf("expected volume mode 2770, got %o", mode)
+	}
+
+	// options are restored
+	r, err = New(rootDir, idtools.IDPair{UID: 0, GID: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.Get("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := v.(*localVolume).opts; opts == nil || opts.UID == nil || *opts.UID != 1000 || opts.Mode == nil || *opts.Mode != 02770 {
+		t.Fatalf("expected volume options to be restored, got %+v", opts)
+	}
+}
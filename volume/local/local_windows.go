@@ -32,6 +32,25 @@ func setOpts(v *localVolume, opts map[string]string) error {
 	return nil
 }
 
+func (v *localVolume) needsMount() bool {
+	return false
+}
+
+func (v *localVolume) quotaSize() uint64 {
+	return 0
+}
+
+func (v *localVolume) update(opts map[string]string) error {
+	if len(opts) > 0 {
+		return fmt.Errorf("options are not supported on this platform")
+	}
+	return nil
+}
+
+func (v *localVolume) setOwnership() error {
+	return nil
+}
+
 func (v *localVolume) mount() error {
 	return nil
 }
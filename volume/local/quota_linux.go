@@ -0,0 +1,68 @@
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"sync"
+
+	"github.com/docker/docker/daemon/graphdriver/quota"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// volumeQuota is shared by the driver and its volumes. quota.Control is not
+// safe for concurrent use, so calls are serialized by m.
+type volumeQuota struct {
+	m        *sync.Mutex
+	quotaCtl *quota.Control
+}
+
+func setupQuota(r *Root) {
+	r.volumeQuota.m = &sync.Mutex{}
+	if quotaCtl, err := quota.NewControl(r.path); err == nil {
+		r.volumeQuota.quotaCtl = quotaCtl
+	} else if err != quota.ErrQuotaNotSupported {
+		logrus.Warnf("Unable to setup quota for local volumes: %v", err)
+	}
+}
+
+// setQuota limits the size of dir, which must be a direct child of the
+// volumes root.
+func (q volumeQuota) setQuota(dir string, size uint64) error {
+	if q.quotaCtl == nil {
+		return errdefs.NotImplemented(errors.New("size option is not supported: the filesystem holding the local volumes does not support project quotas, or they are not enabled"))
+	}
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.quotaCtl.SetQuota(dir, quota.Quota{Size: size})
+}
+
+// clearQuota removes the size limit of dir, if it has one.
+func (q volumeQuota) clearQuota(dir string) error {
+	if q.quotaCtl == nil {
+		return nil
+	}
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.quotaCtl.ClearQuota(dir)
+}
+
+// quotaStatus returns the size limit and usage of dir, in bytes.
+func (q volumeQuota) quotaStatus(dir string) (map[string]interface{}, error) {
+	if q.quotaCtl == nil {
+		return nil, quota.ErrQuotaNotSupported
+	}
+	q.m.Lock()
+	defer q.m.Unlock()
+	var limit quota.Quota
+	if err := q.quotaCtl.GetQuota(dir, &limit); err != nil {
+		return nil, err
+	}
+	usage, err := q.quotaCtl.GetUsage(dir)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"QuotaLimit": limit.Size,
+		"QuotaUsage": usage,
+	}, nil
+}
@@ -0,0 +1,27 @@
+// +build !linux
+
+package local // import "github.com/docker/docker/volume/local"
+
+import (
+	"github.com/docker/docker/daemon/graphdriver/quota"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+)
+
+type volumeQuota struct {
+}
+
+func setupQuota(r *Root) {
+}
+
+func (q volumeQuota) setQuota(dir string, size uint64) error {
+	return errdefs.NotImplemented(errors.New("size option is not supported on this platform"))
+}
+
+func (q volumeQuota) clearQuota(dir string) error {
+	return nil
+}
+
+func (q volumeQuota) quotaStatus(dir string) (map[string]interface{}, error) {
+	return nil, quota.ErrQuotaNotSupported
+}
@@ -41,6 +41,19 @@ func linuxValidateAbsolute(p string) error {
 	}
 	return fmt.Errorf("invalid mount path: '%s' mount path must be absolute", p)
 }
+
+// linuxValidateSubpath checks that a volume subpath is relative and stays
+// within the volume. Symlinks are resolved when the volume is mounted.
+func linuxValidateSubpath(p string) error {
+	if path.IsAbs(p) {
+		return fmt.Errorf("invalid volume subpath: '%s' subpath must be relative to the volume root", p)
+	}
+	if cleaned := path.Clean(p); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("invalid volume subpath: '%s' subpath must not refer to a path outside of the volume", p)
+	}
+	return nil
+}
+
 func (p *linuxParser) ValidateMountConfig(mnt *mount.Mount) error {
 	// there was something looking like a bug in existing codebase:
 	// - validateMountConfig on linux was called with options skipping bind source existence when calling ParseMountRaw
@@ -96,6 +109,12 @@ func (p *linuxParser) validateMountConfigImpl(mnt *mount.Mount, validateBindSour
 		if len(mnt.Source) == 0 && mnt.ReadOnly {
 			return &errMountConfig{mnt, fmt.Errorf("must not set ReadOnly mode when using anonymous volumes")}
 		}
+
+		if mnt.VolumeOptions != nil && len(mnt.VolumeOptions.Subpath) > 0 {
+			if err := linuxValidateSubpath(mnt.VolumeOptions.Subpath); err != nil {
+				return &errMountConfig{mnt, err}
+			}
+		}
 	case mount.TypeTmpfs:
 		if len(mnt.Source) != 0 {
 			return &errMountConfig{mnt, errExtraField("Source")}
@@ -302,7 +321,8 @@ func (p *linuxParser) parseMountSpec(cfg mount.Mount, validateBindSourceExists b
 			if cfg.VolumeOptions.DriverConfig != nil {
 				mp.Driver = cfg.VolumeOptions.DriverConfig.Name
 			}
-			if cfg.VolumeOptions.NoCopy {
+			// Image content is only copied to the root of the volume.
+			if cfg.VolumeOptions.NoCopy || len(cfg.VolumeOptions.Subpath) > 0 {
 				mp.CopyData = false
 			}
 		}
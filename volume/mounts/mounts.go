@@ -9,9 +9,11 @@ import (
 	mounttypes "github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/volume"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // MountPoint is the intersection point between a volume and a container. It
@@ -66,6 +68,26 @@ type MountPoint struct {
 	// Specifically needed for containers which are running and calls to `docker cp`
 	// because both these actions require mounting the volumes.
 	active int
+
+	// subpathMount is the private bind mount of the volume subpath, which
+	// is mounted into the container instead of the subpath itself.
+	subpathMount string
+}
+
+// LiveRestore records the mount of the volume by a container which is still
+// running after a restart of the daemon, so that the volume stays mounted
+// until the container stops.
+func (m *MountPoint) LiveRestore() error {
+	if m.Volume == nil || m.ID == "" {
+		return nil
+	}
+	if lr, ok := m.Volume.(volume.LiveRestorer); ok {
+		if err := lr.LiveRestoreVolume(m.ID); err != nil {
+			return errors.Wrapf(err, "error restoring mount of volume %s", m.Volume.Name())
+		}
+	}
+	m.active++
+	return nil
 }
 
 // Cleanup frees resources used by the mountpoint
@@ -74,6 +96,13 @@ func (m *MountPoint) Cleanup() error {
 		return nil
 	}
 
+	if m.active <= 1 && m.subpathMount != "" {
+		if err := unmountSubpath(m.subpathMount); err != nil {
+			return errors.Wrapf(err, "error unmounting subpath of volume %s", m.Volume.Name())
+		}
+		m.subpathMount = ""
+	}
+
 	if err := m.Volume.Unmount(m.ID); err != nil {
 		return errors.Wrapf(err, "error unmounting volume %s", m.Volume.Name())
 	}
@@ -117,11 +146,28 @@ func (m *MountPoint) Setup(mountLabel string, rootIDs idtools.IDPair, checkFun f
 		if id == "" {
 			id = stringid.GenerateNonCryptoID()
 		}
-		path, err := m.Volume.Mount(id)
+		if rom, ok := m.Volume.(volume.ReadOnlyMounter); ok && !m.RW {
+			path, err = rom.MountReadOnly(id)
+		} else {
+			path, err = m.Volume.Mount(id)
+		}
 		if err != nil {
 			return "", errors.Wrapf(err, "error while mounting volume '%s'", m.Source)
 		}
 
+		if opts := m.Spec.VolumeOptions; opts != nil && len(opts.Subpath) > 0 {
+			if m.subpathMount == "" {
+				m.subpathMount, err = mountSubpath(path, opts.Subpath, rootIDs)
+				if err != nil {
+					if err := m.Volume.Unmount(id); err != nil {
+						logrus.WithError(err).WithField("volume", m.Volume.Name()).Warn("error unmounting volume")
+					}
+					return "", errors.Wrapf(err, "error while mounting volume '%s'", m.Source)
+				}
+			}
+			path = m.subpathMount
+		}
+
 		m.ID = id
 		m.active++
 		return path, nil
@@ -153,6 +199,28 @@ func (m *MountPoint) Setup(mountLabel string, rootIDs idtools.IDPair, checkFun f
 	return m.Source, nil
 }
 
+// resolveSubpath returns the path of subpath in the volume mounted at root.
+// Symlinks are resolved within the volume, so that the resulting path can't
+// be outside of it. The directory is created if it doesn't exist.
+//
+// The path must not be mounted as is, as a container sharing the volume can
+// replace one of its components with a symlink once it is resolved; see
+// mountSubpath.
+func resolveSubpath(root, subpath string, rootIDs idtools.IDPair) (string, error) {
+	path, err := symlink.FollowSymlinkInScope(filepath.Join(root, subpath), root)
+	if err != nil {
+		return "", errors.Wrapf(err, "error resolving volume subpath '%s'", subpath)
+	}
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		if err := idtools.MkdirAllAndChownNew(path, 0755, rootIDs); err != nil {
+			return "", errors.Wrapf(err, "error creating volume subpath '%s'", subpath)
+		}
+	} else if err != nil {
+		return "", errors.Wrapf(err, "error accessing volume subpath '%s'", subpath)
+	}
+	return path, nil
+}
+
 // Path returns the path of a volume in a mount point.
 func (m *MountPoint) Path() string {
 	if m.Volume != nil {
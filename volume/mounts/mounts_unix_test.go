@@ -0,0 +1,98 @@
+// +build !windows
+
+package mounts // import "github.com/docker/docker/volume/mounts"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/volume"
+	volumetestutils "github.com/docker/docker/volume/testutils"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestValidateSubpath(t *testing.T) {
+	parser := &linuxParser{}
+	for _, subpath := range []string{"app", "app/data", "./app", "app/../other"} {
+		mnt := mount.Mount{Type: mount.TypeVolume, Source: "data", Target: "/data", VolumeOptions: &mount.VolumeOptions{Subpath: subpath}}
+		assert.Check(t, parser.ValidateMountConfig(&mnt), subpath)
+	}
+	for _, subpath := range []string{"/app", "..", "../app", "app/../../other"} {
+		mnt := mount.Mount{Type: mount.TypeVolume, Source: "data", Target: "/data", VolumeOptions: &mount.VolumeOptions{Subpath: subpath}}
+		assert.Check(t, is.ErrorContains(parser.ValidateMountConfig(&mnt), "invalid volume subpath"), subpath)
+	}
+
+	mp, err := parser.ParseMountSpec(mount.Mount{Type: mount.TypeVolume, Source: "data", Target: "/data", VolumeOptions: &mount.VolumeOptions{Subpath: "app"}})
+	assert.NilError(t, err)
+	assert.Check(t, !mp.CopyData, "image content must not be copied to a volume subpath")
+}
+
+func TestResolveSubpath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-volume-subpath")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "volume")
+	outside := filepath.Join(dir, "outside")
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "app"), 0755))
+	assert.NilError(t, os.MkdirAll(outside, 0755))
+	assert.NilError(t, os.Symlink("/", filepath.Join(root, "abs")))
+	assert.NilError(t, os.Symlink("../outside", filepath.Join(root, "rel")))
+
+	ids := idtools.IDPair{UID: os.Getuid(), GID: os.Getgid()}
+
+	path, err := resolveSubpath(root, "app", ids)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(filepath.Join(root, "app"), path))
+
+	// missing directories are created
+	path, err = resolveSubpath(root, "new/dir", ids)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(filepath.Join(root, "new", "dir"), path))
+	fi, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Check(t, fi.IsDir())
+
+	// symlinks are resolved within the volume
+	path, err = resolveSubpath(root, "abs", ids)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(root, path))
+
+	path, err = resolveSubpath(root, "rel/app", ids)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(filepath.Join(root, "outside", "app"), path))
+	_, err = os.Stat(filepath.Join(outside, "app"))
+	assert.Check(t, os.IsNotExist(err), "no directory must be created outside of the volume")
+}
+
+// readOnlyVolume is a volume recording whether it was mounted read-only.
+type readOnlyVolume struct {
+	volume.Volume
+	readOnly map[string]bool
+}
+
+func (v readOnlyVolume) Mount(id string) (string, error) {
+	v.readOnly[id] = false
+	return v.Volume.Mount(id)
+}
+
+func (v readOnlyVolume) MountReadOnly(id string) (string, error) {
+	v.readOnly[id] = true
+	return v.Volume.Mount(id)
+}
+
+func TestSetupReadOnlyVolume(t *testing.T) {
+	v := readOnlyVolume{Volume: volumetestutils.NewFakeVolume("data", "fake"), readOnly: make(map[string]bool)}
+	ro := &MountPoint{Volume: v, ID: "ro", RW: false}
+	rw := &MountPoint{Volume: v, ID: "rw", RW: true}
+	for _, m := range []*MountPoint{ro, rw} {
+		_, err := m.Setup("", idtools.IDPair{}, nil)
+		assert.NilError(t, err)
+	}
+	assert.Check(t, is.DeepEqual(map[string]bool{"ro": true, "rw": false}, v.readOnly))
+}
@@ -0,0 +1,111 @@
+package mounts // import "github.com/docker/docker/volume/mounts"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// mountSubpath bind mounts the subpath of the volume mounted at root to a
+// private path in the daemon's temporary directory, and returns that path.
+//
+// The subpath is resolved within the volume, then opened one component at a
+// time without following symlinks, and the bind mount is made from the
+// opened file descriptor. Swapping a component of the subpath for a symlink
+// after it is resolved therefore makes the mount fail rather than mount a
+// path outside of the volume, and the mount keeps referring to the subpath
+// it was made from until it is unmounted.
+func mountSubpath(root, subpath string, rootIDs idtools.IDPair) (string, error) {
+	resolved, err := resolveSubpath(root, subpath, rootIDs)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return "", errors.Wrapf(err, "error resolving volume subpath '%s'", subpath)
+	}
+	fd, err := openInRoot(root, rel)
+	if err != nil {
+		return "", errors.Wrapf(err, "error opening volume subpath '%s'", subpath)
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return "", errors.Wrapf(err, "error accessing volume subpath '%s'", subpath)
+	}
+	var target string
+	if st.Mode&unix.S_IFMT == unix.S_IFDIR {
+		target, err = ioutil.TempDir("", "volume-subpath-")
+	} else {
+		var f *os.File
+		if f, err = ioutil.TempFile("", "volume-subpath-"); err == nil {
+			target = f.Name()
+			f.Close()
+		}
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "error creating volume subpath mount point")
+	}
+
+	if err := unix.Mount(fmt.Sprintf("/proc/self/fd/%d", fd), target, "", unix.MS_BIND, ""); err != nil {
+		os.Remove(target)
+		return "", errors.Wrapf(err, "error mounting volume subpath '%s'", subpath)
+	}
+	return target, nil
+}
+
+// openInRoot opens the path rel below root with O_PATH, one component at a
+// time, failing if any of the components is a symlink.
+func openInRoot(root, rel string) (int, error) {
+	fd, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, &os.PathError{Op: "open", Path: root, Err: err}
+	}
+	if rel == "." {
+		return fd, nil
+	}
+
+	components := strings.Split(rel, string(filepath.Separator))
+	for i, c := range components {
+		flags := unix.O_PATH | unix.O_NOFOLLOW | unix.O_CLOEXEC
+		if i < len(components)-1 {
+			flags |= unix.O_DIRECTORY
+		}
+		next, err := unix.Openat(fd, c, flags, 0)
+		unix.Close(fd)
+		if err != nil {
+			return -1, &os.PathError{Op: "openat", Path: filepath.Join(root, filepath.Join(components[:i+1]...)), Err: err}
+		}
+		fd = next
+	}
+
+	// O_NOFOLLOW opens the last component itself if it is a symlink
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	if st.Mode&unix.S_IFMT == unix.S_IFLNK {
+		unix.Close(fd)
+		return -1, &os.PathError{Op: "openat", Path: filepath.Join(root, rel), Err: unix.ELOOP}
+	}
+	return fd, nil
+}
+
+// unmountSubpath unmounts and removes a mount point created by mountSubpath.
+func unmountSubpath(target string) error {
+	if err := unix.Unmount(target, unix.MNT_DETACH); err != nil && err != unix.EINVAL && err != unix.ENOENT {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package mounts // import "github.com/docker/docker/volume/mounts"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+	"github.com/gotestyourself/gotestyourself/skip"
+	"golang.org/x/sys/unix"
+)
+
+// TestOpenInRootSymlinkSwap swaps a component of a resolved subpath for a
+// symlink pointing outside of the volume, as a container sharing the volume
+// could, and checks that the subpath can't be opened anymore.
+func TestOpenInRootSymlinkSwap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-volume-subpath")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "volume")
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "app", "data"), 0755))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "outside", "data"), 0755))
+
+	ids := idtools.IDPair{UID: os.Getuid(), GID: os.Getgid()}
+	path, err := resolveSubpath(root, "app/data", ids)
+	assert.NilError(t, err)
+	rel, err := filepath.Rel(root, path)
+	assert.NilError(t, err)
+
+	fd, err := openInRoot(root, rel)
+	assert.NilError(t, err)
+	unix.Close(fd)
+
+	assert.NilError(t, os.RemoveAll(filepath.Join(root, "app")))
+	assert.NilError(t, os.Symlink(filepath.Join(dir, "outside"), filepath.Join(root, "app")))
+	_, err = openInRoot(root, rel)
+	assert.Check(t, is.ErrorContains(err, "not a directory"))
+
+	// the last component is not followed either
+	assert.NilError(t, os.Remove(filepath.Join(root, "app")))
+	assert.NilError(t, os.Mkdir(filepath.Join(root, "app"), 0755))
+	assert.NilError(t, os.Symlink(filepath.Join(dir, "outside", "data"), filepath.Join(root, "app", "data")))
+	_, err = openInRoot(root, rel)
+	assert.Check(t, is.ErrorContains(err, "too many levels of symbolic links"))
+}
+
+// TestMountSubpathSymlinkSwap checks that the mount of a subpath keeps
+// referring to the subpath it was made from when one of its components is
+// swapped for a symlink.
+func TestMountSubpathSymlinkSwap(t *testing.T) {
+	skip.If(t, os.Getuid() != 0, "skipping test that requires root")
+
+	dir, err := ioutil.TempDir("", "test-volume-subpath")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "volume")
+	outside := filepath.Join(dir, "outside")
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "app", "data"), 0755))
+	assert.NilError(t, os.MkdirAll(filepath.Join(outside, "data"), 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(root, "app", "data", "file"), []byte("volume"), 0644))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(outside, "data", "file"), []byte("outside"), 0644))
+
+	target, err := mountSubpath(root, "app/data", idtools.IDPair{UID: os.Getuid(), GID: os.Getgid()})
+	assert.NilError(t, err)
+	defer unmountSubpath(target)
+
+	assert.NilError(t, os.Rename(filepath.Join(root, "app"), filepath.Join(root, "moved")))
+	assert.NilError(t, os.Symlink(outside, filepath.Join(root, "app")))
+
+	b, err := ioutil.ReadFile(filepath.Join(target, "file"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("volume", string(b)))
+
+	assert.NilError(t, unmountSubpath(target))
+	_, err = os.Stat(target)
+	assert.Check(t, os.IsNotExist(err))
+}
@@ -0,0 +1,17 @@
+// +build !linux
+
+package mounts // import "github.com/docker/docker/volume/mounts"
+
+import (
+	"errors"
+
+	"github.com/docker/docker/pkg/idtools"
+)
+
+func mountSubpath(root, subpath string, rootIDs idtools.IDPair) (string, error) {
+	return "", errors.New("volume subpaths are not supported on this platform")
+}
+
+func unmountSubpath(target string) error {
+	return nil
+}
@@ -267,6 +267,13 @@ func (p *windowsParser) validateMountConfigReg(mnt *mount.Mount, destRegex strin
 			return &errMountConfig{mnt, fmt.Errorf("must not set ReadOnly mode when using anonymous volumes")}
 		}
 
+		if mnt.VolumeOptions != nil && len(mnt.VolumeOptions.Subpath) > 0 {
+			return &errMountConfig{mnt, errExtraField("VolumeOptions.Subpath")}
+		}
+		if mnt.VolumeOptions != nil && mnt.VolumeOptions.Chown {
+			return &errMountConfig{mnt, errExtraField("VolumeOptions.Chown")}
+		}
+
 		if len(mnt.Source) != 0 {
 			if err := p.ValidateVolumeName(mnt.Source); err != nil {
 				return &errMountConfig{mnt, err}
@@ -0,0 +1,163 @@
+package store // import "github.com/docker/docker/volume/store"
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	volumemounts "github.com/docker/docker/volume/mounts"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// copyFunc creates the volume name from src, using the driver vd. It returns
+// the new volume and the options it was created with.
+type copyFunc func(vd volume.Driver, src volume.Volume, name string) (volume.Volume, map[string]string, error)
+
+// Clone creates a volume with the given name, options and labels, holding a
+// copy of the content of the volume srcName. The new volume is created by the
+// driver of the source volume, which must support cloning volumes.
+func (s *VolumeStore) Clone(srcName, name string, opts, labels map[string]string) (volume.Volume, error) {
+	v, err := s.copyVolume(srcName, name, labels, nil, func(vd volume.Driver, src volume.Volume, name string) (volume.Volume, map[string]string, error) {
+		cloner, ok := vd.(volume.Cloner)
+		if !ok {
+			return nil, nil, errdefs.NotImplemented(errors.Errorf("volume driver %s does not support cloning volumes", vd.Name()))
+		}
+		v, err := cloner.Clone(unwrapVolume(src), name, opts)
+		return v, opts, err
+	})
+	if err != nil {
+		return nil, &OpErr{Err: err, Name: name, Op: "clone"}
+	}
+	return v, nil
+}
+
+// Snapshot creates a volume with the given name and labels, holding the
+// content of the volume srcName at the time of the call. The snapshot is
+// created by the driver of the source volume, which must support snapshots,
+// with the options of the source volume. The source volume and the time of
+// the snapshot are recorded in the volume metadata.
+func (s *VolumeStore) Snapshot(srcName, name string, labels map[string]string) (volume.Volume, error) {
+	info := &volume.SnapshotInfo{
+		Source:    normalizeVolumeName(srcName),
+		CreatedAt: time.Now().UTC(),
+	}
+	v, err := s.copyVolume(srcName, name, labels, info, func(vd volume.Driver, src volume.Volume, name string) (volume.Volume, map[string]string, error) {
+		snapshotter, ok := vd.(volume.Snapshotter)
+		if !ok {
+			return nil, nil, errdefs.NotImplemented(errors.Errorf("volume driver %s does not support volume snapshots", vd.Name()))
+		}
+		var opts map[string]string
+		if dv, ok := src.(volume.DetailedVolume); ok {
+			opts = dv.Options()
+		}
+		v, err := snapshotter.Snapshot(unwrapVolume(src), name, opts)
+		return v, opts, err
+	})
+	if err != nil {
+		return nil, &OpErr{Err: err, Name: name, Op: "snapshot"}
+	}
+	return v, nil
+}
+
+// SnapshotInfo returns the origin of a volume created as a snapshot of
+// another volume, or nil if the volume is not a snapshot.
+func (s *VolumeStore) SnapshotInfo(name string) *volume.SnapshotInfo {
+	meta, err := s.getMeta(normalizeVolumeName(name))
+	if err != nil {
+		logrus.WithError(err).WithField("volume", name).Debug("Error reading volume metadata")
+		return nil
+	}
+	return meta.Snapshot
+}
+
+// copyVolume creates the volume name from the volume srcName using fn, and
+// stores its metadata. A reference to the source volume is held while the
+// volume is created, so that the source volume can't be removed meanwhile.
+func (s *VolumeStore) copyVolume(srcName, name string, labels map[string]string, snapshot *volume.SnapshotInfo, fn copyFunc) (volume.Volume, error) {
+	name = normalizeVolumeName(name)
+	parser := volumemounts.NewParser(runtime.GOOS)
+	if err := parser.ValidateVolumeName(name); err != nil {
+		return nil, err
+	}
+
+	ref := "copy:" + name
+	src, err := s.acquire(srcName, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Dereference(src, ref)
+
+	s.locks.Lock(name)
+	defer s.locks.Unlock(name)
+
+	if v, err := s.checkConflict(name, ""); err != nil {
+		return nil, err
+	} else if v != nil {
+		return nil, errors.Wrapf(errNameConflict, "volume %s already exists", name)
+	}
+
+	driverName := src.DriverName()
+	vd, err := s.drivers.CreateDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+	releaseDriver := func() {
+		if _, err := s.drivers.ReleaseDriver(driverName); err != nil {
+			logrus.WithError(err).WithField("driver", driverName).Error("Error releasing reference to volume driver")
+		}
+	}
+	if v, _ := vd.Get(name); v != nil {
+		releaseDriver()
+		return nil, errors.Wrapf(errNameConflict, "driver '%s' already has volume '%s'", vd.Name(), name)
+	}
+
+	logrus.Debugf("Copying volume %q to %q with driver %q", src.Name(), name, vd.Name())
+	v, opts, err := fn(vd, src, name)
+	if err != nil {
+		releaseDriver()
+		return nil, err
+	}
+
+	metadata := volumeMetadata{
+		Name:     name,
+		Driver:   vd.Name(),
+		Labels:   labels,
+		Options:  opts,
+		Snapshot: snapshot,
+	}
+	if err := s.setMeta(name, metadata); err != nil {
+		// the volume can't be found again without its metadata
+		if rmErr := vd.Remove(v); rmErr != nil {
+			logrus.WithError(rmErr).WithField("volume", name).Warn("Error removing volume after failing to store its metadata")
+		}
+		releaseDriver()
+		return nil, err
+	}
+
+	s.globalLock.Lock()
+	s.labels[name] = labels
+	s.options[name] = opts
+	s.refs[name] = make(map[string]struct{})
+	s.globalLock.Unlock()
+
+	s.setNamed(v, "")
+	return volumeWrapper{v, labels, vd.Scope(), opts}, nil
+}
+
+// acquire looks up the volume with the given name and stores the ref, like
+// Get followed by a reference. The reference must be released with
+// Dereference.
+func (s *VolumeStore) acquire(name, ref string) (volume.Volume, error) {
+	name = normalizeVolumeName(name)
+	s.locks.Lock(name)
+	defer s.locks.Unlock(name)
+
+	v, err := s.getVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	s.setNamed(v, ref)
+	return v, nil
+}
@@ -0,0 +1,127 @@
+package store // import "github.com/docker/docker/volume/store"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	volumetestutils "github.com/docker/docker/volume/testutils"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+// driverWithoutCopy hides the optional interfaces of the wrapped driver, such
+// as volume.Cloner, volume.Snapshotter and volume.Updater.
+type driverWithoutCopy struct {
+	volume.Driver
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	s.drivers.Register(volumetestutils.NewFakeDriver("fake"), "fake")
+
+	_, err := s.Create("source", "fake", map[string]string{"size": "10G"}, nil)
+	assert.NilError(t, err)
+
+	v, err := s.Clone("source", "clone", map[string]string{"size": "20G"}, map[string]string{"a": "b"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("clone", v.Name()))
+	assert.Check(t, is.Equal("fake", v.DriverName()))
+	dv := v.(volume.DetailedVolume)
+	assert.Check(t, is.DeepEqual(map[string]string{"size": "20G"}, dv.Options()))
+	assert.Check(t, is.DeepEqual(map[string]string{"a": "b"}, dv.Labels()))
+	assert.Check(t, is.Nil(s.SnapshotInfo("clone")))
+
+	// the reference held during the copy must be released
+	assert.Check(t, is.Len(s.Refs(v), 0))
+	src, err := s.Get("source")
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(s.Refs(src), 0))
+
+	_, err = s.Clone("source", "clone", nil, nil)
+	assert.Check(t, IsNameConflict(err), err)
+
+	_, err = s.Clone("missing", "other", nil, nil)
+	assert.Check(t, IsNotExist(err), err)
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	s.drivers.Register(volumetestutils.NewFakeDriver("fake"), "fake")
+
+	_, err := s.Create("source", "fake", map[string]string{"size": "10G"}, nil)
+	assert.NilError(t, err)
+
+	v, err := s.Snapshot("source", "snap", nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]string{"size": "10G"}, v.(volume.DetailedVolume).Options()))
+
+	info := s.SnapshotInfo("snap")
+	assert.Assert(t, info != nil)
+	assert.Check(t, is.Equal("source", info.Source))
+	assert.Check(t, !info.CreatedAt.IsZero())
+
+	// snapshot metadata is removed along with the volume
+	assert.NilError(t, s.Remove(v))
+	assert.Check(t, is.Nil(s.SnapshotInfo("snap")))
+}
+
+func TestCloneNotSupported(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	s.drivers.Register(driverWithoutCopy{volumetestutils.NewFakeDriver("fake")}, "fake")
+
+	_, err := s.Create("source", "fake", nil, nil)
+	assert.NilError(t, err)
+
+	_, err = s.Clone("source", "clone", nil, nil)
+	assert.Check(t, errdefs.IsNotImplemented(err), err)
+	_, err = s.Snapshot("source", "snap", nil)
+	assert.Check(t, errdefs.IsNotImplemented(err), err)
+
+	_, err = s.Get("clone")
+	assert.Check(t, IsNotExist(err), err)
+}
+
+// cloneHookDriver calls afterClone once a volume is cloned.
+type cloneHookDriver struct {
+	*volumetestutils.FakeDriver
+	afterClone func()
+}
+
+func (d cloneHookDriver) Clone(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	v, err := d.FakeDriver.Clone(src, name, opts)
+	d.afterClone()
+	return v, err
+}
+
+func TestCloneMetadataError(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	fake := volumetestutils.NewFakeDriver("fake").(*volumetestutils.FakeDriver)
+	s.drivers.Register(cloneHookDriver{FakeDriver: fake, afterClone: func() { s.db.Close() }}, "fake")
+
+	_, err := s.Create("source", "fake", nil, nil)
+	assert.NilError(t, err)
+
+	_, err = s.Clone("source", "clone", nil, nil)
+	assert.Check(t, is.ErrorContains(err, "database not open"))
+
+	// the clone is removed from the driver since its metadata is missing
+	_, err = fake.Get("clone")
+	assert.Check(t, is.ErrorContains(err, "no such volume"))
+	s.globalLock.RLock()
+	_, exists := s.refs["clone"]
+	s.globalLock.RUnlock()
+	assert.Check(t, !exists)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -16,6 +17,8 @@ type volumeMetadata struct {
 	Driver  string
 	Labels  map[string]string
 	Options map[string]string
+	// Snapshot is set for volumes created as a snapshot of another volume.
+	Snapshot *volume.SnapshotInfo `json:",omitempty"`
 }
 
 func (s *VolumeStore) setMeta(name string, meta volumeMetadata) error {
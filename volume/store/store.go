@@ -65,6 +65,24 @@ func (v volumeWrapper) CachedPath() string {
 	return v.Volume.Path()
 }
 
+// MountReadOnly mounts the volume read-only if the volume supports it, and
+// read-write otherwise.
+func (v volumeWrapper) MountReadOnly(id string) (string, error) {
+	if vv, ok := v.Volume.(volume.ReadOnlyMounter); ok {
+		return vv.MountReadOnly(id)
+	}
+	return v.Volume.Mount(id)
+}
+
+// LiveRestoreVolume records the mount id of a live-restored container if
+// the volume keeps track of its mounts.
+func (v volumeWrapper) LiveRestoreVolume(id string) error {
+	if vv, ok := v.Volume.(volume.LiveRestorer); ok {
+		return vv.LiveRestoreVolume(id)
+	}
+	return nil
+}
+
 // New initializes a VolumeStore to keep
 // reference counting of volumes in the system.
 func New(rootPath string, drivers *drivers.Store) (*VolumeStore, error) {
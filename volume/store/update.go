@@ -0,0 +1,72 @@
+package store // import "github.com/docker/docker/volume/store"
+
+import (
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	"github.com/pkg/errors"
+)
+
+// Update replaces the labels of the volume with the given name, unless
+// labels is nil, and changes the given driver options of the volume. Options
+// can only be changed if the driver of the volume supports it; the options
+// which are not given are kept.
+func (s *VolumeStore) Update(name string, labels, opts map[string]string) (volume.Volume, error) {
+	name = normalizeVolumeName(name)
+	s.locks.Lock(name)
+	defer s.locks.Unlock(name)
+
+	v, err := s.update(name, labels, opts)
+	if err != nil {
+		return nil, &OpErr{Err: err, Name: name, Op: "update"}
+	}
+	return v, nil
+}
+
+func (s *VolumeStore) update(name string, labels, opts map[string]string) (volume.Volume, error) {
+	v, err := s.getVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := s.getMeta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	vd, err := s.drivers.GetDriver(v.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	if len(opts) > 0 {
+		updater, ok := vd.(volume.Updater)
+		if !ok {
+			return nil, errdefs.NotImplemented(errors.Errorf("volume driver %s does not support updating volumes", vd.Name()))
+		}
+		if err := updater.Update(unwrapVolume(v), opts); err != nil {
+			return nil, err
+		}
+		options := make(map[string]string, len(meta.Options)+len(opts))
+		for k, v := range meta.Options {
+			options[k] = v
+		}
+		for k, v := range opts {
+			options[k] = v
+		}
+		meta.Options = options
+	}
+	if labels != nil {
+		meta.Labels = labels
+	}
+
+	meta.Name = name
+	meta.Driver = v.DriverName()
+	if err := s.setMeta(name, meta); err != nil {
+		return nil, err
+	}
+
+	s.globalLock.Lock()
+	s.labels[name] = meta.Labels
+	s.options[name] = meta.Options
+	s.globalLock.Unlock()
+
+	return volumeWrapper{unwrapVolume(v), meta.Labels, vd.Scope(), meta.Options}, nil
+}
@@ -0,0 +1,71 @@
+package store // import "github.com/docker/docker/volume/store"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/volume"
+	volumetestutils "github.com/docker/docker/volume/testutils"
+	"github.com/gotestyourself/gotestyourself/assert"
+	is "github.com/gotestyourself/gotestyourself/assert/cmp"
+)
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	s.drivers.Register(volumetestutils.NewFakeDriver("fake"), "fake")
+
+	_, err := s.Create("test", "fake", map[string]string{"size": "10G", "type": "ssd"}, map[string]string{"a": "b"})
+	assert.NilError(t, err)
+
+	// labels are kept when only options are changed
+	v, err := s.Update("test", nil, map[string]string{"size": "20G"})
+	assert.NilError(t, err)
+	dv := v.(volume.DetailedVolume)
+	assert.Check(t, is.DeepEqual(map[string]string{"size": "20G", "type": "ssd"}, dv.Options()))
+	assert.Check(t, is.DeepEqual(map[string]string{"a": "b"}, dv.Labels()))
+
+	_, err = s.Update("test", map[string]string{"c": "d"}, nil)
+	assert.NilError(t, err)
+
+	// the changes are persisted
+	meta, err := s.getMeta("test")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]string{"c": "d"}, meta.Labels))
+	assert.Check(t, is.DeepEqual(map[string]string{"size": "20G", "type": "ssd"}, meta.Options))
+
+	v, err = s.Get("test")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]string{"c": "d"}, v.(volume.DetailedVolume).Labels()))
+
+	// options rejected by the driver are not stored
+	_, err = s.Update("test", map[string]string{"e": "f"}, map[string]string{"error": "immutable option"})
+	assert.Check(t, is.ErrorContains(err, "immutable option"))
+	meta, err = s.getMeta("test")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]string{"c": "d"}, meta.Labels))
+
+	_, err = s.Update("missing", map[string]string{"a": "b"}, nil)
+	assert.Check(t, IsNotExist(err), err)
+}
+
+func TestUpdateNotSupported(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := setupTest(t)
+	defer cleanup()
+	s.drivers.Register(driverWithoutCopy{volumetestutils.NewFakeDriver("fake")}, "fake")
+
+	_, err := s.Create("test", "fake", nil, nil)
+	assert.NilError(t, err)
+
+	_, err = s.Update("test", nil, map[string]string{"size": "20G"})
+	assert.Check(t, errdefs.IsNotImplemented(err), err)
+
+	// labels can be changed with any driver
+	v, err := s.Update("test", map[string]string{"a": "b"}, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string]string{"a": "b"}, v.(volume.DetailedVolume).Labels()))
+}
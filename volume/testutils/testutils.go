@@ -123,6 +123,31 @@ func (d *FakeDriver) Get(name string) (volume.Volume, error) {
 	return nil, fmt.Errorf("no such volume")
 }
 
+// Clone creates a fake volume with the given name.
+func (d *FakeDriver) Clone(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	if _, exists := d.vols[src.Name()]; !exists {
+		return nil, fmt.Errorf("no such volume")
+	}
+	return d.Create(name, opts)
+}
+
+// Snapshot creates a fake volume with the given name.
+func (d *FakeDriver) Snapshot(src volume.Volume, name string, opts map[string]string) (volume.Volume, error) {
+	return d.Clone(src, name, opts)
+}
+
+// Update checks that the volume exists.
+// It returns an error if the options include an "error" key with a message
+func (d *FakeDriver) Update(v volume.Volume, opts map[string]string) error {
+	if _, exists := d.vols[v.Name()]; !exists {
+		return fmt.Errorf("no such volume")
+	}
+	if opts["error"] != "" {
+		return errors.New(opts["error"])
+	}
+	return nil
+}
+
 // Scope returns the local scope
 func (*FakeDriver) Scope() string {
 	return "local"
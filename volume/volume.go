@@ -39,6 +39,49 @@ type Capability struct {
 	// A `local` scope indicates that the driver only manages volumes resources local to the host
 	// Scope is declared by the driver
 	Scope string
+	// Clone indicates that the driver can create a volume holding a copy
+	// of the content of another volume of the driver.
+	Clone bool
+	// Snapshot indicates that the driver can take point-in-time snapshots
+	// of its volumes.
+	Snapshot bool
+	// Update indicates that the driver can change options of existing
+	// volumes.
+	Update bool
+}
+
+// Cloner is implemented by drivers which can create a volume holding a
+// copy of the content of another volume of the driver.
+type Cloner interface {
+	// Clone creates a new volume with the given name and options, holding
+	// a copy of the content of src.
+	Clone(src Volume, name string, opts map[string]string) (Volume, error)
+}
+
+// Snapshotter is implemented by drivers which can take point-in-time
+// snapshots of their volumes. A snapshot is a volume, which is not modified
+// by later changes to the source volume.
+type Snapshotter interface {
+	// Snapshot creates a new volume with the given name and options,
+	// holding the content of src at the time of the call.
+	Snapshot(src Volume, name string, opts map[string]string) (Volume, error)
+}
+
+// Updater is implemented by drivers which can change options of existing
+// volumes.
+type Updater interface {
+	// Update changes the given options of the volume v. Options which
+	// can't be changed are rejected.
+	Update(v Volume, opts map[string]string) error
+}
+
+// SnapshotInfo describes the origin of a volume created as a snapshot of
+// another volume.
+type SnapshotInfo struct {
+	// Source is the name of the volume the snapshot was taken of.
+	Source string
+	// CreatedAt is the time the snapshot was taken.
+	CreatedAt time.Time
 }
 
 // Volume is a place to store data. It is backed by a specific driver, and can be mounted.
@@ -60,6 +103,23 @@ type Volume interface {
 	Status() map[string]interface{}
 }
 
+// ReadOnlyMounter is implemented by volumes which can be mounted read-only
+// by their driver, for mount points which are not writable.
+type ReadOnlyMounter interface {
+	// MountReadOnly mounts the volume like Mount, for a mount which
+	// doesn't write to the volume.
+	MountReadOnly(id string) (string, error)
+}
+
+// LiveRestorer is implemented by volumes which keep track of their mounts
+// by ID, and need to know the mounts of the containers restored with
+// live-restore after a restart of the daemon.
+type LiveRestorer interface {
+	// LiveRestoreVolume records the mount id of a container which is still
+	// running, as if the volume had been mounted.
+	LiveRestoreVolume(id string) error
+}
+
 // DetailedVolume wraps a Volume with user-defined labels, options, and cluster scope (e.g., `local` or `global`)
 type DetailedVolume interface {
 	Labels() map[string]string